@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+var logger *logrus.Entry
+
+// SetLogger sets the logger instance for the auth package
+func SetLogger(l *logrus.Logger) {
+	logger = l.WithField("component", "auth")
+}
+
+// Tokens is the token manager used to issue and verify access tokens
+var Tokens *TokenManager
+
+// Registration holds the self-service registration settings
+var Registration RegistrationConfig
+
+var (
+	// ErrInvalidToken is returned when a token cannot be parsed or verified
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrUnknownKeyID is returned when a token references a key that is not configured
+	ErrUnknownKeyID = errors.New("unknown signing key ID")
+)
+
+// AuthConfig holds the token signing configuration
+type AuthConfig struct {
+	// SigningKeys maps a key ID to its HMAC secret. Every key listed here is
+	// accepted for verification, which allows keys to be rotated without
+	// invalidating tokens that are still in flight.
+	SigningKeys map[string][]byte
+	// ActiveKeyID selects the key used to sign new tokens
+	ActiveKeyID    string
+	Issuer         string
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is the lifetime of a refresh token. Each rotation
+	// issues a new token with a fresh lifetime.
+	RefreshTokenTTL time.Duration
+}
+
+// GetAuthConfig loads the auth configuration from environment variables.
+//
+// AUTH_SIGNING_KEYS is a comma separated list of kid:secret pairs, for
+// example "2025-05:first-secret,2025-06:second-secret". AUTH_ACTIVE_KEY_ID
+// selects the key used for signing and defaults to the last key in the list.
+func GetAuthConfig() (AuthConfig, error) {
+	config := AuthConfig{
+		SigningKeys: make(map[string][]byte),
+		ActiveKeyID: os.Getenv("AUTH_ACTIVE_KEY_ID"),
+		Issuer:      getEnv("AUTH_ISSUER", "student-api"),
+	}
+
+	ttl, err := time.ParseDuration(getEnv("AUTH_ACCESS_TOKEN_TTL", "15m"))
+	if err != nil {
+		return config, fmt.Errorf("invalid AUTH_ACCESS_TOKEN_TTL: %w", err)
+	}
+	config.AccessTokenTTL = ttl
+
+	refreshTTL, err := time.ParseDuration(getEnv("AUTH_REFRESH_TOKEN_TTL", "720h"))
+	if err != nil {
+		return config, fmt.Errorf("invalid AUTH_REFRESH_TOKEN_TTL: %w", err)
+	}
+	config.RefreshTokenTTL = refreshTTL
+
+	var lastKeyID string
+	for _, pair := range strings.Split(os.Getenv("AUTH_SIGNING_KEYS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kid, secret, found := strings.Cut(pair, ":")
+		if !found || kid == "" || secret == "" {
+			return config, errors.New("AUTH_SIGNING_KEYS entries must have the form kid:secret")
+		}
+		config.SigningKeys[kid] = []byte(secret)
+		lastKeyID = kid
+	}
+
+	if config.ActiveKeyID == "" {
+		config.ActiveKeyID = lastKeyID
+	}
+
+	return config, nil
+}
+
+// RegistrationConfig controls self-service registration and invitations
+type RegistrationConfig struct {
+	// Open enables POST /auth/register. When disabled, accounts can only be
+	// created by redeeming an invitation.
+	Open bool
+	// DefaultRole is the role assigned to self-registered users
+	DefaultRole models.UserRole
+	// InvitationTTL is the default lifetime of an invitation code
+	InvitationTTL time.Duration
+}
+
+// GetRegistrationConfig loads the registration settings from environment variables
+func GetRegistrationConfig() (RegistrationConfig, error) {
+	config := RegistrationConfig{
+		Open:        getEnv("AUTH_OPEN_REGISTRATION", "false") == "true",
+		DefaultRole: models.UserRole(getEnv("AUTH_REGISTRATION_ROLE", string(models.RoleParent))),
+	}
+
+	if !config.DefaultRole.IsValid() {
+		return config, fmt.Errorf("invalid AUTH_REGISTRATION_ROLE: %s", config.DefaultRole)
+	}
+	// Staff accounts can manage every other account, so they must always be invited
+	if config.DefaultRole == models.RoleStaff {
+		return config, errors.New("AUTH_REGISTRATION_ROLE cannot be staff")
+	}
+
+	ttl, err := time.ParseDuration(getEnv("AUTH_INVITATION_TTL", "168h"))
+	if err != nil {
+		return config, fmt.Errorf("invalid AUTH_INVITATION_TTL: %w", err)
+	}
+	config.InvitationTTL = ttl
+
+	return config, nil
+}
+
+// getEnv gets an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// InitAuth loads the auth configuration and initializes the token manager
+func InitAuth() error {
+	// Initialize logger if not set
+	if logger == nil {
+		logger = logrus.StandardLogger().WithField("component", "auth")
+	}
+
+	config, err := GetAuthConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(config.SigningKeys) == 0 {
+		// Without a configured key every token becomes invalid on restart and
+		// replicas cannot verify each other's tokens, so only use this locally.
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		config.SigningKeys["ephemeral"] = secret
+		config.ActiveKeyID = "ephemeral"
+		logger.Warn("AUTH_SIGNING_KEYS is not set, using an ephemeral signing key")
+	}
+
+	Tokens, err = NewTokenManager(config)
+	if err != nil {
+		return err
+	}
+
+	Registration, err = GetRegistrationConfig()
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"active_key_id": config.ActiveKeyID,
+		"key_count":     len(config.SigningKeys),
+		"access_ttl":    config.AccessTokenTTL.String(),
+		"refresh_ttl":   config.RefreshTokenTTL.String(),
+	}).Info("Initialized token manager")
+	logger.WithFields(logrus.Fields{
+		"open_registration": Registration.Open,
+		"default_role":      Registration.DefaultRole,
+	}).Info("Loaded registration settings")
+	return nil
+}
+
+// Claims are the claims carried by an access token
+type Claims struct {
+	Username string          `json:"username"`
+	Role     models.UserRole `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager issues and verifies HMAC-signed access tokens
+type TokenManager struct {
+	config AuthConfig
+	parser *jwt.Parser
+}
+
+// NewTokenManager creates a token manager for the given configuration
+func NewTokenManager(config AuthConfig) (*TokenManager, error) {
+	if _, ok := config.SigningKeys[config.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured", config.ActiveKeyID)
+	}
+	if config.AccessTokenTTL <= 0 {
+		return nil, errors.New("access token TTL must be positive")
+	}
+	if config.RefreshTokenTTL <= 0 {
+		return nil, errors.New("refresh token TTL must be positive")
+	}
+
+	return &TokenManager{
+		config: config,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithIssuer(config.Issuer),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+	}, nil
+}
+
+// IssueAccessToken creates a signed access token for the user and returns it
+// together with its expiry time
+func (m *TokenManager) IssueAccessToken(user *models.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.config.AccessTokenTTL)
+
+	claims := Claims{
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.config.Issuer,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = m.config.ActiveKeyID
+
+	signed, err := token.SignedString(m.config.SigningKeys[m.config.ActiveKeyID])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken verifies the token signature and expiry and returns its claims
+func (m *TokenManager) ParseAccessToken(tokenString string) (*Claims, error) {
+	var claims Claims
+	_, err := m.parser.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := m.config.SigningKeys[kid]
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" || claims.Role == "" {
+		return nil, fmt.Errorf("%w: missing subject or role", ErrInvalidToken)
+	}
+
+	return &claims, nil
+}
+
+// AccessTokenTTL returns the lifetime of newly issued access tokens
+func (m *TokenManager) AccessTokenTTL() time.Duration {
+	return m.config.AccessTokenTTL
+}
+
+// RefreshTokenTTL returns the lifetime of newly issued refresh tokens
+func (m *TokenManager) RefreshTokenTTL() time.Duration {
+	return m.config.RefreshTokenTTL
+}
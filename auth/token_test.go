@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+func newTestManager(t *testing.T, activeKeyID string, ttl time.Duration) *TokenManager {
+	t.Helper()
+	manager, err := NewTokenManager(AuthConfig{
+		SigningKeys: map[string][]byte{
+			"old": []byte("old-secret"),
+			"new": []byte("new-secret"),
+		},
+		ActiveKeyID:     activeKeyID,
+		Issuer:          "student-api",
+		AccessTokenTTL:  ttl,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	return manager
+}
+
+// Test issuing and parsing a token round trip
+func TestTokenManager_IssueAndParse(t *testing.T) {
+	manager := newTestManager(t, "new", time.Minute)
+	user := &models.User{ID: "user-1", Username: "jdoe", Role: models.RoleFaculty}
+
+	token, expiresAt, err := manager.IssueAccessToken(user)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 2*time.Second)
+
+	claims, err := manager.ParseAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "jdoe", claims.Username)
+	assert.Equal(t, models.RoleFaculty, claims.Role)
+}
+
+// Test that tokens signed with a previous key remain valid after rotation
+func TestTokenManager_KeyRotation(t *testing.T) {
+	user := &models.User{ID: "user-1", Role: models.RoleStaff}
+
+	token, _, err := newTestManager(t, "old", time.Minute).IssueAccessToken(user)
+	require.NoError(t, err)
+
+	claims, err := newTestManager(t, "new", time.Minute).ParseAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+
+	// Once the old key is removed the token is rejected
+	retired, err := NewTokenManager(AuthConfig{
+		SigningKeys:     map[string][]byte{"new": []byte("new-secret")},
+		ActiveKeyID:     "new",
+		Issuer:          "student-api",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	_, err = retired.ParseAccessToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// Test rejection of expired, tampered and unsigned tokens
+func TestTokenManager_RejectsInvalidTokens(t *testing.T) {
+	manager := newTestManager(t, "new", time.Minute)
+	user := &models.User{ID: "user-1", Role: models.RoleParent}
+
+	// Expired
+	expired := newTestManager(t, "new", time.Nanosecond)
+	token, _, err := expired.IssueAccessToken(user)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = manager.ParseAccessToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Tampered payload
+	token, _, err = manager.IssueAccessToken(user)
+	require.NoError(t, err)
+	_, err = manager.ParseAccessToken(token[:len(token)-2] + "xx")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Unsigned token
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+		Role: models.RoleStaff,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    "student-api",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	unsigned.Header["kid"] = "new"
+	raw, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+	_, err = manager.ParseAccessToken(raw)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// Test loading signing keys from the environment
+func TestGetAuthConfig(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEYS", "k1:secret-one, k2:secret-two")
+	t.Setenv("AUTH_ACTIVE_KEY_ID", "")
+	t.Setenv("AUTH_ACCESS_TOKEN_TTL", "5m")
+
+	config, err := GetAuthConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "k2", config.ActiveKeyID)
+	assert.Equal(t, []byte("secret-one"), config.SigningKeys["k1"])
+	assert.Equal(t, 5*time.Minute, config.AccessTokenTTL)
+
+	t.Setenv("AUTH_SIGNING_KEYS", "missing-secret")
+	_, err = GetAuthConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package authz
+
+import (
+	"context"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// Authorizer answers permission checks against the grants in a store and
+// resolves which records a user may see
+type Authorizer struct {
+	policy *Policy
+	store  *models.Store
+}
+
+// NewAuthorizer creates an authorizer backed by the given store. Grants are
+// cached for AUTHZ_POLICY_TTL, defaulting to 30 seconds.
+func NewAuthorizer(store *models.Store) *Authorizer {
+	return &Authorizer{
+		policy: NewPolicy(store.Permissions.GetRolePermissions, policyTTL()),
+		store:  store,
+	}
+}
+
+// Allows reports whether the role holds the permission
+func (a *Authorizer) Allows(ctx context.Context, role models.UserRole, permission string) (bool, error) {
+	return a.policy.Allows(ctx, role, permission)
+}
+
+// Invalidate drops the cached grants so the next check reloads them
+func (a *Authorizer) Invalidate() {
+	a.policy.Invalidate()
+}
@@ -0,0 +1,138 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// Permission names checked by the API. The permissions themselves and the
+// default grants are seeded by the create_permissions_tables migration.
+const (
+	PermStudentsRead       = "students:read"
+	PermStudentsWrite      = "students:write"
+	PermStudentsDelete     = "students:delete"
+	PermStudentsRestore    = "students:restore"
+	PermStudentsPurge      = "students:purge"
+	PermUsersRead          = "users:read"
+	PermUsersWrite         = "users:write"
+	PermUsersDelete        = "users:delete"
+	PermUsersRoles         = "users:roles"
+	PermUsersSessions      = "users:sessions"
+	PermInvitationsManage  = "invitations:manage"
+	PermClassesRead        = "classes:read"
+	PermClassesManage      = "classes:manage"
+	PermAttendanceRead     = "attendance:read"
+	PermAttendanceWrite    = "attendance:write"
+	PermAssignmentsRead    = "assignments:read"
+	PermAssignmentsWrite   = "assignments:write"
+	PermGradesRead         = "grades:read"
+	PermGradesWrite        = "grades:write"
+	PermForumRead          = "forum:read"
+	PermForumWrite         = "forum:write"
+	PermForumModerate      = "forum:moderate"
+	PermReportsRead        = "reports:read"
+	PermReportsChildren    = "reports:children"
+	PermPermissionsManage  = "permissions:manage"
+	PermAuditRead          = "audit:read"
+	PermDataImport         = "data:import"
+	PermGradebookManage    = "gradebook:manage"
+	PermGradebookConfigure = "gradebook:configure"
+	PermTermsRead          = "terms:read"
+	PermTermsManage        = "terms:manage"
+	PermTermsFinalize      = "terms:finalize"
+	PermReportCardsRead    = "report-cards:read"
+	PermPromotionsManage   = "promotions:manage"
+	PermSubmissionsRead    = "submissions:read"
+	PermSubmissionsWrite   = "submissions:write"
+	PermJobsManage         = "jobs:manage"
+	PermNotificationsRead  = "notifications:read"
+)
+
+// Policy answers whether a role holds a permission. Grants are stored in the
+// database and cached for a short time, so edits made on another replica
+// take effect within one TTL.
+type Policy struct {
+	load func(context.Context) (map[models.UserRole][]string, error)
+	ttl  time.Duration
+
+	mu       sync.RWMutex
+	grants   map[models.UserRole]map[string]struct{}
+	loadedAt time.Time
+}
+
+// NewPolicy creates a policy that loads its grants with the given function
+func NewPolicy(load func(context.Context) (map[models.UserRole][]string, error), ttl time.Duration) *Policy {
+	return &Policy{load: load, ttl: ttl}
+}
+
+// policyTTL reads AUTHZ_POLICY_TTL, defaulting to 30 seconds
+func policyTTL() time.Duration {
+	if value := os.Getenv("AUTHZ_POLICY_TTL"); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil {
+			return ttl
+		}
+	}
+	return 30 * time.Second
+}
+
+// Allows reports whether the role holds the permission
+func (p *Policy) Allows(ctx context.Context, role models.UserRole, permission string) (bool, error) {
+	grants, err := p.current(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := grants[role][permission]
+	return ok, nil
+}
+
+// Invalidate drops the cached grants so the next check reloads them
+func (p *Policy) Invalidate() {
+	p.mu.Lock()
+	p.grants = nil
+	p.mu.Unlock()
+}
+
+// current returns the cached grants, reloading them once they are stale
+func (p *Policy) current(ctx context.Context) (map[models.UserRole]map[string]struct{}, error) {
+	p.mu.RLock()
+	grants, loadedAt := p.grants, p.loadedAt
+	p.mu.RUnlock()
+
+	if grants != nil && time.Since(loadedAt) < p.ttl {
+		return grants, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Another request may have reloaded while we waited for the lock
+	if p.grants != nil && time.Since(p.loadedAt) < p.ttl {
+		return p.grants, nil
+	}
+
+	loaded, err := p.load(ctx)
+	if err != nil {
+		// Keep serving the last known grants through a database hiccup
+		if p.grants != nil {
+			return p.grants, nil
+		}
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+
+	p.grants = make(map[models.UserRole]map[string]struct{}, len(loaded))
+	for role, permissions := range loaded {
+		set := make(map[string]struct{}, len(permissions))
+		for _, permission := range permissions {
+			set[permission] = struct{}{}
+		}
+		p.grants[role] = set
+	}
+	p.loadedAt = time.Now()
+
+	return p.grants, nil
+}
@@ -0,0 +1,71 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that grants are cached until invalidated
+func TestPolicyCachesGrants(t *testing.T) {
+	loads := 0
+	grants := map[models.UserRole][]string{
+		models.RoleFaculty: {PermGradesWrite},
+	}
+	policy := NewPolicy(func(context.Context) (map[models.UserRole][]string, error) {
+		loads++
+		return grants, nil
+	}, time.Minute)
+
+	allowed, err := policy.Allows(context.Background(), models.RoleFaculty, PermGradesWrite)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = policy.Allows(context.Background(), models.RoleParent, PermGradesWrite)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 1, loads)
+
+	grants = map[models.UserRole][]string{}
+	policy.Invalidate()
+
+	allowed, err = policy.Allows(context.Background(), models.RoleFaculty, PermGradesWrite)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 2, loads)
+}
+
+// Test that a failed reload keeps the last known grants
+func TestPolicyServesStaleGrantsOnError(t *testing.T) {
+	fail := false
+	policy := NewPolicy(func(context.Context) (map[models.UserRole][]string, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		return map[models.UserRole][]string{models.RoleStaff: {PermPermissionsManage}}, nil
+	}, 0)
+
+	allowed, err := policy.Allows(context.Background(), models.RoleStaff, PermPermissionsManage)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	fail = true
+	allowed, err = policy.Allows(context.Background(), models.RoleStaff, PermPermissionsManage)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// Test that the first load failing denies access with an error
+func TestPolicyLoadError(t *testing.T) {
+	policy := NewPolicy(func(context.Context) (map[models.UserRole][]string, error) {
+		return nil, errors.New("connection refused")
+	}, time.Minute)
+
+	allowed, err := policy.Allows(context.Background(), models.RoleStaff, PermStudentsRead)
+	assert.Error(t, err)
+	assert.False(t, allowed)
+}
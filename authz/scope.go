@@ -0,0 +1,124 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// Scope describes which students' records a user may read and which classes
+// they may teach. Staff are unrestricted; faculty are limited to the students
+// enrolled in the classes they teach; parents are limited to the students
+// linked to them through parent_student and teach no classes.
+type Scope struct {
+	unrestricted bool
+	studentIDs   map[string]struct{}
+	classIDs     map[string]struct{}
+}
+
+// ScopeFor resolves the scope of the given user
+func (a *Authorizer) ScopeFor(ctx context.Context, user *models.User) (*Scope, error) {
+	if user == nil {
+		return &Scope{studentIDs: map[string]struct{}{}}, nil
+	}
+
+	switch user.Role {
+	case models.RoleParent:
+		ids := user.StudentIDs
+		if ids == nil {
+			var err error
+			ids, err = a.store.Users.GetStudentIDsByParentID(ctx, user.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load linked students: %w", err)
+			}
+		}
+		return NewRestrictedScope(ids), nil
+
+	case models.RoleFaculty:
+		classIDs, err := a.store.Classes.GetIDsByTeacherID(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load taught classes: %w", err)
+		}
+		studentIDs, err := a.store.Classes.GetStudentIDsByTeacherID(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load class rosters: %w", err)
+		}
+		return NewClassScope(classIDs, studentIDs), nil
+
+	default:
+		return &Scope{unrestricted: true}, nil
+	}
+}
+
+// NewRestrictedScope returns a scope limited to the given students
+func NewRestrictedScope(studentIDs []string) *Scope {
+	scope := &Scope{studentIDs: make(map[string]struct{}, len(studentIDs))}
+	for _, id := range studentIDs {
+		scope.studentIDs[id] = struct{}{}
+	}
+	return scope
+}
+
+// NewClassScope returns a scope limited to the given classes and the students
+// enrolled in them
+func NewClassScope(classIDs, studentIDs []string) *Scope {
+	scope := NewRestrictedScope(studentIDs)
+	scope.classIDs = make(map[string]struct{}, len(classIDs))
+	for _, id := range classIDs {
+		scope.classIDs[id] = struct{}{}
+	}
+	return scope
+}
+
+// Unrestricted reports whether the scope covers every student
+func (s *Scope) Unrestricted() bool {
+	return s.unrestricted
+}
+
+// CanAccessStudent reports whether records of the given student are visible
+func (s *Scope) CanAccessStudent(studentID string) bool {
+	if s.unrestricted {
+		return true
+	}
+	_, ok := s.studentIDs[studentID]
+	return ok
+}
+
+// CanAccessClass reports whether the user may manage the class's
+// assignments, grades and attendance
+func (s *Scope) CanAccessClass(classID string) bool {
+	if s.unrestricted {
+		return true
+	}
+	_, ok := s.classIDs[classID]
+	return ok
+}
+
+// StudentIDs returns the students visible in a restricted scope. It returns
+// nil for an unrestricted scope.
+func (s *Scope) StudentIDs() []string {
+	if s.unrestricted {
+		return nil
+	}
+	ids := make([]string, 0, len(s.studentIDs))
+	for id := range s.studentIDs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Filter returns the items whose student is visible in the scope. studentID
+// extracts the student a record belongs to.
+func Filter[T any](s *Scope, items []T, studentID func(T) string) []T {
+	if s.unrestricted {
+		return items
+	}
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if s.CanAccessStudent(studentID(item)) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,75 @@
+package authz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/models/memory"
+)
+
+// Test that staff see every student and class
+func TestScopeFor_Unrestricted(t *testing.T) {
+	scope, err := NewAuthorizer(memory.NewStore()).ScopeFor(context.Background(), &models.User{ID: "u1", Role: models.RoleStaff})
+	assert.NoError(t, err)
+	assert.True(t, scope.Unrestricted())
+	assert.True(t, scope.CanAccessStudent("any"))
+	assert.True(t, scope.CanAccessClass("any"))
+	assert.Nil(t, scope.StudentIDs())
+}
+
+// Test that faculty only see the classes they teach and their rosters
+func TestClassScope(t *testing.T) {
+	scope := NewClassScope([]string{"c1"}, []string{"s1", "s2"})
+	assert.False(t, scope.Unrestricted())
+	assert.True(t, scope.CanAccessClass("c1"))
+	assert.False(t, scope.CanAccessClass("c2"))
+	assert.True(t, scope.CanAccessStudent("s2"))
+	assert.False(t, scope.CanAccessStudent("s3"))
+}
+
+// Test that faculty scopes are resolved from the classes they teach
+func TestScopeFor_Faculty(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+
+	teacher := &models.User{Username: "teacher", Email: "teacher@example.com", Password: "secret", FirstName: "T", LastName: "One", Role: models.RoleFaculty}
+	assert.NoError(t, store.Users.Create(ctx, teacher))
+	student := &models.Student{Name: "Ann", Email: "ann@example.com", DateOfBirth: time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), Grade: "5"}
+	assert.NoError(t, store.Students.Create(ctx, student))
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "2026", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{student.ID}}
+	assert.NoError(t, store.Classes.Create(ctx, class))
+
+	scope, err := NewAuthorizer(store).ScopeFor(ctx, &models.User{ID: teacher.ID, Role: models.RoleFaculty})
+	assert.NoError(t, err)
+	assert.True(t, scope.CanAccessClass(class.ID))
+	assert.True(t, scope.CanAccessStudent(student.ID))
+	assert.False(t, scope.CanAccessStudent("other"))
+}
+
+// Test that parents only see their linked students
+func TestScopeFor_Parent(t *testing.T) {
+	scope, err := NewAuthorizer(memory.NewStore()).ScopeFor(context.Background(), &models.User{ID: "p1", Role: models.RoleParent, StudentIDs: []string{"s1", "s2"}})
+	assert.NoError(t, err)
+	assert.False(t, scope.Unrestricted())
+	assert.True(t, scope.CanAccessStudent("s1"))
+	assert.False(t, scope.CanAccessStudent("s3"))
+	assert.False(t, scope.CanAccessClass("c1"))
+	assert.ElementsMatch(t, []string{"s1", "s2"}, scope.StudentIDs())
+
+	grades := []models.Grade{{ID: "g1", StudentID: "s1"}, {ID: "g2", StudentID: "s3"}, {ID: "g3", StudentID: "s2"}}
+	filtered := Filter(scope, grades, func(g models.Grade) string { return g.StudentID })
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, "g1", filtered[0].ID)
+	assert.Equal(t, "g3", filtered[1].ID)
+}
+
+// Test that a parent without links sees nothing
+func TestScopeFor_ParentWithoutStudents(t *testing.T) {
+	scope := NewRestrictedScope(nil)
+	assert.False(t, scope.CanAccessStudent("s1"))
+	assert.Empty(t, scope.StudentIDs())
+}
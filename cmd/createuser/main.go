@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/db"
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/models/postgres"
+	"github.com/sirupsen/logrus"
+)
+
+// createuser creates an account directly in the database. It exists to
+// bootstrap the first staff member, who can then invite everyone else.
+var (
+	username  = flag.String("username", "", "Username for the new account")
+	email     = flag.String("email", "", "Email address for the new account")
+	password  = flag.String("password", "", "Password for the new account")
+	firstName = flag.String("first-name", "", "First name")
+	lastName  = flag.String("last-name", "", "Last name")
+	role      = flag.String("role", string(models.RoleStaff), "Role (faculty, staff, parent)")
+)
+
+func main() {
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: time.RFC3339,
+	})
+	db.SetLogger(logger)
+
+	user := models.User{
+		Username:  *username,
+		Email:     *email,
+		Password:  *password,
+		FirstName: *firstName,
+		LastName:  *lastName,
+		Role:      models.UserRole(*role),
+	}
+	if user.Username == "" || user.Email == "" || user.Password == "" || user.FirstName == "" || user.LastName == "" {
+		logger.Fatal("-username, -email, -password, -first-name and -last-name are required")
+	}
+	if !user.Role.IsValid() {
+		logger.Fatalf("Invalid role: %s", *role)
+	}
+
+	if err := db.InitDB(); err != nil {
+		logger.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.CloseDB()
+
+	if err := postgres.NewStore(db.DB).Users.Create(context.Background(), &user); err != nil {
+		logger.Fatalf("Failed to create user: %v", err)
+	}
+
+	logger.Infof("Created %s user %s with ID %s", user.Role, user.Username, user.ID)
+}
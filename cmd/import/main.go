@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/db"
+	"example.com/sre-bootcamp-rest-api/importer"
+	"example.com/sre-bootcamp-rest-api/models/postgres"
+	"github.com/sirupsen/logrus"
+)
+
+// import loads students, users and parent links from CSV files, in the
+// format described in the importer package, in a single transaction
+var (
+	studentsFile    = flag.String("students", "", "CSV file of students")
+	usersFile       = flag.String("users", "", "CSV file of users")
+	parentLinksFile = flag.String("parent-links", "", "CSV file of parent-student links")
+	dryRun          = flag.Bool("dry-run", false, "Check every row without writing anything")
+)
+
+func main() {
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: time.RFC3339,
+	})
+	db.SetLogger(logger)
+
+	if *studentsFile == "" && *usersFile == "" && *parentLinksFile == "" {
+		logger.Fatal("At least one of -students, -users and -parent-links is required")
+	}
+
+	var files importer.Files
+	for path, target := range map[string]*io.Reader{
+		*studentsFile:    &files.Students,
+		*usersFile:       &files.Users,
+		*parentLinksFile: &files.ParentLinks,
+	} {
+		if path == "" {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			logger.Fatalf("Failed to open %s: %v", path, err)
+		}
+		defer file.Close()
+		*target = file
+	}
+
+	if err := db.InitDB(); err != nil {
+		logger.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.CloseDB()
+
+	report, err := importer.Run(context.Background(), postgres.NewStore(db.DB).Import, files, *dryRun)
+	if err != nil {
+		logger.Fatalf("Failed to import: %v", err)
+	}
+
+	for _, rowErr := range report.Errors {
+		logger.Error(rowErr.Error())
+	}
+	switch {
+	case len(report.Errors) > 0:
+		logger.Errorf("Found %d errors; nothing was imported", len(report.Errors))
+		db.CloseDB()
+		os.Exit(1)
+	case *dryRun:
+		logger.Infof("Dry run passed for %d students, %d users and %d parent links; nothing was written", report.Students, report.Users, report.ParentLinks)
+	default:
+		logger.Infof("Imported %d students, %d users and %d parent links", report.Students, report.Users, report.ParentLinks)
+	}
+}
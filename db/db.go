@@ -6,8 +6,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 var logger *logrus.Entry
@@ -33,6 +35,9 @@ type DBConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// QueryTimeout bounds the database work of a single request. Zero
+	// disables the deadline.
+	QueryTimeout time.Duration
 }
 
 // GetDBConfig loads database configuration from environment variables with fallback values
@@ -44,7 +49,27 @@ func GetDBConfig() DBConfig {
 		Password: getEnv("DB_PASSWORD", "postgres"),
 		DBName:   getEnv("DB_NAME", "student_api"),
 		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		QueryTimeout: getDurationEnv("DB_QUERY_TIMEOUT", 10*time.Second),
+	}
+}
+
+// getDurationEnv gets a duration environment variable or returns a default
+// value if it is unset or invalid
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		if logger == nil {
+			logger = logrus.StandardLogger().WithField("component", "database")
+		}
+		logger.Warnf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
 	}
+	return duration
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -81,8 +106,13 @@ func InitDB() error {
 	logger = logger.WithField("db_url", maskSensitiveData(dbURL))
 	logger.Info("Initializing database connection")
 
+	// Wrap the driver so every statement run with a request context becomes
+	// a child span of that request
 	var err error
-	DB, err = sql.Open("postgres", dbURL)
+	DB, err = otelsql.Open("postgres", dbURL,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -0,0 +1,228 @@
+// Package export renders report data as CSV files and printable PDF
+// documents. Handlers describe a report once as a Document and pick the
+// writer matching the format the client asked for.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Document is a report made of labelled summary fields followed by tables
+type Document struct {
+	Title       string
+	Subtitle    string
+	Fields      []Field
+	Tables      []Table
+	GeneratedAt time.Time
+}
+
+// Field is a labelled value shown above the tables of a document
+type Field struct {
+	Label string
+	Value string
+}
+
+// Table is a titled grid of values. Widths optionally weights the columns
+// in the PDF; by default every column gets the same share of the page.
+type Table struct {
+	Title   string
+	Columns []string
+	Widths  []float64
+	Rows    [][]string
+}
+
+// WriteCSV writes the document as CSV. A document with a single table and
+// no fields is written as that table alone, so it opens directly in a
+// spreadsheet. Otherwise every part starts with its title and parts are
+// separated by an empty row. Cells a spreadsheet would read as a formula
+// are prefixed with a quote.
+func WriteCSV(w io.Writer, doc Document) error {
+	writer := csv.NewWriter(w)
+
+	if len(doc.Fields) == 0 && len(doc.Tables) == 1 {
+		writeTable(writer, doc.Tables[0])
+		writer.Flush()
+		return writer.Error()
+	}
+
+	writeRow(writer, []string{doc.Title})
+	for _, field := range doc.Fields {
+		writeRow(writer, []string{field.Label, field.Value})
+	}
+	for _, table := range doc.Tables {
+		writeRow(writer, nil)
+		writeRow(writer, []string{table.Title})
+		writeTable(writer, table)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeTable(writer *csv.Writer, table Table) {
+	writeRow(writer, table.Columns)
+	for _, row := range table.Rows {
+		writeRow(writer, row)
+	}
+}
+
+// writeRow writes a record with every cell escaped. Errors are reported by
+// the writer once flushed.
+func writeRow(writer *csv.Writer, row []string) {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		escaped[i] = escapeCell(cell)
+	}
+	_ = writer.Write(escaped)
+}
+
+// escapeCell prefixes a cell starting with a character that makes
+// spreadsheets evaluate it as a formula with a quote, so user input such as
+// a student name or feedback cannot run formulas when the file is opened
+func escapeCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// Page layout of PDF documents, in millimetres
+const (
+	pageMargin   = 15.0
+	footerHeight = 15.0
+	rowHeight    = 6.0
+)
+
+// WritePDF renders the document as A4 pages with numbered footers. Tables
+// that continue on a new page repeat their header row.
+func WritePDF(w io.Writer, doc Document) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, footerHeight+pageMargin/2)
+	pdf.SetTitle(doc.Title, true)
+	pdf.SetCreator("Student API", true)
+	if !doc.GeneratedAt.IsZero() {
+		pdf.SetCreationDate(doc.GeneratedAt)
+	}
+	pdf.AliasNbPages("")
+
+	// The core fonts are built in, so rendering needs no font files; text
+	// is converted to their Windows-1252 encoding
+	text := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-footerHeight)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(110, 110, 110)
+		footer := fmt.Sprintf("%s - page %d of {nb}", doc.Title, pdf.PageNo())
+		pdf.CellFormat(0, 10, text(footer), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, text(doc.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(90, 90, 90)
+	if doc.Subtitle != "" {
+		pdf.CellFormat(0, rowHeight, text(doc.Subtitle), "", 1, "L", false, 0, "")
+	}
+	if !doc.GeneratedAt.IsZero() {
+		pdf.CellFormat(0, rowHeight, "Generated "+doc.GeneratedAt.Format("2006-01-02 15:04 MST"), "", 1, "L", false, 0, "")
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	for _, field := range doc.Fields {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(45, rowHeight, text(field.Label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, rowHeight, text(field.Value), "", "L", false)
+	}
+
+	for _, table := range doc.Tables {
+		pdf.Ln(4)
+		writePDFTable(pdf, table, text)
+	}
+
+	return pdf.Output(w)
+}
+
+// writePDFTable draws a table, starting a new page with the header row
+// whenever the next row would run into the footer
+func writePDFTable(pdf *fpdf.Fpdf, table Table, text func(string) string) {
+	pageWidth, pageHeight := pdf.GetPageSize()
+	widths := columnWidths(table, pageWidth-2*pageMargin)
+	bottom := pageHeight - footerHeight - pageMargin/2
+
+	// Keep the title together with the header and first row
+	if pdf.GetY()+3*rowHeight+2 > bottom {
+		pdf.AddPage()
+	}
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, text(table.Title), "", 1, "L", false, 0, "")
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(225, 225, 225)
+		for i, column := range table.Columns {
+			pdf.CellFormat(widths[i], rowHeight, fit(pdf, text(column), widths[i]), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	header()
+
+	if len(table.Rows) == 0 {
+		pdf.CellFormat(0, rowHeight, "No records", "1", 1, "L", false, 0, "")
+		return
+	}
+	for _, row := range table.Rows {
+		if pdf.GetY()+rowHeight > bottom {
+			pdf.AddPage()
+			header()
+		}
+		for i := range table.Columns {
+			var value string
+			if i < len(row) {
+				value = row[i]
+			}
+			pdf.CellFormat(widths[i], rowHeight, fit(pdf, text(value), widths[i]), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// columnWidths shares the available width between the columns of a table
+func columnWidths(table Table, available float64) []float64 {
+	widths := make([]float64, len(table.Columns))
+	var total float64
+	for i := range widths {
+		widths[i] = 1
+		if i < len(table.Widths) && table.Widths[i] > 0 {
+			widths[i] = table.Widths[i]
+		}
+		total += widths[i]
+	}
+	for i := range widths {
+		widths[i] = widths[i] / total * available
+	}
+	return widths
+}
+
+// fit shortens text that is wider than a cell, ending it with an ellipsis
+func fit(pdf *fpdf.Fpdf, value string, width float64) string {
+	const padding = 2
+	if pdf.GetStringWidth(value) <= width-padding {
+		return value
+	}
+	runes := []rune(value)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width-padding {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var grades = Table{
+	Title:   "Grades",
+	Columns: []string{"Assignment", "Score"},
+	Rows:    [][]string{{"Essay, draft", "9"}, {"Quiz", "7.5"}},
+}
+
+// Test that a lone table is written without a title and that documents
+// with several parts are separated into titled sections
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, Document{Title: "Grades Report", Tables: []Table{grades}}))
+	assert.Equal(t, "Assignment,Score\n\"Essay, draft\",9\nQuiz,7.5\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteCSV(&buf, Document{
+		Title:  "Report Card",
+		Fields: []Field{{Label: "Student", Value: "Ann"}},
+		Tables: []Table{grades, {Title: "Forum Posts", Columns: []string{"Date", "Title"}}},
+	}))
+	assert.Equal(t, "Report Card\nStudent,Ann\n\nGrades\nAssignment,Score\n\"Essay, draft\",9\nQuiz,7.5\n\nForum Posts\nDate,Title\n", buf.String())
+}
+
+// Test that cells a spreadsheet would evaluate are written as text
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, Document{Tables: []Table{{
+		Columns: []string{"Student", "Feedback"},
+		Rows: [][]string{
+			{"=HYPERLINK(\"http://example.com\")", "+1"},
+			{"-2+3", "@SUM(A1)"},
+			{"\tTab", "\rReturn"},
+			{"Ann", "a=b"},
+		},
+	}}}))
+	assert.Equal(t, "Student,Feedback\n\"'=HYPERLINK(\"\"http://example.com\"\")\",'+1\n'-2+3,'@SUM(A1)\n'\tTab,\"'\rReturn\"\nAnn,a=b\n", buf.String())
+}
+
+// Test that long tables continue on further pages
+func TestWritePDF(t *testing.T) {
+	table := Table{Title: "Attendance", Columns: []string{"Date", "Status"}}
+	for i := 0; i < 120; i++ {
+		table.Rows = append(table.Rows, []string{fmt.Sprintf("Day %d", i), "présent"})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, Document{
+		Title:  "Report Card",
+		Fields: []Field{{Label: "Student", Value: "Zoë"}},
+		Tables: []Table{table},
+	}))
+	assert.True(t, strings.HasPrefix(buf.String(), "%PDF-"))
+	assert.Equal(t, 3, strings.Count(buf.String(), "/Type /Page\n"), "pages")
+}
@@ -0,0 +1,306 @@
+// Package importer reads the CSV files used to onboard students, users and
+// parent links in bulk and writes them through models.ImportRepository.
+//
+// Every file starts with a header naming its columns, in any order:
+//
+//	students:     name, email, date_of_birth, grade, [enrollment_date], [is_active]
+//	users:        username, email, password, first_name, last_name, role
+//	parent_links: parent_username, student_email
+//
+// Dates are written as YYYY-MM-DD. Parent links refer to parents by username
+// and to students by email, so they can link rows of the same import.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// Files are the CSV files of an import; files that are not part of the
+// import are nil
+type Files struct {
+	Students    io.Reader
+	Users       io.Reader
+	ParentLinks io.Reader
+}
+
+// Report is the outcome of an import
+type Report struct {
+	DryRun bool `json:"dry_run"`
+	// Imported is true when the rows were written
+	Imported    bool                `json:"imported"`
+	Students    int                 `json:"students"`
+	Users       int                 `json:"users"`
+	ParentLinks int                 `json:"parent_links"`
+	Errors      models.ImportErrors `json:"errors"`
+}
+
+// columns lists the required and optional columns of a file
+type columns struct {
+	required []string
+	optional []string
+}
+
+var (
+	studentColumns = columns{
+		required: []string{"name", "email", "date_of_birth", "grade"},
+		optional: []string{"enrollment_date", "is_active"},
+	}
+	userColumns = columns{
+		required: []string{"username", "email", "password", "first_name", "last_name", "role"},
+	}
+	parentLinkColumns = columns{
+		required: []string{"parent_username", "student_email"},
+	}
+)
+
+// Run checks every row of the files and imports them in one transaction,
+// or only checks them when dryRun is set. Problems with rows are reported
+// in the Report; the error is only set when the import could not be
+// attempted.
+func Run(ctx context.Context, repo models.ImportRepository, files Files, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun, Errors: models.ImportErrors{}}
+
+	batch, errs := Parse(files)
+	report.Students = len(batch.Students)
+	report.Users = len(batch.Users)
+	report.ParentLinks = len(batch.ParentLinks)
+	if len(errs) > 0 {
+		report.Errors = errs
+		return report, nil
+	}
+
+	if err := repo.Import(ctx, batch, dryRun); err != nil {
+		var rowErrors models.ImportErrors
+		if !errors.As(err, &rowErrors) {
+			return report, err
+		}
+		report.Errors = rowErrors
+		return report, nil
+	}
+
+	report.Imported = !dryRun
+	return report, nil
+}
+
+// Parse reads the files into a batch and checks each row against the model
+// rules and for duplicates within the file. Checks that need the database,
+// such as existing emails, are left to the repository.
+func Parse(files Files) (*models.ImportBatch, models.ImportErrors) {
+	batch := &models.ImportBatch{}
+	var errs models.ImportErrors
+
+	if files.Students == nil && files.Users == nil && files.ParentLinks == nil {
+		return batch, models.ImportErrors{{Message: "no files to import"}}
+	}
+
+	if files.Students != nil {
+		emails := make(map[string]bool)
+		errs = append(errs, readFile(models.ImportFileStudents, files.Students, studentColumns, func(line int, row map[string]string) error {
+			student, err := parseStudent(row)
+			if err != nil {
+				return err
+			}
+			if emails[student.Email] {
+				return fmt.Errorf("email %s is listed more than once", student.Email)
+			}
+			emails[student.Email] = true
+			batch.Students = append(batch.Students, models.ImportStudent{Line: line, Student: student})
+			return nil
+		})...)
+	}
+
+	if files.Users != nil {
+		usernames := make(map[string]bool)
+		emails := make(map[string]bool)
+		errs = append(errs, readFile(models.ImportFileUsers, files.Users, userColumns, func(line int, row map[string]string) error {
+			user, err := parseUser(row)
+			if err != nil {
+				return err
+			}
+			if usernames[user.Username] {
+				return fmt.Errorf("username %s is listed more than once", user.Username)
+			}
+			if emails[user.Email] {
+				return fmt.Errorf("email %s is listed more than once", user.Email)
+			}
+			usernames[user.Username] = true
+			emails[user.Email] = true
+			batch.Users = append(batch.Users, models.ImportUser{Line: line, User: user})
+			return nil
+		})...)
+	}
+
+	if files.ParentLinks != nil {
+		links := make(map[models.ImportParentLink]bool)
+		errs = append(errs, readFile(models.ImportFileParentLinks, files.ParentLinks, parentLinkColumns, func(line int, row map[string]string) error {
+			link := models.ImportParentLink{ParentUsername: row["parent_username"], StudentEmail: row["student_email"]}
+			if link.ParentUsername == "" || link.StudentEmail == "" {
+				return errors.New("parent_username and student_email are required")
+			}
+			if links[link] {
+				return errors.New("link is listed more than once")
+			}
+			links[link] = true
+			link.Line = line
+			batch.ParentLinks = append(batch.ParentLinks, link)
+			return nil
+		})...)
+	}
+
+	return batch, errs
+}
+
+// readFile reads a CSV file with a header and passes each row, keyed by
+// column, to parse. Errors are reported with the line of the row.
+func readFile(file string, r io.Reader, cols columns, parse func(line int, row map[string]string) error) models.ImportErrors {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return models.ImportErrors{{File: file, Line: 1, Message: "file is empty"}}
+	}
+	if err != nil {
+		return models.ImportErrors{{File: file, Line: 1, Message: err.Error()}}
+	}
+	if errs := checkHeader(file, header, cols); len(errs) > 0 {
+		return errs
+	}
+
+	var errs models.ImportErrors
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var line int
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.StartLine
+				err = parseErr.Err
+			}
+			errs = append(errs, models.ImportError{File: file, Line: line, Message: err.Error()})
+			if !errors.Is(err, csv.ErrFieldCount) {
+				// The reader cannot continue after malformed quoting
+				return errs
+			}
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[normalizeColumn(column)] = strings.TrimSpace(record[i])
+		}
+		if err := parse(line, row); err != nil {
+			errs = append(errs, models.ImportError{File: file, Line: line, Message: err.Error()})
+		}
+	}
+	return errs
+}
+
+// checkHeader checks that the header has every required column and no
+// unknown or repeated ones
+func checkHeader(file string, header []string, cols columns) models.ImportErrors {
+	var errs models.ImportErrors
+	seen := make(map[string]bool, len(header))
+	for _, column := range header {
+		column = normalizeColumn(column)
+		switch {
+		case seen[column]:
+			errs = append(errs, models.ImportError{File: file, Line: 1, Message: fmt.Sprintf("column %q is repeated", column)})
+		case !containsColumn(cols.required, column) && !containsColumn(cols.optional, column):
+			errs = append(errs, models.ImportError{File: file, Line: 1, Message: fmt.Sprintf("unknown column %q", column)})
+		}
+		seen[column] = true
+	}
+	for _, column := range cols.required {
+		if !seen[column] {
+			errs = append(errs, models.ImportError{File: file, Line: 1, Message: fmt.Sprintf("missing column %q", column)})
+		}
+	}
+	return errs
+}
+
+// parseStudent converts a students row; students are active unless
+// is_active says otherwise
+func parseStudent(row map[string]string) (models.Student, error) {
+	student := models.Student{
+		Name:     row["name"],
+		Email:    row["email"],
+		Grade:    row["grade"],
+		IsActive: true,
+	}
+
+	var err error
+	if student.DateOfBirth, err = parseDate(row["date_of_birth"]); err != nil {
+		return models.Student{}, fmt.Errorf("date_of_birth %v", err)
+	}
+	if value := row["enrollment_date"]; value != "" {
+		if student.EnrollmentDate, err = parseDate(value); err != nil {
+			return models.Student{}, fmt.Errorf("enrollment_date %v", err)
+		}
+	}
+	if value := row["is_active"]; value != "" {
+		if student.IsActive, err = strconv.ParseBool(value); err != nil {
+			return models.Student{}, errors.New("is_active must be true or false")
+		}
+	}
+
+	if err := student.Validate(); err != nil {
+		return models.Student{}, err
+	}
+	return student, nil
+}
+
+// parseUser converts a users row
+func parseUser(row map[string]string) (models.User, error) {
+	user := models.User{
+		Username:  row["username"],
+		Email:     row["email"],
+		Password:  row["password"],
+		FirstName: row["first_name"],
+		LastName:  row["last_name"],
+		Role:      models.UserRole(row["role"]),
+	}
+	if err := user.Validate(); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("is required")
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("must be a date (YYYY-MM-DD)")
+	}
+	return t, nil
+}
+
+// normalizeColumn drops the byte order mark spreadsheets put before the
+// first column along with case and spaces
+func normalizeColumn(column string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
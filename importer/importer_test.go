@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/models/memory"
+)
+
+const (
+	studentsCSV = `name,email,date_of_birth,grade,enrollment_date
+Ann Lee,ann@example.com,2016-04-01,5,2026-09-01
+Bob Lee,bob@example.com,2015-02-03,6,
+`
+	usersCSV = `username,email,password,first_name,last_name,role
+pat,pat@example.com,secret123,Pat,Lee,parent
+`
+	parentLinksCSV = `parent_username,student_email
+pat,ann@example.com
+pat,bob@example.com
+`
+)
+
+// Test that rows breaking the model rules are reported with their line
+func TestParseReportsLines(t *testing.T) {
+	batch, errs := Parse(Files{
+		Students: strings.NewReader(`Name,Email,Date_of_Birth,Grade
+Ann Lee,ann@example.com,2016-04-01,5
+Ann Again,ann@example.com,2016-04-01,5
+Bad Date,bad@example.com,01/04/2016,5
+No Email,,2016-04-01,5
+`),
+		Users: strings.NewReader(`username,email,role
+`),
+	})
+
+	assert.Len(t, batch.Students, 1)
+	assert.Equal(t, models.ImportErrors{
+		{File: "students", Line: 3, Message: "email ann@example.com is listed more than once"},
+		{File: "students", Line: 4, Message: "date_of_birth must be a date (YYYY-MM-DD)"},
+		{File: "students", Line: 5, Message: "invalid student data"},
+		{File: "users", Line: 1, Message: `missing column "password"`},
+		{File: "users", Line: 1, Message: `missing column "first_name"`},
+		{File: "users", Line: 1, Message: `missing column "last_name"`},
+	}, errs)
+}
+
+// Test that an import is written completely or not at all
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	files := func() Files {
+		return Files{
+			Students:    strings.NewReader(studentsCSV),
+			Users:       strings.NewReader(usersCSV),
+			ParentLinks: strings.NewReader(parentLinksCSV),
+		}
+	}
+
+	// A dry run checks the rows without writing them
+	report, err := Run(ctx, store.Import, files(), true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.False(t, report.Imported)
+	page, err := store.Students.List(ctx, models.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	report, err = Run(ctx, store.Import, files(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.True(t, report.Imported)
+	assert.Equal(t, 2, report.Students)
+
+	parent, err := store.Users.GetByUsername(ctx, "pat")
+	require.NoError(t, err)
+	assert.Len(t, parent.StudentIDs, 2)
+
+	// Importing again fails on every row and writes nothing
+	report, err = Run(ctx, store.Import, Files{
+		Students: strings.NewReader(studentsCSV + "Cat Lee,cat@example.com,2016-01-01,5,\n"),
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, models.ImportError{File: "students", Line: 2, Message: "student email already exists"}, report.Errors[0])
+	assert.False(t, report.Imported)
+	page, err = store.Students.List(ctx, models.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,10 +11,17 @@ import (
 	"syscall"
 	"time"
 
+	"example.com/sre-bootcamp-rest-api/auth"
 	"example.com/sre-bootcamp-rest-api/db"
+	"example.com/sre-bootcamp-rest-api/metrics"
 	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/migrations"
+	"example.com/sre-bootcamp-rest-api/models/postgres"
+	"example.com/sre-bootcamp-rest-api/notify"
 	"example.com/sre-bootcamp-rest-api/routes"
+	"example.com/sre-bootcamp-rest-api/scheduler"
+	"example.com/sre-bootcamp-rest-api/storage"
+	"example.com/sre-bootcamp-rest-api/tracing"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 )
@@ -26,6 +34,14 @@ func main() {
 	// Set up package loggers
 	db.SetLogger(logger)
 	migrations.SetLogger(logger)
+	auth.SetLogger(logger)
+	tracing.SetLogger(logger)
+
+	// Initialize tracing before the database so queries are instrumented
+	shutdownTracing, err := tracing.InitTracing(context.Background())
+	if err != nil {
+		logger.Fatalf("Failed to initialize tracing: %v", err)
+	}
 	
 	// Get database URL from environment
 	dbURL := os.Getenv("DATABASE_URL")
@@ -39,6 +55,11 @@ func main() {
 		logger.Fatalf("Failed to initialize database: %v", err)
 	}
 	logger.Info("Database connection established")
+
+	// Export connection pool statistics
+	if err := metrics.RegisterDBStats(db.DB, db.GetDBConfig().DBName); err != nil {
+		logger.Warnf("Failed to register database metrics: %v", err)
+	}
 	
 	// Run migrations
 	migrationsPath := filepath.Join(".", "migrations")
@@ -48,6 +69,22 @@ func main() {
 	}
 	logger.Info("Database migrations completed successfully")
 
+	// Initialize access token signing
+	if err := auth.InitAuth(); err != nil {
+		logger.Fatalf("Failed to initialize auth: %v", err)
+	}
+
+	// Files attached to submissions are kept on disk, on a volume shared by
+	// every instance when there are several
+	storageDir := os.Getenv("SUBMISSION_STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = filepath.Join(".", "data", "submissions")
+	}
+	blobs, err := storage.NewLocal(storageDir)
+	if err != nil {
+		logger.Fatalf("Failed to initialize submission storage: %v", err)
+	}
+
 	// Set Gin mode based on environment
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -61,8 +98,11 @@ func main() {
 	// Add middleware
 	r.Use(
 		gin.Recovery(),
+		tracing.Middleware(),
+		middleware.Metrics(),
 		middleware.RequestLogger(),
 		middleware.ErrorLogger(),
+		middleware.QueryTimeout(db.GetDBConfig().QueryTimeout),
 	)
 
 	// Basic health check endpoint
@@ -74,9 +114,22 @@ func main() {
 		})
 	})
 
+	// Prometheus metrics endpoint, scraped through the prometheus.io pod annotations
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	store := postgres.NewStore(db.DB)
+
+	// Parents get notifications in their inbox and, as they choose, by email
+	// (through SMTP_HOST, or logged when unset) and webhook
+	notifier := notify.New(store, notify.DefaultSenders()...)
+
+	// Background jobs run on every instance; advisory locks make sure each
+	// run happens on only one of them
+	jobs := scheduler.New(scheduler.NewPostgresLocker(db.DB), store.Jobs, scheduler.DefaultJobs(store, notifier)...)
+
 	// Register API routes
 	apiV1 := r.Group("/api/v1")
-	routes.RegisterRoutes(apiV1)
+	routes.RegisterRoutes(apiV1, store, blobs, jobs, notifier)
 
 	// Get port from environment variable, default to 8080
 	port := os.Getenv("PORT")
@@ -84,12 +137,21 @@ func main() {
 		port = "8080"
 	}
 
+	// Request contexts derive from baseCtx so that work still running when
+	// the shutdown grace period ends can be cancelled
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	// Create a server with graceful shutdown
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", port),
-		Handler: r,
+		Addr:        fmt.Sprintf(":%s", port),
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
+	stopJobs := jobs.Start(baseCtx)
+	stopNotifications := notifier.Start(baseCtx)
+
 	// Start the server in a goroutine
 	go func() {
 		logger.Infof("Starting server on port %s", port)
@@ -111,8 +173,24 @@ func main() {
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	// Cancel the queries of requests that outlive the grace period
+	context.AfterFunc(ctx, cancelRequests)
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatalf("Server forced to shutdown: %v", err)
+		logger.Errorf("Server forced to shutdown: %v", err)
+	}
+
+	// Stop the background jobs and notification deliveries before the
+	// database goes away
+	stopJobs()
+	stopNotifications()
+
+	// Flush spans that have not been exported yet
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warnf("Failed to shut down tracing: %v", err)
+	}
+
+	if err := db.CloseDB(); err != nil {
+		logger.Warnf("Failed to close database: %v", err)
 	}
 
 	logger.Info("Server exited gracefully")
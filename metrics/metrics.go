@@ -0,0 +1,78 @@
+// Package metrics defines the Prometheus metrics exported by the service on
+// /metrics. HTTP metrics are recorded by middleware.Metrics; domain counters
+// are incremented by the handlers.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HTTP metrics. The path label is the route template, such as
+// /api/v1/students/:id, so that IDs do not create new series.
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	}, []string{"method", "path", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	HTTPRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served.",
+	}, []string{"method", "path"})
+
+	HTTPServerErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_server_errors_total",
+		Help: "Total number of HTTP requests that returned a 5xx status.",
+	}, []string{"method", "path"})
+)
+
+// Domain metrics
+var (
+	LoginsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "student_api_logins_total",
+		Help: "Total number of successful logins.",
+	})
+
+	LoginFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "student_api_login_failures_total",
+		Help: "Total number of failed logins due to a wrong username or password.",
+	})
+
+	GradesCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "student_api_grades_created_total",
+		Help: "Total number of grades created.",
+	})
+
+	AttendanceRecordedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "student_api_attendance_recorded_total",
+		Help: "Total number of attendance records created, by attendance status.",
+	}, []string{"status"})
+
+	NotificationsDeliveredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "student_api_notifications_delivered_total",
+		Help: "Total number of notification deliveries attempted, by channel and result.",
+	}, []string{"channel", "result"})
+)
+
+// RegisterDBStats exports the connection pool statistics of the database
+// handle, as reported by sql.DB.Stats, under the go_sql_* metrics
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -1,15 +1,20 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
+	"example.com/sre-bootcamp-rest-api/auth"
+	"example.com/sre-bootcamp-rest-api/authz"
 	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 )
 
-// Role-based access control middleware
-func AuthMiddleware(allowedRoles ...models.UserRole) gin.HandlerFunc {
+// Authenticate verifies the Bearer access token and stores the caller and
+// the authorizer in the context. Authorization is left to RequirePermission.
+func Authenticate(authorizer *authz.Authorizer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -27,40 +32,100 @@ func AuthMiddleware(allowedRoles ...models.UserRole) gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		// In a real application, this would validate a JWT or session token
-		// For simplicity, we'll just check if the token is a valid user ID
-		user, err := models.GetUserByID(token)
+		// Verify the signed access token. The claims carry everything needed
+		// for authorization, so no database lookup is required here.
+		claims, err := auth.Tokens.ParseAccessToken(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Check if user has an allowed role
-		if len(allowedRoles) > 0 {
-			roleAllowed := false
-			for _, role := range allowedRoles {
-				if user.Role == role {
-					roleAllowed = true
-					break
-				}
-			}
+		user := &models.User{
+			ID:       claims.Subject,
+			Username: claims.Username,
+			Role:     claims.Role,
+		}
+
+		// Set the user and token claims in the context for later use
+		c.Set("user", user)
+		c.Set("claims", claims)
+		c.Set("authorizer", authorizer)
+
+		// Attribute the changes made by this request to the user
+		c.Request = c.Request.WithContext(models.WithAuditActor(c.Request.Context(), user.ID))
+		c.Next()
+	}
+}
 
-			if !roleAllowed {
+// RequirePermission rejects callers whose role lacks any of the given
+// permissions. It must run after Authenticate.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetUserFromContext(c)
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			allowed, err := allows(c, user, permission)
+			if err != nil {
+				log.Println("Error loading role permissions:", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify permissions"})
+				c.Abort()
+				return
+			}
+			if !allowed {
 				c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
 				c.Abort()
 				return
 			}
 		}
 
-		// Set the user in the context for later use
-		c.Set("user", user)
 		c.Next()
 	}
 }
 
-// GetUserFromContext retrieves the authenticated user from the Gin context
+// HasPermission reports whether the authenticated caller holds the
+// permission. Handlers use it for checks that depend on the resource, such as
+// moderating other users' posts. Errors are logged and treated as a denial.
+func HasPermission(c *gin.Context, permission string) bool {
+	user := GetUserFromContext(c)
+	if user == nil {
+		return false
+	}
+
+	allowed, err := allows(c, user, permission)
+	if err != nil {
+		log.Println("Error loading role permissions:", err)
+		return false
+	}
+	return allowed
+}
+
+// allows checks the permission with the authorizer set by Authenticate
+func allows(c *gin.Context, user *models.User, permission string) (bool, error) {
+	authorizer := GetAuthorizerFromContext(c)
+	if authorizer == nil {
+		return false, errors.New("no authorizer in context")
+	}
+	return authorizer.Allows(c.Request.Context(), user.Role, permission)
+}
+
+// GetAuthorizerFromContext retrieves the authorizer stored by Authenticate
+func GetAuthorizerFromContext(c *gin.Context) *authz.Authorizer {
+	authorizer, exists := c.Get("authorizer")
+	if !exists {
+		return nil
+	}
+	return authorizer.(*authz.Authorizer)
+}
+
+// GetUserFromContext retrieves the authenticated user from the Gin context.
+// Only the ID, username and role are populated; handlers that need the full
+// profile must load it from the user repository.
 func GetUserFromContext(c *gin.Context) *models.User {
 	user, exists := c.Get("user")
 	if !exists {
@@ -1,12 +1,16 @@
 package middleware
 
 import (
+	"context"
 	"os"
 	"time"
 
+	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var logger *logrus.Logger
@@ -60,8 +64,14 @@ func RequestLogger() gin.HandlerFunc {
 			c.Header("X-Request-ID", requestID)
 		}
 
-		// Add request ID to context
+		// Add request ID to context, and to the request context so the
+		// audit log can tag changes with it
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(models.WithAuditRequestID(c.Request.Context(), requestID))
+
+		// Record the request ID on the server span so traces and logs can be
+		// matched either way
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", requestID))
 
 		// Create a logger with request context
 		requestLogger := logger.WithFields(logrus.Fields{
@@ -71,7 +81,7 @@ func RequestLogger() gin.HandlerFunc {
 			"query":      query,
 			"ip":         c.ClientIP(),
 			"user_agent": c.Request.UserAgent(),
-		})
+		}).WithFields(traceFields(c.Request.Context()))
 
 
 		// Log request start
@@ -134,6 +144,7 @@ func ErrorLogger() gin.HandlerFunc {
 			} else {
 				requestLogger = logrus.NewEntry(logger)
 			}
+			requestLogger = requestLogger.WithFields(traceFields(c.Request.Context()))
 
 			for _, e := range c.Errors {
 				requestLogger.WithFields(logrus.Fields{
@@ -145,3 +156,16 @@ func ErrorLogger() gin.HandlerFunc {
 		}
 	}
 }
+
+// traceFields returns the trace and span IDs of the span in ctx, or no
+// fields if the request is not traced
+func traceFields(ctx context.Context) logrus.Fields {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return logrus.Fields{}
+	}
+	return logrus.Fields{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics returns a middleware that records request counts, latencies and
+// in-flight requests, labelled by route template, method and status
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Label by the matched route rather than the raw path so that IDs do
+		// not create a series per resource. Unmatched paths share one label.
+		path := c.FullPath()
+		if path == "" {
+			path = "unmatched"
+		}
+		method := c.Request.Method
+
+		inFlight := metrics.HTTPRequestsInFlight.WithLabelValues(method, path)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		statusLabel := strconv.Itoa(status)
+		metrics.HTTPRequestsTotal.WithLabelValues(method, path, statusLabel).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, path, statusLabel).Observe(time.Since(start).Seconds())
+		if status >= 500 {
+			metrics.HTTPServerErrorsTotal.WithLabelValues(method, path).Inc()
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/authz"
+	"github.com/gin-gonic/gin"
+)
+
+// GetScopeFromContext returns the student scope of the authenticated user,
+// loading it on first use and caching it for the rest of the request
+func GetScopeFromContext(c *gin.Context) (*authz.Scope, error) {
+	if scope, exists := c.Get("scope"); exists {
+		return scope.(*authz.Scope), nil
+	}
+
+	authorizer := GetAuthorizerFromContext(c)
+	if authorizer == nil {
+		return nil, errors.New("no authorizer in context")
+	}
+
+	scope, err := authorizer.ScopeFor(c.Request.Context(), GetUserFromContext(c))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set("scope", scope)
+	return scope, nil
+}
+
+// RequireStudentAccess rejects requests for a student, identified by the
+// given route parameter, that is outside the caller's scope
+func RequireStudentAccess(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CheckStudentAccess(c, c.Param(param)) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckStudentAccess verifies the caller may read records of the student and
+// writes an error response if not. Handlers use it after loading a resource
+// to check the student it belongs to.
+func CheckStudentAccess(c *gin.Context, studentID string) bool {
+	scope, err := GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify access"})
+		c.Abort()
+		return false
+	}
+
+	if !scope.CanAccessStudent(studentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this student's records"})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
+
+// CheckClassAccess verifies the caller teaches the class, or is unrestricted,
+// and writes an error response if not
+func CheckClassAccess(c *gin.Context, classID string) bool {
+	scope, err := GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not verify access"})
+		c.Abort()
+		return false
+	}
+
+	if !scope.CanAccessClass(classID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not teach this class"})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
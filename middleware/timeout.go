@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTimeout returns a middleware that gives each request a deadline for
+// its database work. Handlers pass the request context to the models, so an
+// expired deadline, a disconnected client or server shutdown cancels the
+// running query. A zero timeout only adds the error handling. Routes that
+// legitimately run longer, such as uploads and imports, replace the deadline
+// with ExtendQueryTimeout.
+//
+// When the context has ended and the handler answers with an error, that
+// response is replaced with 504 Gateway Timeout for an expired deadline or
+// 503 Service Unavailable for a cancellation, instead of the generic 500 or
+// misleading 404 the handler would otherwise send.
+func QueryTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := c.Request.Context()
+		ctx := parent
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		writer := &deadlineWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Set(queryDeadlineKey, &queryDeadline{parent: parent, writer: writer})
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+	}
+}
+
+// queryDeadlineKey is the gin context key of the request's queryDeadline
+const queryDeadlineKey = "query_deadline"
+
+// queryDeadline is what ExtendQueryTimeout needs from QueryTimeout: the
+// request context from before the deadline, whose cancellation still ends
+// the request, and the writer watching the request context
+type queryDeadline struct {
+	parent context.Context
+	writer *deadlineWriter
+}
+
+// ExtendQueryTimeout returns a route middleware that replaces the deadline
+// set by QueryTimeout with a longer one, or with none for a zero timeout.
+// A disconnected client or server shutdown still cancels the request.
+func ExtendQueryTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(queryDeadlineKey)
+		if !ok {
+			return
+		}
+		deadline := value.(*queryDeadline)
+
+		// Keep the values added to the context since, such as the audit actor
+		base := context.WithoutCancel(c.Request.Context())
+		ctx, cancel := context.WithCancel(base)
+		if timeout > 0 {
+			cancel()
+			ctx, cancel = context.WithTimeout(base, timeout)
+		}
+		defer cancel()
+		stop := context.AfterFunc(deadline.parent, cancel)
+		defer stop()
+
+		c.Request = c.Request.WithContext(ctx)
+		deadline.writer.ctx = ctx
+		c.Next()
+	}
+}
+
+// deadlineWriter replaces error responses written after the request context
+// has ended
+type deadlineWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	replaced bool
+}
+
+func (w *deadlineWriter) WriteHeader(code int) {
+	if w.replaced {
+		return
+	}
+	if code >= http.StatusBadRequest && w.ctx.Err() != nil && !w.Written() {
+		w.replace()
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *deadlineWriter) Write(data []byte) (int, error) {
+	if w.replaced {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *deadlineWriter) WriteString(s string) (int, error) {
+	if w.replaced {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// replace writes the timeout response in place of the handler's error
+func (w *deadlineWriter) replace() {
+	w.replaced = true
+
+	status, message := http.StatusServiceUnavailable, "The request was cancelled before it could complete"
+	if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		status, message = http.StatusGatewayTimeout, "The request took too long to complete"
+	}
+
+	w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(status)
+	json.NewEncoder(w.ResponseWriter).Encode(gin.H{"error": message})
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that an error written after the deadline becomes a 504
+func TestQueryTimeoutDeadlineExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryTimeout(time.Millisecond))
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"error":"The request took too long to complete"}`, w.Body.String())
+}
+
+// Test that an error written after cancellation becomes a 503
+func TestQueryTimeoutCanceled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryTimeout(time.Minute))
+	router.GET("/students", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch students."})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/students", nil).WithContext(ctx))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error":"The request was cancelled before it could complete"}`, w.Body.String())
+}
+
+// Test that responses within the deadline are untouched
+func TestQueryTimeoutWithinDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryTimeout(time.Minute))
+	router.GET("/students/:id", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		assert.True(t, hasDeadline)
+		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/students/1", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message":"Student not found."}`, w.Body.String())
+}
+
+// Test that ExtendQueryTimeout replaces the deadline but keeps cancellation
+func TestExtendQueryTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(QueryTimeout(time.Millisecond))
+	router.POST("/import", ExtendQueryTimeout(time.Minute), func(c *gin.Context) {
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, c.Request.Context().Err())
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid CSV."})
+	})
+	router.POST("/upload", ExtendQueryTimeout(time.Minute), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not upload."})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/import", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"message":"Invalid CSV."}`, w.Body.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/upload", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,6 @@
+-- Rollback: create_refresh_tokens_table
+-- Created: 2026-10-17T10:00:00+05:30
+
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+DROP TABLE IF EXISTS refresh_tokens;
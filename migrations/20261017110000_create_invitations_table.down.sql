@@ -0,0 +1,6 @@
+-- Rollback: create_invitations_table
+-- Created: 2026-10-17T11:00:00+05:30
+
+DROP INDEX IF EXISTS idx_invitations_email;
+DROP TABLE IF EXISTS invitation_students;
+DROP TABLE IF EXISTS invitations;
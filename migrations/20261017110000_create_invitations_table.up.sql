@@ -0,0 +1,26 @@
+-- Migration: create_invitations_table
+-- Created: 2026-10-17T11:00:00+05:30
+
+-- Invitations are created by staff and redeemed once by the invitee to create
+-- an account. Only the SHA-256 hash of the invitation code is stored.
+CREATE TABLE IF NOT EXISTS invitations (
+    id VARCHAR(36) PRIMARY KEY,
+    code_hash CHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    redeemed_at TIMESTAMP WITH TIME ZONE,
+    redeemed_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Students a parent invitation will be linked to on redemption
+CREATE TABLE IF NOT EXISTS invitation_students (
+    invitation_id VARCHAR(36) NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+    student_id VARCHAR(36) NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    PRIMARY KEY (invitation_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(email);
@@ -0,0 +1,5 @@
+-- Rollback: create_permissions_tables
+-- Created: 2026-10-17T12:00:00+05:30
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
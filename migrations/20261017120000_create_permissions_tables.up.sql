@@ -0,0 +1,82 @@
+-- Migration: create_permissions_tables
+-- Created: 2026-10-17T12:00:00+05:30
+
+-- Named permissions checked by the API
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL
+);
+
+-- Permissions granted to each role
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(20) NOT NULL,
+    permission VARCHAR(50) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('students:read', 'View student records'),
+    ('students:write', 'Create and update students'),
+    ('students:delete', 'Delete students'),
+    ('users:read', 'View user accounts'),
+    ('users:write', 'Update user accounts'),
+    ('users:delete', 'Delete user accounts'),
+    ('users:roles', 'Change user roles and parent-student links'),
+    ('users:sessions', 'Revoke the sessions of other users'),
+    ('invitations:manage', 'Create, list and revoke invitations'),
+    ('attendance:read', 'View attendance records'),
+    ('attendance:write', 'Record and update attendance'),
+    ('assignments:read', 'View assignments'),
+    ('assignments:write', 'Create, update and delete assignments'),
+    ('grades:read', 'View grades'),
+    ('grades:write', 'Create and update grades and list grades by assignment'),
+    ('forum:read', 'View forum posts and comments'),
+    ('forum:write', 'Create and edit own forum posts and comments'),
+    ('forum:moderate', 'Delete forum posts written by others'),
+    ('reports:read', 'Generate school-wide and student reports'),
+    ('reports:children', 'Generate reports for linked students'),
+    ('permissions:manage', 'View and edit role permissions')
+ON CONFLICT (name) DO NOTHING;
+
+-- Defaults mirror the role lists previously hard-coded in the routes
+INSERT INTO role_permissions (role, permission) VALUES
+    ('faculty', 'students:read'),
+    ('faculty', 'students:write'),
+    ('faculty', 'students:delete'),
+    ('faculty', 'users:read'),
+    ('faculty', 'users:write'),
+    ('faculty', 'attendance:read'),
+    ('faculty', 'attendance:write'),
+    ('faculty', 'assignments:read'),
+    ('faculty', 'assignments:write'),
+    ('faculty', 'grades:read'),
+    ('faculty', 'grades:write'),
+    ('faculty', 'forum:read'),
+    ('faculty', 'forum:write'),
+    ('faculty', 'forum:moderate'),
+    ('faculty', 'reports:read'),
+    ('staff', 'students:read'),
+    ('staff', 'students:write'),
+    ('staff', 'students:delete'),
+    ('staff', 'users:read'),
+    ('staff', 'users:write'),
+    ('staff', 'users:delete'),
+    ('staff', 'users:roles'),
+    ('staff', 'users:sessions'),
+    ('staff', 'invitations:manage'),
+    ('staff', 'attendance:read'),
+    ('staff', 'attendance:write'),
+    ('staff', 'assignments:read'),
+    ('staff', 'grades:read'),
+    ('staff', 'forum:read'),
+    ('staff', 'forum:write'),
+    ('staff', 'forum:moderate'),
+    ('staff', 'reports:read'),
+    ('staff', 'permissions:manage'),
+    ('parent', 'students:read'),
+    ('parent', 'assignments:read'),
+    ('parent', 'grades:read'),
+    ('parent', 'forum:read'),
+    ('parent', 'forum:write'),
+    ('parent', 'reports:children')
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Rollback: create_classes_tables
+-- Created: 2026-10-17T13:00:00+05:30
+
+DELETE FROM permissions WHERE name IN ('classes:read', 'classes:manage');
+
+DROP INDEX IF EXISTS idx_assignments_class_id;
+ALTER TABLE assignments DROP COLUMN IF EXISTS class_id;
+
+DROP TABLE IF EXISTS class_students;
+DROP TABLE IF EXISTS class_teachers;
+DROP TABLE IF EXISTS classes;
@@ -0,0 +1,47 @@
+-- Migration: create_classes_tables
+-- Created: 2026-10-17T13:00:00+05:30
+
+-- A class or section of a subject in a term
+CREATE TABLE IF NOT EXISTS classes (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    subject VARCHAR(50) NOT NULL,
+    term VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Faculty teaching each class
+CREATE TABLE IF NOT EXISTS class_teachers (
+    class_id VARCHAR(36) NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    teacher_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (class_id, teacher_id)
+);
+
+-- Students enrolled in each class
+CREATE TABLE IF NOT EXISTS class_students (
+    class_id VARCHAR(36) NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    student_id VARCHAR(36) NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    PRIMARY KEY (class_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_class_teachers_teacher_id ON class_teachers(teacher_id);
+CREATE INDEX IF NOT EXISTS idx_class_students_student_id ON class_students(student_id);
+
+-- Assignments belong to a class so their grades are tied to its roster.
+-- Existing assignments keep a NULL class.
+ALTER TABLE assignments
+ADD COLUMN IF NOT EXISTS class_id VARCHAR(36) REFERENCES classes(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_assignments_class_id ON assignments(class_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('classes:read', 'View classes and their rosters'),
+    ('classes:manage', 'Create, update and delete classes and assign teachers and students')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('faculty', 'classes:read'),
+    ('staff', 'classes:read'),
+    ('staff', 'classes:manage')
+ON CONFLICT (role, permission) DO NOTHING;
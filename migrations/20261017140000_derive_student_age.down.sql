@@ -0,0 +1,14 @@
+-- Rollback: derive_student_age
+-- Created: 2026-10-17T14:00:00+05:30
+
+DROP INDEX IF EXISTS idx_students_is_active;
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS age INTEGER;
+UPDATE students SET age = EXTRACT(YEAR FROM AGE(CURRENT_DATE, date_of_birth))::INTEGER;
+
+ALTER TABLE students
+ALTER COLUMN age SET NOT NULL,
+ALTER COLUMN email DROP NOT NULL,
+ALTER COLUMN date_of_birth DROP NOT NULL,
+ALTER COLUMN enrollment_date DROP NOT NULL,
+ALTER COLUMN is_active DROP NOT NULL;
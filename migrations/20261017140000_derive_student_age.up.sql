@@ -0,0 +1,23 @@
+-- Migration: derive_student_age
+-- Created: 2026-10-17T14:00:00+05:30
+
+-- Age is derived from date_of_birth from now on. Students still carrying the
+-- placeholder date written by update_students_table get one that matches
+-- their stored age.
+UPDATE students
+SET date_of_birth = (CURRENT_DATE - make_interval(years => age))::DATE
+WHERE date_of_birth IS NULL OR date_of_birth = '2000-01-01'::DATE;
+
+UPDATE students SET email = CONCAT('student_', id, '@example.com') WHERE email IS NULL;
+UPDATE students SET enrollment_date = CURRENT_TIMESTAMP WHERE enrollment_date IS NULL;
+UPDATE students SET is_active = true WHERE is_active IS NULL;
+
+ALTER TABLE students
+ALTER COLUMN email SET NOT NULL,
+ALTER COLUMN date_of_birth SET NOT NULL,
+ALTER COLUMN enrollment_date SET NOT NULL,
+ALTER COLUMN is_active SET NOT NULL,
+DROP COLUMN IF EXISTS age;
+
+-- GET /students lists active students by default
+CREATE INDEX IF NOT EXISTS idx_students_is_active ON students(is_active);
@@ -0,0 +1,11 @@
+-- Rollback: archive_students
+-- Created: 2026-10-17T15:00:00+05:30
+
+DELETE FROM permissions WHERE name IN ('students:restore', 'students:purge');
+UPDATE permissions SET description = 'Delete students' WHERE name = 'students:delete';
+
+DROP INDEX IF EXISTS idx_students_archived_at;
+
+ALTER TABLE students
+DROP COLUMN IF EXISTS archived_by,
+DROP COLUMN IF EXISTS archived_at;
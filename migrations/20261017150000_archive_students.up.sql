@@ -0,0 +1,22 @@
+-- Migration: archive_students
+-- Created: 2026-10-17T15:00:00+05:30
+
+-- Deleting a student archives it. Archived students are hidden from the API
+-- until restored, and only purged once the retention period has passed.
+ALTER TABLE students
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS archived_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_students_archived_at ON students(archived_at);
+
+UPDATE permissions SET description = 'Archive students' WHERE name = 'students:delete';
+
+INSERT INTO permissions (name, description) VALUES
+    ('students:restore', 'List and restore archived students'),
+    ('students:purge', 'Permanently delete archived students after the retention period')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('staff', 'students:restore'),
+    ('staff', 'students:purge')
+ON CONFLICT (role, permission) DO NOTHING;
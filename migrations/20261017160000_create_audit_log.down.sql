@@ -0,0 +1,7 @@
+-- Rollback: create_audit_log
+-- Created: 2026-10-17T16:00:00+05:30
+
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
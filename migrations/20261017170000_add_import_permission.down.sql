@@ -0,0 +1,4 @@
+-- Rollback: add_import_permission
+-- Created: 2026-10-17T17:00:00+05:30
+
+DELETE FROM permissions WHERE name = 'data:import';
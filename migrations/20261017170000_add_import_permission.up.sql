@@ -0,0 +1,10 @@
+-- Migration: add_import_permission
+-- Created: 2026-10-17T17:00:00+05:30
+
+INSERT INTO permissions (name, description) VALUES
+    ('data:import', 'Import students, users and parent links from CSV files')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('staff', 'data:import')
+ON CONFLICT (role, permission) DO NOTHING;
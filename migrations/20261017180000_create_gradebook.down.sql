@@ -0,0 +1,11 @@
+-- Rollback: create_gradebook
+-- Created: 2026-10-17T18:00:00+05:30
+
+DELETE FROM permissions WHERE name IN ('gradebook:manage', 'gradebook:configure');
+
+DROP INDEX IF EXISTS idx_assignments_category_id;
+ALTER TABLE assignments DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS gradebook_settings;
+DROP TABLE IF EXISTS grading_categories;
+DROP TABLE IF EXISTS grade_scales;
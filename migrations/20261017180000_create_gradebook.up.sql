@@ -0,0 +1,53 @@
+-- Migration: create_gradebook
+-- Created: 2026-10-17T18:00:00+05:30
+
+-- Letter-grade scales. steps is an array of {"letter", "min_percent"}
+-- ordered from the highest minimum down.
+CREATE TABLE IF NOT EXISTS grade_scales (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    steps JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Weighted groups of assignments, for one class or every class of a subject
+CREATE TABLE IF NOT EXISTS grading_categories (
+    id VARCHAR(36) PRIMARY KEY,
+    class_id VARCHAR(36) REFERENCES classes(id) ON DELETE CASCADE,
+    subject VARCHAR(50),
+    name VARCHAR(100) NOT NULL,
+    weight NUMERIC(7,2) NOT NULL CHECK (weight > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((class_id IS NULL) <> (subject IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_grading_categories_class_id ON grading_categories(class_id);
+CREATE INDEX IF NOT EXISTS idx_grading_categories_subject ON grading_categories(subject);
+
+-- Grading rules of a class. Classes without a row use the defaults.
+CREATE TABLE IF NOT EXISTS gradebook_settings (
+    class_id VARCHAR(36) PRIMARY KEY REFERENCES classes(id) ON DELETE CASCADE,
+    grade_scale_id VARCHAR(36) REFERENCES grade_scales(id) ON DELETE SET NULL,
+    missing_policy VARCHAR(10) NOT NULL DEFAULT 'zero' CHECK (missing_policy IN ('zero', 'exclude')),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Assignments count towards at most one category. Deleting a category
+-- leaves its assignments uncategorized.
+ALTER TABLE assignments
+ADD COLUMN IF NOT EXISTS category_id VARCHAR(36) REFERENCES grading_categories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_assignments_category_id ON assignments(category_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('gradebook:manage', 'Manage the grading categories and gradebook settings of classes taught'),
+    ('gradebook:configure', 'Manage letter-grade scales and subject-wide grading categories')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('faculty', 'gradebook:manage'),
+    ('staff', 'gradebook:manage'),
+    ('staff', 'gradebook:configure')
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1,9 @@
+-- Rollback: create_terms
+-- Created: 2026-10-17T19:00:00+05:30
+
+DELETE FROM permissions WHERE name IN ('terms:read', 'terms:manage', 'terms:finalize', 'report-cards:read');
+
+DROP TABLE IF EXISTS report_cards;
+DROP FUNCTION IF EXISTS report_cards_immutable();
+DROP TABLE IF EXISTS terms;
+DROP TABLE IF EXISTS academic_years;
@@ -0,0 +1,79 @@
+-- Migration: create_terms
+-- Created: 2026-10-17T19:00:00+05:30
+
+-- School years. Dates are inclusive and years never overlap.
+CREATE TABLE IF NOT EXISTS academic_years (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date),
+    EXCLUDE USING gist (daterange(start_date, end_date, '[]') WITH &&)
+);
+
+-- Grading periods of a year. Attendance belongs to the term its date falls
+-- in and grades to the term their assignment is due in, so terms never
+-- overlap either.
+CREATE TABLE IF NOT EXISTS terms (
+    id VARCHAR(36) PRIMARY KEY,
+    academic_year_id VARCHAR(36) NOT NULL REFERENCES academic_years(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    finalized_at TIMESTAMP WITH TIME ZONE,
+    finalized_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (academic_year_id, name),
+    CHECK (end_date >= start_date),
+    EXCLUDE USING gist (daterange(start_date, end_date, '[]') WITH &&)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terms_academic_year_id ON terms(academic_year_id);
+
+-- Report cards taken when a term is finalized. A finalized term cannot be
+-- deleted; the cards of a purged student go with it.
+CREATE TABLE IF NOT EXISTS report_cards (
+    id VARCHAR(36) PRIMARY KEY,
+    term_id VARCHAR(36) NOT NULL REFERENCES terms(id) ON DELETE RESTRICT,
+    student_id VARCHAR(36) NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    student_name VARCHAR(100) NOT NULL,
+    data JSONB NOT NULL,
+    document JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (term_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_cards_student_id ON report_cards(student_id);
+
+-- Report cards never change once taken
+CREATE OR REPLACE FUNCTION report_cards_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'report_cards are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS report_cards_immutable ON report_cards;
+CREATE TRIGGER report_cards_immutable
+BEFORE UPDATE ON report_cards
+FOR EACH ROW EXECUTE FUNCTION report_cards_immutable();
+
+INSERT INTO permissions (name, description) VALUES
+    ('terms:read', 'View academic years and terms'),
+    ('terms:manage', 'Create, update and delete academic years and terms'),
+    ('terms:finalize', 'Finalize terms, locking their grades and taking report cards'),
+    ('report-cards:read', 'View and download the report cards of finalized terms')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('faculty', 'terms:read'),
+    ('faculty', 'report-cards:read'),
+    ('staff', 'terms:read'),
+    ('staff', 'terms:manage'),
+    ('staff', 'terms:finalize'),
+    ('staff', 'report-cards:read'),
+    ('parent', 'terms:read'),
+    ('parent', 'report-cards:read')
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Rollback: create_student_grade_history
+-- Created: 2026-10-17T20:00:00+05:30
+
+DELETE FROM permissions WHERE name = 'promotions:manage';
+
+DROP TABLE IF EXISTS student_grade_history;
@@ -0,0 +1,27 @@
+-- Migration: create_student_grade_history
+-- Created: 2026-10-17T20:00:00+05:30
+
+-- Grade-level history of each student, written by year-end promotions. A
+-- student had to_grade from effective_date on and from_grade before it, so
+-- reports over past periods show the grade level of the time. A student
+-- changes grade level at most once a day, which also keeps a rollover from
+-- being applied twice.
+CREATE TABLE IF NOT EXISTS student_grade_history (
+    id VARCHAR(36) PRIMARY KEY,
+    student_id VARCHAR(36) NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    from_grade VARCHAR(50) NOT NULL,
+    to_grade VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('promote', 'hold', 'graduate')),
+    effective_date DATE NOT NULL,
+    recorded_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (student_id, effective_date)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('promotions:manage', 'Preview and apply year-end grade-level promotions')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('staff', 'promotions:manage')
+ON CONFLICT (role, permission) DO NOTHING;
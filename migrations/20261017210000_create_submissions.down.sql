@@ -0,0 +1,7 @@
+-- Rollback: create_submissions
+-- Created: 2026-10-17T21:00:00+05:30
+
+DELETE FROM permissions WHERE name IN ('submissions:read', 'submissions:write');
+
+DROP TABLE IF EXISTS submission_files;
+DROP TABLE IF EXISTS submissions;
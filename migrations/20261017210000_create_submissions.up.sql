@@ -0,0 +1,43 @@
+-- Migration: create_submissions
+-- Created: 2026-10-17T21:00:00+05:30
+
+-- Work handed in for assignments. The status is decided by when the
+-- submission was received compared to the due date, and the student's grade
+-- for the assignment takes the status of their latest submission.
+CREATE TABLE IF NOT EXISTS submissions (
+    id VARCHAR(36) PRIMARY KEY,
+    assignment_id VARCHAR(36) NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id VARCHAR(36) NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    text TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('completed', 'late')),
+    submitted_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_submissions_assignment_id ON submissions(assignment_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_student_assignment ON submissions(student_id, assignment_id, submitted_at DESC);
+
+-- Files attached to a submission. Their content is kept in blob storage
+-- under storage_key.
+CREATE TABLE IF NOT EXISTS submission_files (
+    id VARCHAR(36) PRIMARY KEY,
+    submission_id VARCHAR(36) NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_files_submission_id ON submission_files(submission_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('submissions:read', 'View submitted work and download its files'),
+    ('submissions:write', 'Hand in work for assignments on behalf of linked students')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('faculty', 'submissions:read'),
+    ('staff', 'submissions:read'),
+    ('parent', 'submissions:read'),
+    ('parent', 'submissions:write')
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1,8 @@
+-- Rollback: create_job_runs
+-- Created: 2026-10-17T22:00:00+05:30
+
+DELETE FROM permissions WHERE name = 'jobs:manage';
+
+DROP TABLE IF EXISTS job_runs;
+
+ALTER TABLE assignments DROP COLUMN IF EXISTS max_score;
@@ -0,0 +1,27 @@
+-- Migration: create_job_runs
+-- Created: 2026-10-17T22:00:00+05:30
+
+-- Points possible of an assignment, given to the grades of students marked
+-- missing automatically
+ALTER TABLE assignments
+ADD COLUMN IF NOT EXISTS max_score DECIMAL(5,2) NOT NULL DEFAULT 100 CHECK (max_score >= 0);
+
+-- Last run of each background job. Every replica runs the scheduler and
+-- reads this table, so the status is the same whichever replica is asked.
+CREATE TABLE IF NOT EXISTS job_runs (
+    name VARCHAR(100) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    result TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    instance VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('jobs:manage', 'View the status of background jobs and run them on demand')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('staff', 'jobs:manage')
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1,7 @@
+-- Rollback: create_notifications
+-- Created: 2026-10-17T23:00:00+05:30
+
+DELETE FROM permissions WHERE name = 'notifications:read';
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
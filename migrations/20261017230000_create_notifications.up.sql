@@ -0,0 +1,39 @@
+-- Migration: create_notifications
+-- Created: 2026-10-17T23:00:00+05:30
+
+-- Inbox of each user. A user gets at most one notification of each kind
+-- per record, so saving the same absence or grade twice notifies once.
+CREATE TABLE IF NOT EXISTS notifications (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    student_id VARCHAR(36) NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('absence', 'low_grade', 'missing_assignment', 'forum_comment')),
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id VARCHAR(36) NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, kind, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_student_id ON notifications(student_id);
+
+-- Kinds of notification each user wants delivered by email and by webhook.
+-- Users without a row get every kind by email.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT[] NOT NULL DEFAULT '{}',
+    webhook TEXT[] NOT NULL DEFAULT '{}',
+    webhook_url VARCHAR(2048) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('notifications:read', 'View own notifications and choose how they are delivered')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('parent', 'notifications:read')
+ON CONFLICT (role, permission) DO NOTHING;
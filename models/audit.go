@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditAction is the kind of change an audit entry records
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditArchive AuditAction = "archive"
+	AuditRestore AuditAction = "restore"
+)
+
+// Entity types recorded in the audit log
+const (
+	AuditEntityGrade      = "grade"
+	AuditEntityAttendance = "attendance"
+	AuditEntityUser       = "user"
+	AuditEntityStudent    = "student"
+)
+
+// AuditEntry records one change to a grade, attendance record, user or
+// student. Entries are written by the repositories together with the change
+// they describe and are never modified afterwards.
+type AuditEntry struct {
+	ID string `json:"id"`
+	// ActorID is the user who made the change; it is empty for changes
+	// made without a signed-in user, such as self-service registration
+	ActorID    string      `json:"actor_id,omitempty"`
+	Action     AuditAction `json:"action"`
+	EntityType string      `json:"entity_type"`
+	EntityID   string      `json:"entity_id"`
+	// Before and After are the record as JSON; Before is null for creations
+	// and After is null for deletions
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditListSpec declares how the audit log can be sorted and filtered
+var AuditListSpec = ListSpec{
+	Fields: map[string]FieldKind{
+		"created_at":  KindTime,
+		"actor_id":    KindString,
+		"action":      KindString,
+		"entity_type": KindString,
+		"entity_id":   KindString,
+	},
+	Sortable:    []string{"created_at"},
+	DefaultSort: "-created_at",
+	Filters: map[string]Filter{
+		"actor_id":    {Field: "actor_id", Op: OpEq},
+		"action":      {Field: "action", Op: OpEq},
+		"entity_type": {Field: "entity_type", Op: OpEq},
+		"entity_id":   {Field: "entity_id", Op: OpEq},
+		"from":        {Field: "created_at", Op: OpGte},
+		"to":          {Field: "created_at", Op: OpLte},
+	},
+}
+
+// ListValue returns the value of a field of AuditListSpec
+func (e AuditEntry) ListValue(field string) interface{} {
+	switch field {
+	case "created_at":
+		return e.CreatedAt
+	case "actor_id":
+		return e.ActorID
+	case "action":
+		return string(e.Action)
+	case "entity_type":
+		return e.EntityType
+	case "entity_id":
+		return e.EntityID
+	}
+	return e.ID
+}
+
+type auditActorKey struct{}
+type auditRequestIDKey struct{}
+
+// WithAuditActor returns a context whose changes are attributed to the user
+func WithAuditActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, userID)
+}
+
+// WithAuditRequestID returns a context whose changes are tagged with the
+// request ID
+func WithAuditRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, auditRequestIDKey{}, requestID)
+}
+
+// AuditActor returns the user set by WithAuditActor, if any
+func AuditActor(ctx context.Context) string {
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// AuditRequestID returns the request ID set by WithAuditRequestID, if any
+func AuditRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(auditRequestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidTeacher is returned when a class is assigned a teacher that is not a faculty user
+var ErrInvalidTeacher = errors.New("class teachers must be faculty users")
+
+// Class represents a class or section of a subject in a term, with its
+// teachers and enrolled students
+type Class struct {
+	ID         string    `json:"id,omitempty"`
+	Name       string    `json:"name" binding:"required"`
+	Subject    string    `json:"subject" binding:"required"`
+	Term       string    `json:"term" binding:"required"`
+	TeacherIDs []string  `json:"teacher_ids"`
+	StudentIDs []string  `json:"student_ids"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks that the required class fields are set
+func (c *Class) Validate() error {
+	if c.Name == "" || c.Subject == "" || c.Term == "" {
+		return errors.New("invalid class data")
+	}
+	return nil
+}
@@ -2,12 +2,7 @@ package models
 
 import (
 	"errors"
-	"fmt"
-	"log"
 	"time"
-
-	"example.com/sre-bootcamp-rest-api/db"
-	"github.com/google/uuid"
 )
 
 // AssignmentStatus represents the status of an assignment
@@ -26,14 +21,17 @@ const (
 
 // Assignment represents an academic assignment for students
 type Assignment struct {
-	ID          string           `json:"id,omitempty"`
-	Title       string           `json:"title" binding:"required"`
-	Description string           `json:"description"`
-	Subject     string           `json:"subject" binding:"required"`
-	DueDate     time.Time        `json:"due_date" binding:"required"`
-	CreatedBy   string           `json:"created_by" binding:"required"` // ID of user who created the assignment
-	CreatedAt   time.Time        `json:"created_at,omitempty"`
-	UpdatedAt   time.Time        `json:"updated_at,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	Title       string    `json:"title" binding:"required"`
+	Description string    `json:"description"`
+	Subject     string    `json:"subject" binding:"required"`
+	DueDate     time.Time `json:"due_date" binding:"required"`
+	ClassID     string    `json:"class_id"`                      // Class whose roster the assignment is graded against
+	CategoryID  string    `json:"category_id"`                   // Grading category the assignment counts towards, if any
+	MaxScore    float64   `json:"max_score"`                     // Points possible, given to grades marked missing automatically
+	CreatedBy   string    `json:"created_by" binding:"required"` // ID of user who created the assignment
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
 }
 
 // Grade represents a student's grade for a particular assignment
@@ -50,377 +48,72 @@ type Grade struct {
 	UpdatedAt    time.Time        `json:"updated_at,omitempty"`
 }
 
-// Save persists a new assignment to the database
-func (a *Assignment) Save() error {
-	if a.Title == "" || a.Subject == "" || a.CreatedBy == "" {
-		return errors.New("invalid assignment data")
-	}
-
-	// Generate UUID if ID is empty
-	if a.ID == "" {
-		a.ID = uuid.New().String()
-	}
-
-	// Set timestamps
-	now := time.Now()
-	a.CreatedAt = now
-	a.UpdatedAt = now
-
-	query := `INSERT INTO assignments 
-			(id, title, description, subject, due_date, created_by, created_at, updated_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-	log.Printf("Executing INSERT query: %s", query)
-
-	result, err := db.DB.Exec(query, a.ID, a.Title, a.Description, a.Subject, a.DueDate, a.CreatedBy, a.CreatedAt, a.UpdatedAt)
-	if err != nil {
-		log.Printf("Error executing INSERT: %v", err)
-		return fmt.Errorf("failed to execute insert query: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting affected rows: %v", err)
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-	if rows == 0 {
-		return errors.New("failed to create assignment: no rows affected")
-	}
-
-	log.Printf("Successfully created assignment with ID: %s", a.ID)
-	return nil
+// AssignmentListSpec declares how assignments can be sorted and filtered
+var AssignmentListSpec = ListSpec{
+	Fields: map[string]FieldKind{
+		"title":       KindString,
+		"subject":     KindString,
+		"due_date":    KindTime,
+		"class_id":    KindString,
+		"category_id": KindString,
+	},
+	Sortable:    []string{"due_date", "title", "subject"},
+	DefaultSort: "due_date",
+	Filters: map[string]Filter{
+		"subject":     {Field: "subject", Op: OpEq},
+		"class_id":    {Field: "class_id", Op: OpEq},
+		"category_id": {Field: "category_id", Op: OpEq},
+		"due_before":  {Field: "due_date", Op: OpLt},
+		"due_after":   {Field: "due_date", Op: OpGte},
+	},
 }
 
-// Update updates an existing assignment in the database
-func (a *Assignment) Update() error {
-	if a.ID == "" || a.Title == "" || a.Subject == "" || a.CreatedBy == "" {
-		return errors.New("invalid assignment data")
-	}
-
-	// Update timestamp
-	a.UpdatedAt = time.Now()
-
-	query := `UPDATE assignments SET 
-			title = $1, description = $2, subject = $3, due_date = $4, created_by = $5, updated_at = $6 
-			WHERE id = $7`
-	log.Printf("Executing UPDATE query: %s", query)
-
-	result, err := db.DB.Exec(query, a.Title, a.Description, a.Subject, a.DueDate, a.CreatedBy, a.UpdatedAt, a.ID)
-	if err != nil {
-		log.Printf("Error executing UPDATE: %v", err)
-		return fmt.Errorf("failed to execute update query: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting affected rows: %v", err)
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-	if rows == 0 {
-		return errors.New("assignment not found")
-	}
-
-	log.Printf("Successfully updated assignment with ID: %s", a.ID)
-	return nil
-}
-
-// Delete removes an assignment from the database
-func (a *Assignment) Delete() error {
-	if a.ID == "" {
-		return errors.New("assignment ID is required")
-	}
-
-	query := "DELETE FROM assignments WHERE id = $1"
-	log.Printf("Executing DELETE query: %s", query)
-
-	result, err := db.DB.Exec(query, a.ID)
-	if err != nil {
-		log.Printf("Error executing DELETE: %v", err)
-		return fmt.Errorf("failed to execute delete query: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting affected rows: %v", err)
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-	if rows == 0 {
-		return errors.New("assignment not found")
-	}
-
-	log.Printf("Successfully deleted assignment with ID: %s", a.ID)
-	return nil
-}
-
-// GetAssignmentByID retrieves an assignment by its ID
-func GetAssignmentByID(id string) (*Assignment, error) {
-	if db.DB == nil {
-		return nil, errors.New("database connection not initialized")
-	}
-
-	query := `SELECT id, title, description, subject, due_date, created_by, created_at, updated_at 
-			FROM assignments WHERE id = $1`
-	log.Printf("Executing SELECT query: %s", query)
-
-	var assignment Assignment
-	err := db.DB.QueryRow(query, id).Scan(
-		&assignment.ID,
-		&assignment.Title,
-		&assignment.Description,
-		&assignment.Subject,
-		&assignment.DueDate,
-		&assignment.CreatedBy,
-		&assignment.CreatedAt,
-		&assignment.UpdatedAt,
-	)
-	if err != nil {
-		log.Printf("Error scanning row: %v", err)
-		return nil, fmt.Errorf("failed to scan assignment row: %w", err)
-	}
-
-	log.Printf("Successfully retrieved assignment with ID: %s", assignment.ID)
-	return &assignment, nil
-}
-
-// GetAllAssignments retrieves all assignments
-func GetAllAssignments() ([]Assignment, error) {
-	if db.DB == nil {
-		return nil, errors.New("database connection not initialized")
-	}
-
-	query := `SELECT id, title, description, subject, due_date, created_by, created_at, updated_at 
-			FROM assignments ORDER BY due_date`
-	log.Printf("Executing SELECT query: %s", query)
-
-	rows, err := db.DB.Query(query)
-	if err != nil {
-		log.Printf("Error executing SELECT: %v", err)
-		return nil, fmt.Errorf("failed to execute select query: %w", err)
-	}
-	defer rows.Close()
-
-	var assignments []Assignment
-	for rows.Next() {
-		var assignment Assignment
-		err := rows.Scan(
-			&assignment.ID,
-			&assignment.Title,
-			&assignment.Description,
-			&assignment.Subject,
-			&assignment.DueDate,
-			&assignment.CreatedBy,
-			&assignment.CreatedAt,
-			&assignment.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning row: %v", err)
-			return nil, fmt.Errorf("failed to scan assignment row: %w", err)
-		}
-		assignments = append(assignments, assignment)
-	}
-
-	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
-		return nil, fmt.Errorf("error iterating assignment rows: %w", err)
-	}
-
-	return assignments, nil
+// ListValue returns the value of a field of AssignmentListSpec
+func (a Assignment) ListValue(field string) interface{} {
+	switch field {
+	case "title":
+		return a.Title
+	case "subject":
+		return a.Subject
+	case "due_date":
+		return a.DueDate
+	case "class_id":
+		return a.ClassID
+	case "category_id":
+		return a.CategoryID
+	}
+	return a.ID
 }
 
-// Save persists a new grade to the database
-func (g *Grade) Save() error {
-	if g.StudentID == "" || g.AssignmentID == "" || g.GradedBy == "" {
-		return errors.New("invalid grade data")
-	}
+// ErrAssignmentClassRequired is returned when an assignment is created without a class
+var ErrAssignmentClassRequired = errors.New("assignment class is required")
 
-	// Generate UUID if ID is empty
-	if g.ID == "" {
-		g.ID = uuid.New().String()
-	}
-
-	// Set timestamps
-	now := time.Now()
-	g.CreatedAt = now
-	g.UpdatedAt = now
+// DefaultMaxScore is the points possible of assignments created without a max_score
+const DefaultMaxScore = 100
 
-	query := `INSERT INTO grades 
-			(id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-	log.Printf("Executing INSERT query: %s", query)
+// MissingFeedback is the feedback of grades marked missing automatically
+const MissingFeedback = "Marked missing automatically after the due date."
 
-	result, err := db.DB.Exec(query, g.ID, g.StudentID, g.AssignmentID, g.Score, g.MaxScore, g.Status, g.Feedback, g.GradedBy, g.CreatedAt, g.UpdatedAt)
-	if err != nil {
-		log.Printf("Error executing INSERT: %v", err)
-		return fmt.Errorf("failed to execute insert query: %w", err)
+// Validate checks that the required assignment fields are set and gives
+// assignments without a max score the default. Assignments created before
+// classes existed have no class, so the class is only required on creation.
+func (a *Assignment) Validate() error {
+	if a.Title == "" || a.Subject == "" || a.CreatedBy == "" {
+		return errors.New("invalid assignment data")
 	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting affected rows: %v", err)
-		return fmt.Errorf("failed to get affected rows: %w", err)
+	if a.MaxScore < 0 {
+		return errors.New("max_score may not be negative")
 	}
-	if rows == 0 {
-		return errors.New("failed to create grade: no rows affected")
+	if a.MaxScore == 0 {
+		a.MaxScore = DefaultMaxScore
 	}
-
-	log.Printf("Successfully created grade with ID: %s", g.ID)
 	return nil
 }
 
-// Update updates an existing grade in the database
-func (g *Grade) Update() error {
-	if g.ID == "" || g.StudentID == "" || g.AssignmentID == "" || g.GradedBy == "" {
+// Validate checks that the required grade fields are set
+func (g *Grade) Validate() error {
+	if g.StudentID == "" || g.AssignmentID == "" || g.GradedBy == "" {
 		return errors.New("invalid grade data")
 	}
-
-	// Update timestamp
-	g.UpdatedAt = time.Now()
-
-	query := `UPDATE grades SET 
-			student_id = $1, assignment_id = $2, score = $3, max_score = $4, status = $5, feedback = $6, graded_by = $7, updated_at = $8 
-			WHERE id = $9`
-	log.Printf("Executing UPDATE query: %s", query)
-
-	result, err := db.DB.Exec(query, g.StudentID, g.AssignmentID, g.Score, g.MaxScore, g.Status, g.Feedback, g.GradedBy, g.UpdatedAt, g.ID)
-	if err != nil {
-		log.Printf("Error executing UPDATE: %v", err)
-		return fmt.Errorf("failed to execute update query: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting affected rows: %v", err)
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-	if rows == 0 {
-		return errors.New("grade not found")
-	}
-
-	log.Printf("Successfully updated grade with ID: %s", g.ID)
 	return nil
 }
-
-// GetGradeByID retrieves a grade by its ID
-func GetGradeByID(id string) (*Grade, error) {
-	if db.DB == nil {
-		return nil, errors.New("database connection not initialized")
-	}
-
-	query := `SELECT id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at 
-			FROM grades WHERE id = $1`
-	log.Printf("Executing SELECT query: %s", query)
-
-	var grade Grade
-	err := db.DB.QueryRow(query, id).Scan(
-		&grade.ID,
-		&grade.StudentID,
-		&grade.AssignmentID,
-		&grade.Score,
-		&grade.MaxScore,
-		&grade.Status,
-		&grade.Feedback,
-		&grade.GradedBy,
-		&grade.CreatedAt,
-		&grade.UpdatedAt,
-	)
-	if err != nil {
-		log.Printf("Error scanning row: %v", err)
-		return nil, fmt.Errorf("failed to scan grade row: %w", err)
-	}
-
-	log.Printf("Successfully retrieved grade with ID: %s", grade.ID)
-	return &grade, nil
-}
-
-// GetGradesByStudentID retrieves all grades for a student
-func GetGradesByStudentID(studentID string) ([]Grade, error) {
-	if db.DB == nil {
-		return nil, errors.New("database connection not initialized")
-	}
-
-	query := `SELECT id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at 
-			FROM grades WHERE student_id = $1`
-	log.Printf("Executing SELECT query: %s", query)
-
-	rows, err := db.DB.Query(query, studentID)
-	if err != nil {
-		log.Printf("Error executing SELECT: %v", err)
-		return nil, fmt.Errorf("failed to execute select query: %w", err)
-	}
-	defer rows.Close()
-
-	var grades []Grade
-	for rows.Next() {
-		var grade Grade
-		err := rows.Scan(
-			&grade.ID,
-			&grade.StudentID,
-			&grade.AssignmentID,
-			&grade.Score,
-			&grade.MaxScore,
-			&grade.Status,
-			&grade.Feedback,
-			&grade.GradedBy,
-			&grade.CreatedAt,
-			&grade.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning row: %v", err)
-			return nil, fmt.Errorf("failed to scan grade row: %w", err)
-		}
-		grades = append(grades, grade)
-	}
-
-	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
-		return nil, fmt.Errorf("error iterating grade rows: %w", err)
-	}
-
-	return grades, nil
-}
-
-// GetGradesByAssignmentID retrieves all grades for an assignment
-func GetGradesByAssignmentID(assignmentID string) ([]Grade, error) {
-	if db.DB == nil {
-		return nil, errors.New("database connection not initialized")
-	}
-
-	query := `SELECT id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at 
-			FROM grades WHERE assignment_id = $1`
-	log.Printf("Executing SELECT query: %s", query)
-
-	rows, err := db.DB.Query(query, assignmentID)
-	if err != nil {
-		log.Printf("Error executing SELECT: %v", err)
-		return nil, fmt.Errorf("failed to execute select query: %w", err)
-	}
-	defer rows.Close()
-
-	var grades []Grade
-	for rows.Next() {
-		var grade Grade
-		err := rows.Scan(
-			&grade.ID,
-			&grade.StudentID,
-			&grade.AssignmentID,
-			&grade.Score,
-			&grade.MaxScore,
-			&grade.Status,
-			&grade.Feedback,
-			&grade.GradedBy,
-			&grade.CreatedAt,
-			&grade.UpdatedAt,
-		)
-		if err != nil {
-			log.Printf("Error scanning row: %v", err)
-			return nil, fmt.Errorf("failed to scan grade row: %w", err)
-		}
-		grades = append(grades, grade)
-	}
-
-	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
-		return nil, fmt.Errorf("error iterating grade rows: %w", err)
-	}
-
-	return grades, nil
-}
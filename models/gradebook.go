@@ -0,0 +1,155 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MissingPolicy decides how missing assignments count towards term averages
+type MissingPolicy string
+
+const (
+	// MissingAsZero counts a missing assignment as a score of zero
+	MissingAsZero MissingPolicy = "zero"
+	// MissingExcluded leaves missing assignments out of the average
+	MissingExcluded MissingPolicy = "exclude"
+)
+
+// GradingCategory groups assignments, such as homework, quizzes or exams,
+// that make up a weighted share of a term average. A category belongs
+// either to a class or to every class of a subject. Weights are relative:
+// the weights of the categories with grades are scaled to add up to 100%.
+type GradingCategory struct {
+	ID        string    `json:"id,omitempty"`
+	ClassID   string    `json:"class_id,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Name      string    `json:"name" binding:"required"`
+	Weight    float64   `json:"weight" binding:"required"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// ErrCategoryMismatch is returned when an assignment is given a category of
+// another class or subject
+var ErrCategoryMismatch = errors.New("grading category does not apply to the assignment's class or subject")
+
+// Validate checks that the category has a name, a positive weight and
+// belongs to exactly one class or subject
+func (c *GradingCategory) Validate() error {
+	if c.Name == "" {
+		return errors.New("category name is required")
+	}
+	if c.Weight <= 0 {
+		return errors.New("category weight must be positive")
+	}
+	if (c.ClassID == "") == (c.Subject == "") {
+		return errors.New("a category belongs to either a class or a subject")
+	}
+	return nil
+}
+
+// AppliesTo reports whether the assignment can be put in the category
+func (c *GradingCategory) AppliesTo(a *Assignment) bool {
+	if c.ClassID != "" {
+		return c.ClassID == a.ClassID
+	}
+	return c.Subject == a.Subject
+}
+
+// GradeScale maps percentages to letter grades. Each step gives the lowest
+// percentage that earns its letter.
+type GradeScale struct {
+	ID        string      `json:"id,omitempty"`
+	Name      string      `json:"name" binding:"required"`
+	Steps     []GradeStep `json:"steps" binding:"required"`
+	CreatedAt time.Time   `json:"created_at,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at,omitempty"`
+}
+
+// GradeStep is the letter earned from a minimum percentage upwards
+type GradeStep struct {
+	Letter     string  `json:"letter"`
+	MinPercent float64 `json:"min_percent"`
+}
+
+// DefaultGradeScale is used by classes that have not chosen a scale
+var DefaultGradeScale = GradeScale{
+	Name: "Standard",
+	Steps: []GradeStep{
+		{Letter: "A", MinPercent: 90},
+		{Letter: "B", MinPercent: 80},
+		{Letter: "C", MinPercent: 70},
+		{Letter: "D", MinPercent: 60},
+		{Letter: "F", MinPercent: 0},
+	},
+}
+
+// Validate checks the steps of the scale and orders them from the highest
+// minimum down. Every percentage must map to a letter, so one step has to
+// start at 0.
+func (s *GradeScale) Validate() error {
+	if s.Name == "" {
+		return errors.New("scale name is required")
+	}
+	if len(s.Steps) == 0 {
+		return errors.New("scale needs at least one step")
+	}
+
+	letters := make(map[string]bool, len(s.Steps))
+	minimums := make(map[float64]bool, len(s.Steps))
+	for _, step := range s.Steps {
+		if step.Letter == "" {
+			return errors.New("every step needs a letter")
+		}
+		if step.MinPercent < 0 || step.MinPercent > 100 {
+			return fmt.Errorf("minimum of %s must be between 0 and 100", step.Letter)
+		}
+		if letters[step.Letter] || minimums[step.MinPercent] {
+			return fmt.Errorf("step %s is repeated", step.Letter)
+		}
+		letters[step.Letter] = true
+		minimums[step.MinPercent] = true
+	}
+	if !minimums[0] {
+		return errors.New("the lowest step must start at 0")
+	}
+
+	sort.Slice(s.Steps, func(i, j int) bool { return s.Steps[i].MinPercent > s.Steps[j].MinPercent })
+	return nil
+}
+
+// Letter returns the letter earned by the percentage. The steps must be
+// ordered as Validate leaves them.
+func (s *GradeScale) Letter(percent float64) string {
+	for _, step := range s.Steps {
+		if percent >= step.MinPercent {
+			return step.Letter
+		}
+	}
+	return ""
+}
+
+// GradebookSettings are the grading rules of a class. Without a scale the
+// class uses DefaultGradeScale.
+type GradebookSettings struct {
+	ClassID       string        `json:"class_id"`
+	GradeScaleID  string        `json:"grade_scale_id"`
+	MissingPolicy MissingPolicy `json:"missing_policy"`
+	UpdatedAt     time.Time     `json:"updated_at,omitempty"`
+}
+
+// DefaultGradebookSettings returns the settings of a class that has not
+// changed them
+func DefaultGradebookSettings(classID string) *GradebookSettings {
+	return &GradebookSettings{ClassID: classID, MissingPolicy: MissingAsZero}
+}
+
+// Validate checks the missing assignment policy
+func (s *GradebookSettings) Validate() error {
+	if s.MissingPolicy != MissingAsZero && s.MissingPolicy != MissingExcluded {
+		return fmt.Errorf("missing_policy must be %q or %q", MissingAsZero, MissingExcluded)
+	}
+	return nil
+}
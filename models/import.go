@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Import files, as named in ImportError
+const (
+	ImportFileStudents    = "students"
+	ImportFileUsers       = "users"
+	ImportFileParentLinks = "parent_links"
+)
+
+// ImportBatch is the content of a set of import files. Rows keep their line
+// numbers so problems can be reported against the file.
+type ImportBatch struct {
+	Students    []ImportStudent
+	Users       []ImportUser
+	ParentLinks []ImportParentLink
+}
+
+// ImportStudent is a student row of an import
+type ImportStudent struct {
+	Line    int
+	Student Student
+}
+
+// ImportUser is a user row of an import
+type ImportUser struct {
+	Line int
+	User User
+}
+
+// ImportParentLink links a parent to a student, both identified by the
+// natural keys used in the import files so that rows can refer to records
+// created by the same import
+type ImportParentLink struct {
+	Line           int
+	ParentUsername string
+	StudentEmail   string
+}
+
+// ImportError is a problem with one row of an import file. Line 0 refers
+// to the file as a whole, such as a missing header.
+type ImportError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (e ImportError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// ImportErrors collects the problems of an import. An import with errors
+// writes nothing.
+type ImportErrors []ImportError
+
+func (e ImportErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
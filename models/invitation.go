@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvitationInvalid is returned for expired, revoked or already redeemed invitation codes
+var ErrInvitationInvalid = errors.New("invalid or expired invitation")
+
+// Invitation represents a single-use invitation to create an account with a
+// role chosen by staff
+type Invitation struct {
+	ID         string     `json:"id,omitempty"`
+	Email      string     `json:"email" binding:"required"`
+	Role       UserRole   `json:"role" binding:"required"`
+	StudentIDs []string   `json:"student_ids,omitempty"` // Only for parent invitations
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty"`
+	RedeemedBy *string    `json:"redeemed_by,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Validate checks the fields required to create an invitation
+func (i *Invitation) Validate() error {
+	if i.Email == "" || i.CreatedBy == "" || i.ExpiresAt.IsZero() {
+		return errors.New("invalid invitation data")
+	}
+	if !i.Role.IsValid() {
+		return fmt.Errorf("invalid user role: %s", i.Role)
+	}
+	if i.Role != RoleParent && len(i.StudentIDs) > 0 {
+		return errors.New("student links are only allowed for parent invitations")
+	}
+	return nil
+}
+
+// Redeemable reports whether the invitation can still be used to create an account
+func (i *Invitation) Redeemable(now time.Time) bool {
+	return i.RedeemedAt == nil && i.RevokedAt == nil && !now.After(i.ExpiresAt)
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// JobStatus is the outcome of a background job run
+type JobStatus string
+
+const (
+	// JobSucceeded marks a run that finished without error
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed marks a run that returned an error
+	JobFailed JobStatus = "failed"
+)
+
+// JobRun records a run of a background job. Result summarizes what a
+// successful run did; Error holds why a failed one stopped. Instance is the
+// host name of the replica that ran it.
+type JobRun struct {
+	Name       string    `json:"name"`
+	Status     JobStatus `json:"status"`
+	Result     string    `json:"result,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Instance   string    `json:"instance"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
@@ -0,0 +1,288 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultListLimit is the page size used when a request sets no limit
+	DefaultListLimit = 50
+	// MaxListLimit is the largest page size a request may ask for
+	MaxListLimit = 200
+)
+
+// ErrInvalidListOptions is returned for malformed limit, cursor, sort or
+// filter query parameters
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// FieldKind is the type of a field clients may sort or filter by
+type FieldKind int
+
+const (
+	// KindString is compared as text
+	KindString FieldKind = iota
+	// KindInt is compared as an integer
+	KindInt
+	// KindTime accepts a date (2006-01-02) or an RFC 3339 timestamp
+	KindTime
+	// KindBool accepts true or false
+	KindBool
+)
+
+// FilterOp is how a filter compares a field with the requested value
+type FilterOp string
+
+const (
+	// OpEq matches fields equal to the value
+	OpEq FilterOp = "eq"
+	// OpContains matches text fields containing the value, ignoring case
+	OpContains FilterOp = "contains"
+	// OpLt matches fields less than, or before, the value
+	OpLt FilterOp = "lt"
+	// OpLte matches fields less than or equal to the value
+	OpLte FilterOp = "lte"
+	// OpGt matches fields greater than, or after, the value
+	OpGt FilterOp = "gt"
+	// OpGte matches fields greater than or equal to the value
+	OpGte FilterOp = "gte"
+)
+
+// Filter maps a query parameter onto a comparison of a field
+type Filter struct {
+	Field string
+	Op    FilterOp
+}
+
+// ListSpec declares which fields of a listing may be sorted and filtered.
+// Every listing is also ordered by ID, which breaks ties between equal sort
+// values and makes cursors stable.
+type ListSpec struct {
+	// Fields are the fields used by Sortable and Filters, with their types
+	Fields map[string]FieldKind
+	// Sortable lists the fields accepted by the sort parameter
+	Sortable []string
+	// DefaultSort is used when the request has no sort parameter. A leading
+	// "-" sorts in descending order.
+	DefaultSort string
+	// Filters maps query parameters onto filters
+	Filters map[string]Filter
+}
+
+// Condition is a parsed filter with a value of the field's type
+type Condition struct {
+	Field string
+	Op    FilterOp
+	Value interface{}
+}
+
+// Cursor is the position after which the next page starts
+type Cursor struct {
+	Value interface{}
+	ID    string
+}
+
+// ListOptions selects one page of a listing. The zero value returns every
+// record in the default order.
+type ListOptions struct {
+	// Limit is the page size; zero means no limit
+	Limit int
+	// Sort is the field to order by, Desc reverses the order
+	Sort string
+	Desc bool
+	// After continues a listing after the last record of the previous page
+	After *Cursor
+	// Conditions must all hold for a record to be listed
+	Conditions []Condition
+	// IDs, when not nil, restricts the listing to these records
+	IDs []string
+}
+
+// Page is one page of a listing
+type Page[T any] struct {
+	Items []T
+	// NextCursor is passed as the cursor parameter to fetch the next page.
+	// It is empty on the last page.
+	NextCursor string
+	// Total is the number of records matching the conditions across all pages
+	Total int
+}
+
+// Listable is implemented by records that can be listed page by page
+type Listable interface {
+	// ListValue returns the value of a field declared in the record's ListSpec,
+	// or the record ID for "id"
+	ListValue(field string) interface{}
+}
+
+// cursorToken is the encoded form of a cursor. The sort is kept so a cursor
+// cannot be reused with a different order.
+type cursorToken struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// ParseListOptions reads the limit, cursor, sort and filter parameters of a
+// request. Parameters that are not filters of the listing are ignored.
+func ParseListOptions(query url.Values, spec ListSpec) (ListOptions, error) {
+	opts := ListOptions{Limit: DefaultListLimit}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxListLimit {
+			return ListOptions{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidListOptions, MaxListLimit)
+		}
+		opts.Limit = limit
+	}
+
+	order := query.Get("sort")
+	if order == "" {
+		order = spec.DefaultSort
+	}
+	opts.Sort = strings.TrimPrefix(order, "-")
+	opts.Desc = strings.HasPrefix(order, "-")
+	if !containsString(spec.Sortable, opts.Sort) {
+		return ListOptions{}, fmt.Errorf("%w: cannot sort by %q", ErrInvalidListOptions, opts.Sort)
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := decodeCursor(value, order, spec.Fields[opts.Sort])
+		if err != nil {
+			return ListOptions{}, err
+		}
+		opts.After = cursor
+	}
+
+	// Apply filters in a fixed order so equal requests build equal queries
+	params := make([]string, 0, len(spec.Filters))
+	for param := range spec.Filters {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		filter := spec.Filters[param]
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseFieldValue(value, spec.Fields[filter.Field])
+		if err != nil {
+			return ListOptions{}, fmt.Errorf("%w: %s: %v", ErrInvalidListOptions, param, err)
+		}
+		opts.Conditions = append(opts.Conditions, Condition{Field: filter.Field, Op: filter.Op, Value: parsed})
+	}
+
+	return opts, nil
+}
+
+// WithDefaultSort returns the options with the default sort of the spec
+// when none is set
+func (o ListOptions) WithDefaultSort(spec ListSpec) ListOptions {
+	if o.Sort == "" {
+		o.Sort = strings.TrimPrefix(spec.DefaultSort, "-")
+		o.Desc = strings.HasPrefix(spec.DefaultSort, "-")
+	}
+	return o
+}
+
+// NewPage builds a page from records fetched with one more row than the
+// limit; the extra row only signals that another page follows
+func NewPage[T Listable](items []T, opts ListOptions, total int) Page[T] {
+	page := Page[T]{Items: items, Total: total}
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = opts.encodeCursor(last.ListValue(opts.Sort), fmt.Sprint(last.ListValue("id")))
+	}
+	return page
+}
+
+// encodeCursor returns the cursor for the position after the given record
+func (o ListOptions) encodeCursor(value interface{}, id string) string {
+	order := o.Sort
+	if o.Desc {
+		order = "-" + order
+	}
+
+	token := cursorToken{Sort: order, Value: formatFieldValue(value), ID: id}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor issued for the same sort order
+func decodeCursor(value, order string, kind FieldKind) (*Cursor, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", ErrInvalidListOptions)
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ID == "" {
+		return nil, invalid
+	}
+	if token.Sort != order {
+		return nil, fmt.Errorf("%w: cursor was issued for a different sort", ErrInvalidListOptions)
+	}
+
+	parsed, err := parseFieldValue(token.Value, kind)
+	if err != nil {
+		return nil, invalid
+	}
+	return &Cursor{Value: parsed, ID: token.ID}, nil
+}
+
+// parseFieldValue converts a query parameter to the type of a field
+func parseFieldValue(value string, kind FieldKind) (interface{}, error) {
+	switch kind {
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return n, nil
+	case KindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	case KindTime:
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, errors.New("must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		}
+		return t, nil
+	default:
+		return value, nil
+	}
+}
+
+// formatFieldValue is the inverse of parseFieldValue
+func formatFieldValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
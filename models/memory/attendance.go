@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// AttendanceRepository keeps attendance records in memory
+type AttendanceRepository struct {
+	db *database
+}
+
+// Create stores a new attendance record. A student can only have one record
+// per day.
+func (r *AttendanceRepository) Create(ctx context.Context, a *models.Attendance) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.checkAttendance(a); err != nil {
+		return err
+	}
+
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	r.db.attendance[a.ID] = *a
+	r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityAttendance, a.ID, nil, *a)
+	return nil
+}
+
+// Update updates an existing attendance record
+func (r *AttendanceRepository) Update(ctx context.Context, a *models.Attendance) error {
+	if a.ID == "" {
+		return errors.New("attendance ID is required")
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.attendance[a.ID]
+	if !ok {
+		return fmt.Errorf("attendance record %w", models.ErrNotFound)
+	}
+	if err := r.db.checkAttendance(a); err != nil {
+		return err
+	}
+
+	a.CreatedAt = existing.CreatedAt
+	a.UpdatedAt = time.Now()
+
+	r.db.attendance[a.ID] = *a
+	r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityAttendance, a.ID, existing, *a)
+	return nil
+}
+
+// Upsert creates or replaces attendance records, matching existing records
+// on student and date. Every record is checked before any is written.
+func (r *AttendanceRepository) Upsert(ctx context.Context, records []*models.Attendance) ([]bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, a := range records {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := r.db.students[a.StudentID]; !ok {
+			return nil, fmt.Errorf("student %w", models.ErrNotFound)
+		}
+	}
+
+	now := time.Now()
+	created := make([]bool, len(records))
+	for i, a := range records {
+		var before interface{}
+		a.ID = uuid.New().String()
+		a.CreatedAt = now
+		for id, attendance := range r.db.attendance {
+			if attendance.StudentID == a.StudentID && sameDay(attendance.Date, a.Date) {
+				before = attendance
+				a.ID = id
+				a.CreatedAt = attendance.CreatedAt
+				break
+			}
+		}
+		a.UpdatedAt = now
+
+		r.db.attendance[a.ID] = *a
+		if before == nil {
+			created[i] = true
+			r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityAttendance, a.ID, nil, *a)
+		} else {
+			r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityAttendance, a.ID, before, *a)
+		}
+	}
+	return created, nil
+}
+
+// checkAttendance checks that the student exists and has no other record
+// on the same day. The caller must hold the lock.
+func (db *database) checkAttendance(a *models.Attendance) error {
+	if _, ok := db.students[a.StudentID]; !ok {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+	for id, attendance := range db.attendance {
+		if id != a.ID && attendance.StudentID == a.StudentID && sameDay(attendance.Date, a.Date) {
+			return errors.New("attendance already recorded for this student on this date")
+		}
+	}
+	return nil
+}
+
+// sameDay reports whether both times fall on the same calendar date, like
+// values of a DATE column
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// Delete removes an attendance record
+func (r *AttendanceRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("attendance ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.attendance[id]
+	if !ok {
+		return fmt.Errorf("attendance record %w", models.ErrNotFound)
+	}
+
+	delete(r.db.attendance, id)
+	r.db.recordAudit(ctx, models.AuditDelete, models.AuditEntityAttendance, id, existing, nil)
+	return nil
+}
+
+// GetByID retrieves an attendance record by its ID
+func (r *AttendanceRepository) GetByID(ctx context.Context, id string) (*models.Attendance, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	attendance, ok := r.db.attendance[id]
+	if !ok {
+		return nil, fmt.Errorf("attendance record %w", models.ErrNotFound)
+	}
+	return &attendance, nil
+}
+
+// ListByStudentID retrieves a page of the attendance records for a student
+func (r *AttendanceRepository) ListByStudentID(ctx context.Context, studentID string, opts models.ListOptions) (models.Page[models.Attendance], error) {
+	records := r.listAttendance(func(a models.Attendance) bool { return a.StudentID == studentID })
+	return paginate(records, models.AttendanceListSpec, opts), nil
+}
+
+// ListByDateRange retrieves the attendance records between both dates,
+// inclusive, oldest first, of the given students only when studentIDs is not
+// nil
+func (r *AttendanceRepository) ListByDateRange(ctx context.Context, startDate, endDate time.Time, studentIDs []string) ([]models.Attendance, error) {
+	records := r.listAttendance(func(a models.Attendance) bool {
+		return !a.Date.Before(startDate) && !a.Date.After(endDate) && inScope(studentIDs, a.StudentID)
+	})
+	sort.Slice(records, func(i, j int) bool { return records[i].Date.Before(records[j].Date) })
+	return records, nil
+}
+
+// listAttendance returns the attendance records matching the filter
+func (r *AttendanceRepository) listAttendance(match func(models.Attendance) bool) []models.Attendance {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var records []models.Attendance
+	for _, attendance := range r.db.attendance {
+		if match(attendance) {
+			records = append(records, attendance)
+		}
+	}
+	return records
+}
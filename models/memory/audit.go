@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// AuditRepository reads the audit log kept by the other repositories
+type AuditRepository struct {
+	db *database
+}
+
+// List retrieves a page of audit entries
+func (r *AuditRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.AuditEntry], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	entries := append([]models.AuditEntry(nil), r.db.audit...)
+	return paginate(entries, models.AuditListSpec, opts), nil
+}
+
+// recordAudit appends an entry to the audit log, attributed to the actor and
+// request of the context. before and after are the stored record, or nil
+// when it did not or no longer exists. The caller must hold the write lock.
+func (db *database) recordAudit(ctx context.Context, action models.AuditAction, entityType, entityID string, before, after interface{}) {
+	db.audit = append(db.audit, models.AuditEntry{
+		ID:         uuid.New().String(),
+		ActorID:    models.AuditActor(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     auditJSON(before),
+		After:      auditJSON(after),
+		RequestID:  models.AuditRequestID(ctx),
+		CreatedAt:  time.Now(),
+	})
+}
+
+// auditJSON encodes a record for the audit log
+func auditJSON(record interface{}) json.RawMessage {
+	if record == nil {
+		return json.RawMessage("null")
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// ClassRepository keeps classes and their rosters in memory
+type ClassRepository struct {
+	db *database
+}
+
+// Create stores a new class and its roster
+func (r *ClassRepository) Create(ctx context.Context, c *models.Class) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.checkRoster(c); err != nil {
+		return err
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	r.db.classes[c.ID] = copyClass(*c)
+	return nil
+}
+
+// Update updates a class and replaces its roster
+func (r *ClassRepository) Update(ctx context.Context, c *models.Class) error {
+	if c.ID == "" {
+		return errors.New("class ID is required")
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.classes[c.ID]
+	if !ok {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+	if err := r.db.checkRoster(c); err != nil {
+		return err
+	}
+
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = time.Now()
+
+	r.db.classes[c.ID] = copyClass(*c)
+	return nil
+}
+
+// checkRoster removes duplicate IDs from the roster and checks that every
+// teacher is a faculty user. The caller must hold the lock.
+func (db *database) checkRoster(c *models.Class) error {
+	c.TeacherIDs = models.UniqueStrings(c.TeacherIDs)
+	c.StudentIDs = models.UniqueStrings(c.StudentIDs)
+
+	for _, teacherID := range c.TeacherIDs {
+		if teacher, ok := db.users[teacherID]; !ok || teacher.Role != models.RoleFaculty {
+			return models.ErrInvalidTeacher
+		}
+	}
+	for _, studentID := range c.StudentIDs {
+		if _, ok := db.students[studentID]; !ok {
+			return fmt.Errorf("failed to enroll student in class: student %w", models.ErrNotFound)
+		}
+	}
+	return nil
+}
+
+// Delete removes a class. Its roster and assignments are removed with it.
+func (r *ClassRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("class ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.classes[id]; !ok {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+
+	r.db.deleteClass(ctx, id)
+	return nil
+}
+
+// GetByID retrieves a class and its roster by ID
+func (r *ClassRepository) GetByID(ctx context.Context, id string) (*models.Class, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	class, ok := r.db.classes[id]
+	if !ok {
+		return nil, fmt.Errorf("class %w", models.ErrNotFound)
+	}
+
+	class = copyClass(class)
+	return &class, nil
+}
+
+// List retrieves all classes without their rosters
+func (r *ClassRepository) List(ctx context.Context) ([]models.Class, error) {
+	return r.listClasses(func(models.Class) bool { return true }), nil
+}
+
+// ListByTeacherID retrieves the classes a faculty member teaches, without
+// their rosters
+func (r *ClassRepository) ListByTeacherID(ctx context.Context, teacherID string) ([]models.Class, error) {
+	return r.listClasses(func(class models.Class) bool {
+		return contains(class.TeacherIDs, teacherID)
+	}), nil
+}
+
+// listClasses returns the classes matching the filter ordered by term and
+// name, without their rosters
+func (r *ClassRepository) listClasses(match func(models.Class) bool) []models.Class {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var classes []models.Class
+	for _, class := range r.db.classes {
+		if match(class) {
+			class.TeacherIDs = nil
+			class.StudentIDs = nil
+			classes = append(classes, class)
+		}
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if classes[i].Term != classes[j].Term {
+			return classes[i].Term < classes[j].Term
+		}
+		return classes[i].Name < classes[j].Name
+	})
+	return classes
+}
+
+// GetIDsByTeacherID returns the IDs of the classes a faculty member teaches
+func (r *ClassRepository) GetIDsByTeacherID(ctx context.Context, teacherID string) ([]string, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var ids []string
+	for id, class := range r.db.classes {
+		if contains(class.TeacherIDs, teacherID) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetStudentIDsByTeacherID returns the students enrolled in any class the
+// faculty member teaches
+func (r *ClassRepository) GetStudentIDsByTeacherID(ctx context.Context, teacherID string) ([]string, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var ids []string
+	for _, class := range r.db.classes {
+		if contains(class.TeacherIDs, teacherID) {
+			ids = append(ids, class.StudentIDs...)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return models.UniqueStrings(ids), nil
+}
+
+// IsStudentEnrolled reports whether the student is enrolled in the class
+func (r *ClassRepository) IsStudentEnrolled(ctx context.Context, classID, studentID string) (bool, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return contains(r.db.classes[classID].StudentIDs, studentID), nil
+}
+
+// copyClass returns a copy of the class that does not share its rosters
+func copyClass(class models.Class) models.Class {
+	class.TeacherIDs = clone(class.TeacherIDs)
+	class.StudentIDs = clone(class.StudentIDs)
+	return class
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// ForumRepository keeps forum posts and comments in memory
+type ForumRepository struct {
+	db *database
+}
+
+// CreatePost stores a new forum post
+func (r *ForumRepository) CreatePost(ctx context.Context, fp *models.ForumPost) error {
+	if err := fp.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.students[fp.StudentID]; !ok {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+
+	if fp.ID == "" {
+		fp.ID = uuid.New().String()
+	}
+	now := time.Now()
+	fp.CreatedAt = now
+	fp.UpdatedAt = now
+
+	r.db.posts[fp.ID] = *fp
+	return nil
+}
+
+// UpdatePost updates the title and content of a post written by fp.AuthorID
+func (r *ForumRepository) UpdatePost(ctx context.Context, fp *models.ForumPost) error {
+	if fp.ID == "" {
+		return errors.New("forum post ID is required")
+	}
+	if err := fp.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	post, ok := r.db.posts[fp.ID]
+	if !ok || post.AuthorID != fp.AuthorID {
+		return errors.New("forum post not found or you are not authorized to update it")
+	}
+
+	fp.UpdatedAt = time.Now()
+	post.Title = fp.Title
+	post.Content = fp.Content
+	post.UpdatedAt = fp.UpdatedAt
+	r.db.posts[fp.ID] = post
+	return nil
+}
+
+// DeletePost removes a forum post and its comments. A non-empty authorID
+// restricts the deletion to posts written by that user.
+func (r *ForumRepository) DeletePost(ctx context.Context, id, authorID string) error {
+	if id == "" {
+		return errors.New("forum post ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	post, ok := r.db.posts[id]
+	if !ok || (authorID != "" && post.AuthorID != authorID) {
+		return errors.New("forum post not found or you are not authorized to delete it")
+	}
+
+	r.db.deletePost(id)
+	return nil
+}
+
+// GetPostByID retrieves a forum post by its ID
+func (r *ForumRepository) GetPostByID(ctx context.Context, id string) (*models.ForumPost, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	post, ok := r.db.posts[id]
+	if !ok {
+		return nil, fmt.Errorf("forum post %w", models.ErrNotFound)
+	}
+	return &post, nil
+}
+
+// ListPostsByStudentID retrieves a page of the forum posts related to a
+// student
+func (r *ForumRepository) ListPostsByStudentID(ctx context.Context, studentID string, opts models.ListOptions) (models.Page[models.ForumPost], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var posts []models.ForumPost
+	for _, post := range r.db.posts {
+		if post.StudentID == studentID {
+			posts = append(posts, post)
+		}
+	}
+	return paginate(posts, models.ForumPostListSpec, opts), nil
+}
+
+// CreateComment stores a new comment on a forum post
+func (r *ForumRepository) CreateComment(ctx context.Context, fc *models.ForumComment) error {
+	if err := fc.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.posts[fc.PostID]; !ok {
+		return fmt.Errorf("forum post %w", models.ErrNotFound)
+	}
+
+	if fc.ID == "" {
+		fc.ID = uuid.New().String()
+	}
+	now := time.Now()
+	fc.CreatedAt = now
+	fc.UpdatedAt = now
+
+	r.db.comments[fc.ID] = *fc
+	return nil
+}
+
+// UpdateComment updates the content of a comment written by fc.AuthorID
+func (r *ForumRepository) UpdateComment(ctx context.Context, fc *models.ForumComment) error {
+	if fc.ID == "" || fc.Content == "" || fc.AuthorID == "" {
+		return errors.New("invalid forum comment data")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	comment, ok := r.db.comments[fc.ID]
+	if !ok || comment.AuthorID != fc.AuthorID {
+		return errors.New("forum comment not found or you are not authorized to update it")
+	}
+
+	fc.UpdatedAt = time.Now()
+	comment.Content = fc.Content
+	comment.UpdatedAt = fc.UpdatedAt
+	r.db.comments[fc.ID] = comment
+	return nil
+}
+
+// ListCommentsByPostID retrieves the comments on a forum post, oldest first
+func (r *ForumRepository) ListCommentsByPostID(ctx context.Context, postID string) ([]models.ForumComment, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var comments []models.ForumComment
+	for _, comment := range r.db.comments {
+		if comment.PostID == postID {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+	return comments, nil
+}
@@ -0,0 +1,316 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// GradeRepository keeps assignments and grades in memory
+type GradeRepository struct {
+	db *database
+}
+
+// CreateAssignment stores a new assignment unless it is due in a finalized
+// term
+func (r *GradeRepository) CreateAssignment(ctx context.Context, a *models.Assignment) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if a.ClassID == "" {
+		return models.ErrAssignmentClassRequired
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.classes[a.ClassID]; !ok {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+	if _, ok := r.db.categories[a.CategoryID]; a.CategoryID != "" && !ok {
+		return fmt.Errorf("category %w", models.ErrNotFound)
+	}
+	if term := r.db.termAt(a.DueDate); term != nil && term.Finalized() {
+		return models.ErrTermFinalized
+	}
+
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	r.db.assignments[a.ID] = *a
+	return nil
+}
+
+// UpdateAssignment updates an existing assignment
+func (r *GradeRepository) UpdateAssignment(ctx context.Context, a *models.Assignment) error {
+	if a.ID == "" {
+		return errors.New("assignment ID is required")
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.assignments[a.ID]
+	if !ok {
+		return fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+	if _, ok := r.db.classes[a.ClassID]; a.ClassID != "" && !ok {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+	if _, ok := r.db.categories[a.CategoryID]; a.CategoryID != "" && !ok {
+		return fmt.Errorf("category %w", models.ErrNotFound)
+	}
+	if err := r.db.checkTermOpen(a.ID); err != nil {
+		return err
+	}
+	if term := r.db.termAt(a.DueDate); term != nil && term.Finalized() {
+		return models.ErrTermFinalized
+	}
+
+	a.CreatedAt = existing.CreatedAt
+	a.UpdatedAt = time.Now()
+
+	r.db.assignments[a.ID] = *a
+	return nil
+}
+
+// DeleteAssignment removes an assignment together with its grades
+func (r *GradeRepository) DeleteAssignment(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("assignment ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.assignments[id]; !ok {
+		return fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+	if err := r.db.checkTermOpen(id); err != nil {
+		return err
+	}
+
+	r.db.deleteAssignment(ctx, id)
+	return nil
+}
+
+// GetAssignmentByID retrieves an assignment by its ID
+func (r *GradeRepository) GetAssignmentByID(ctx context.Context, id string) (*models.Assignment, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	assignment, ok := r.db.assignments[id]
+	if !ok {
+		return nil, fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+	return &assignment, nil
+}
+
+// ListAssignments retrieves a page of assignments
+func (r *GradeRepository) ListAssignments(ctx context.Context, opts models.ListOptions) (models.Page[models.Assignment], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var assignments []models.Assignment
+	for _, assignment := range r.db.assignments {
+		assignments = append(assignments, assignment)
+	}
+	return paginate(assignments, models.AssignmentListSpec, opts), nil
+}
+
+// CreateGrade stores a new grade. A student can only have one grade per
+// assignment.
+func (r *GradeRepository) CreateGrade(ctx context.Context, g *models.Grade) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.checkGrade(g); err != nil {
+		return err
+	}
+	if err := r.db.checkTermOpen(g.AssignmentID); err != nil {
+		return err
+	}
+	r.db.submittedStatus(g)
+
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	now := time.Now()
+	g.CreatedAt = now
+	g.UpdatedAt = now
+
+	r.db.grades[g.ID] = *g
+	r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityGrade, g.ID, nil, *g)
+	return nil
+}
+
+// UpdateGrade updates an existing grade
+func (r *GradeRepository) UpdateGrade(ctx context.Context, g *models.Grade) error {
+	if g.ID == "" {
+		return errors.New("grade ID is required")
+	}
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.grades[g.ID]
+	if !ok {
+		return fmt.Errorf("grade %w", models.ErrNotFound)
+	}
+	if err := r.db.checkGrade(g); err != nil {
+		return err
+	}
+	if err := r.db.checkTermOpen(existing.AssignmentID, g.AssignmentID); err != nil {
+		return err
+	}
+	r.db.submittedStatus(g)
+
+	g.CreatedAt = existing.CreatedAt
+	g.UpdatedAt = time.Now()
+
+	r.db.grades[g.ID] = *g
+	r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityGrade, g.ID, existing, *g)
+	return nil
+}
+
+// checkGrade checks that the student and assignment exist and that no other
+// grade was given for the same pair. The caller must hold the lock.
+func (db *database) checkGrade(g *models.Grade) error {
+	if _, ok := db.students[g.StudentID]; !ok {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+	if _, ok := db.assignments[g.AssignmentID]; !ok {
+		return fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+	for id, grade := range db.grades {
+		if id != g.ID && grade.StudentID == g.StudentID && grade.AssignmentID == g.AssignmentID {
+			return errors.New("student already has a grade for this assignment")
+		}
+	}
+	return nil
+}
+
+// checkTermOpen fails with models.ErrTermFinalized when one of the
+// assignments is due in a finalized term. The caller must hold the lock.
+func (db *database) checkTermOpen(assignmentIDs ...string) error {
+	for _, id := range assignmentIDs {
+		assignment, ok := db.assignments[id]
+		if !ok {
+			continue
+		}
+		if term := db.termAt(assignment.DueDate); term != nil && term.Finalized() {
+			return models.ErrTermFinalized
+		}
+	}
+	return nil
+}
+
+// GetGradeByID retrieves a grade by its ID
+func (r *GradeRepository) GetGradeByID(ctx context.Context, id string) (*models.Grade, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	grade, ok := r.db.grades[id]
+	if !ok {
+		return nil, fmt.Errorf("grade %w", models.ErrNotFound)
+	}
+	return &grade, nil
+}
+
+// ListGradesByStudentID retrieves all grades for a student
+func (r *GradeRepository) ListGradesByStudentID(ctx context.Context, studentID string) ([]models.Grade, error) {
+	return r.listGrades(func(grade models.Grade) bool { return grade.StudentID == studentID }), nil
+}
+
+// ListGradesByAssignmentID retrieves the grades for an assignment, of the
+// given students only when studentIDs is not nil
+func (r *GradeRepository) ListGradesByAssignmentID(ctx context.Context, assignmentID string, studentIDs []string) ([]models.Grade, error) {
+	return r.listGrades(func(grade models.Grade) bool {
+		return grade.AssignmentID == assignmentID && inScope(studentIDs, grade.StudentID)
+	}), nil
+}
+
+// listGrades returns the grades matching the filter in the order they were given
+func (r *GradeRepository) listGrades(match func(models.Grade) bool) []models.Grade {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var grades []models.Grade
+	for _, grade := range r.db.grades {
+		if match(grade) {
+			grades = append(grades, grade)
+		}
+	}
+	sort.Slice(grades, func(i, j int) bool {
+		return grades[i].CreatedAt.Before(grades[j].CreatedAt)
+	})
+	return grades
+}
+
+// MarkMissing gives a missing grade to every active student on the roster
+// of an assignment due in the period who has neither a grade nor a
+// submission for it
+func (r *GradeRepository) MarkMissing(ctx context.Context, dueFrom, dueBefore time.Time) ([]models.Grade, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	handedIn := make(map[[2]string]bool)
+	for _, grade := range r.db.grades {
+		handedIn[[2]string{grade.StudentID, grade.AssignmentID}] = true
+	}
+	for _, submission := range r.db.submissions {
+		handedIn[[2]string{submission.StudentID, submission.AssignmentID}] = true
+	}
+
+	var created []models.Grade
+	for _, assignment := range r.db.assignments {
+		if assignment.ClassID == "" || assignment.DueDate.Before(dueFrom) || !assignment.DueDate.Before(dueBefore) {
+			continue
+		}
+		if r.db.checkTermOpen(assignment.ID) != nil {
+			continue
+		}
+		for _, studentID := range r.db.classes[assignment.ClassID].StudentIDs {
+			student, ok := r.db.students[studentID]
+			if !ok || !student.IsActive || student.ArchivedAt != nil || handedIn[[2]string{studentID, assignment.ID}] {
+				continue
+			}
+
+			now := time.Now()
+			grade := models.Grade{
+				ID:           uuid.New().String(),
+				StudentID:    studentID,
+				AssignmentID: assignment.ID,
+				MaxScore:     assignment.MaxScore,
+				Status:       models.AssignmentStatusMissing,
+				Feedback:     models.MissingFeedback,
+				GradedBy:     assignment.CreatedBy,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			r.db.grades[grade.ID] = grade
+			r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityGrade, grade.ID, nil, grade)
+			created = append(created, grade)
+		}
+	}
+	return created, nil
+}
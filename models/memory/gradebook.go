@@ -0,0 +1,272 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// GradebookRepository keeps grading categories, letter-grade scales and
+// class settings in memory
+type GradebookRepository struct {
+	db *database
+}
+
+// CreateCategory stores a new grading category
+func (r *GradebookRepository) CreateCategory(ctx context.Context, c *models.GradingCategory) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.classes[c.ClassID]; c.ClassID != "" && !ok {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	r.db.categories[c.ID] = *c
+	return nil
+}
+
+// UpdateCategory updates the name and weight of a category, keeping the
+// class or subject it belongs to
+func (r *GradebookRepository) UpdateCategory(ctx context.Context, c *models.GradingCategory) error {
+	if c.ID == "" {
+		return errors.New("category ID is required")
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.categories[c.ID]
+	if !ok {
+		return fmt.Errorf("category %w", models.ErrNotFound)
+	}
+
+	c.ClassID = existing.ClassID
+	c.Subject = existing.Subject
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = time.Now()
+
+	r.db.categories[c.ID] = *c
+	return nil
+}
+
+// DeleteCategory removes a category and leaves its assignments uncategorized
+func (r *GradebookRepository) DeleteCategory(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("category ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.categories[id]; !ok {
+		return fmt.Errorf("category %w", models.ErrNotFound)
+	}
+
+	r.db.deleteCategory(id)
+	return nil
+}
+
+// GetCategoryByID retrieves a grading category by its ID
+func (r *GradebookRepository) GetCategoryByID(ctx context.Context, id string) (*models.GradingCategory, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	category, ok := r.db.categories[id]
+	if !ok {
+		return nil, fmt.Errorf("category %w", models.ErrNotFound)
+	}
+	return &category, nil
+}
+
+// ListCategories returns the categories of a class and of a subject
+func (r *GradebookRepository) ListCategories(ctx context.Context, classID, subject string) ([]models.GradingCategory, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var categories []models.GradingCategory
+	for _, category := range r.db.categories {
+		if (classID != "" && category.ClassID == classID) || (subject != "" && category.Subject == subject) {
+			categories = append(categories, category)
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// CreateScale stores a new letter-grade scale. Scale names are unique.
+func (r *GradebookRepository) CreateScale(ctx context.Context, s *models.GradeScale) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.db.scaleNameTaken(s.Name, "") {
+		return fmt.Errorf("scale %s already exists", s.Name)
+	}
+
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	r.db.scales[s.ID] = copyScale(*s)
+	return nil
+}
+
+// UpdateScale replaces the name and steps of a scale
+func (r *GradebookRepository) UpdateScale(ctx context.Context, s *models.GradeScale) error {
+	if s.ID == "" {
+		return errors.New("scale ID is required")
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.scales[s.ID]
+	if !ok {
+		return fmt.Errorf("scale %w", models.ErrNotFound)
+	}
+	if r.db.scaleNameTaken(s.Name, s.ID) {
+		return fmt.Errorf("scale %s already exists", s.Name)
+	}
+
+	s.CreatedAt = existing.CreatedAt
+	s.UpdatedAt = time.Now()
+
+	r.db.scales[s.ID] = copyScale(*s)
+	return nil
+}
+
+// DeleteScale removes a scale; classes using it fall back to the default
+func (r *GradebookRepository) DeleteScale(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("scale ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.scales[id]; !ok {
+		return fmt.Errorf("scale %w", models.ErrNotFound)
+	}
+
+	delete(r.db.scales, id)
+	for classID, settings := range r.db.settings {
+		if settings.GradeScaleID == id {
+			settings.GradeScaleID = ""
+			r.db.settings[classID] = settings
+		}
+	}
+	return nil
+}
+
+// GetScaleByID retrieves a letter-grade scale by its ID
+func (r *GradebookRepository) GetScaleByID(ctx context.Context, id string) (*models.GradeScale, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	scale, ok := r.db.scales[id]
+	if !ok {
+		return nil, fmt.Errorf("scale %w", models.ErrNotFound)
+	}
+	scale = copyScale(scale)
+	return &scale, nil
+}
+
+// ListScales returns every letter-grade scale ordered by name
+func (r *GradebookRepository) ListScales(ctx context.Context) ([]models.GradeScale, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var scales []models.GradeScale
+	for _, scale := range r.db.scales {
+		scales = append(scales, copyScale(scale))
+	}
+	sort.Slice(scales, func(i, j int) bool { return scales[i].Name < scales[j].Name })
+	return scales, nil
+}
+
+// GetSettings returns the settings of a class, or the defaults when the
+// class has not saved any
+func (r *GradebookRepository) GetSettings(ctx context.Context, classID string) (*models.GradebookSettings, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	settings, ok := r.db.settings[classID]
+	if !ok {
+		return models.DefaultGradebookSettings(classID), nil
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the settings of a class
+func (r *GradebookRepository) SaveSettings(ctx context.Context, s *models.GradebookSettings) error {
+	if s.ClassID == "" {
+		return errors.New("class ID is required")
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.classes[s.ClassID]; !ok {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+	if _, ok := r.db.scales[s.GradeScaleID]; s.GradeScaleID != "" && !ok {
+		return fmt.Errorf("scale %w", models.ErrNotFound)
+	}
+
+	s.UpdatedAt = time.Now()
+	r.db.settings[s.ClassID] = *s
+	return nil
+}
+
+// scaleNameTaken reports whether a scale other than exceptID has the name.
+// The caller must hold the lock.
+func (db *database) scaleNameTaken(name, exceptID string) bool {
+	for id, scale := range db.scales {
+		if id != exceptID && scale.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// copyScale returns a scale that shares no steps with the given one
+func copyScale(scale models.GradeScale) models.GradeScale {
+	scale.Steps = append([]models.GradeStep(nil), scale.Steps...)
+	return scale
+}
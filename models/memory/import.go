@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// ImportRepository writes import batches into the in-memory store
+type ImportRepository struct {
+	db *database
+}
+
+// Import creates the students, users and parent links of the batch. Rows
+// are written as they are checked and undone again when any row failed or
+// this is a dry run, like the rolled back transaction of the Postgres store.
+func (r *ImportRepository) Import(ctx context.Context, batch *models.ImportBatch, dryRun bool) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	auditLength := len(r.db.audit)
+	var studentIDs, userIDs []string
+	linked := make(map[string][]string) // parent ID to its students before the import
+
+	var rowErrors models.ImportErrors
+	fail := func(file string, line int, err error) {
+		rowErrors = append(rowErrors, models.ImportError{File: file, Line: line, Message: err.Error()})
+	}
+
+	for i := range batch.Students {
+		s := &batch.Students[i].Student
+		if err := s.Validate(); err != nil {
+			fail(models.ImportFileStudents, batch.Students[i].Line, err)
+			continue
+		}
+		if err := r.db.insertStudent(s); err != nil {
+			fail(models.ImportFileStudents, batch.Students[i].Line, err)
+			continue
+		}
+		studentIDs = append(studentIDs, s.ID)
+		r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityStudent, s.ID, nil, *s)
+	}
+
+	for i := range batch.Users {
+		u := &batch.Users[i].User
+		if err := r.db.insertUser(u); err != nil {
+			fail(models.ImportFileUsers, batch.Users[i].Line, err)
+			continue
+		}
+		userIDs = append(userIDs, u.ID)
+		r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityUser, u.ID, nil, r.db.users[u.ID])
+	}
+
+	for _, link := range batch.ParentLinks {
+		parent, student, err := r.db.resolveParentLink(link)
+		if err != nil {
+			fail(models.ImportFileParentLinks, link.Line, err)
+			continue
+		}
+		if _, saved := linked[parent.ID]; !saved {
+			linked[parent.ID] = parent.StudentIDs
+		}
+		if !contains(parent.StudentIDs, student.ID) {
+			parent.StudentIDs = append(clone(parent.StudentIDs), student.ID)
+			r.db.users[parent.ID] = parent
+		}
+	}
+
+	if len(rowErrors) > 0 || dryRun {
+		for _, id := range studentIDs {
+			delete(r.db.students, id)
+		}
+		for _, id := range userIDs {
+			delete(r.db.users, id)
+		}
+		for id, studentIDs := range linked {
+			if parent, ok := r.db.users[id]; ok {
+				parent.StudentIDs = studentIDs
+				r.db.users[id] = parent
+			}
+		}
+		r.db.audit = r.db.audit[:auditLength]
+	}
+
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+	return nil
+}
+
+// resolveParentLink finds the parent and the active student of a link. The
+// caller must hold the lock.
+func (db *database) resolveParentLink(link models.ImportParentLink) (models.User, models.Student, error) {
+	var parent *models.User
+	for _, user := range db.users {
+		if user.Username == link.ParentUsername {
+			parent = &user
+			break
+		}
+	}
+	if parent == nil {
+		return models.User{}, models.Student{}, fmt.Errorf("parent %w", models.ErrNotFound)
+	}
+	if parent.Role != models.RoleParent {
+		return models.User{}, models.Student{}, fmt.Errorf("user %s is not a parent", link.ParentUsername)
+	}
+
+	for _, student := range db.students {
+		if student.Email == link.StudentEmail && student.ArchivedAt == nil {
+			return *parent, student, nil
+		}
+	}
+	return models.User{}, models.Student{}, fmt.Errorf("student %w", models.ErrNotFound)
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// invitation is a stored invitation with the hash of its code
+type invitation struct {
+	models.Invitation
+	codeHash string
+}
+
+// InvitationRepository keeps invitations in memory
+type InvitationRepository struct {
+	db *database
+}
+
+// Create stores a new invitation and returns its code. Only the hash of the
+// code is kept.
+func (r *InvitationRepository) Create(ctx context.Context, i *models.Invitation) (string, error) {
+	if err := i.Validate(); err != nil {
+		return "", err
+	}
+
+	code, hash, err := models.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	i.CreatedAt = time.Now()
+
+	stored := *i
+	stored.StudentIDs = models.UniqueStrings(i.StudentIDs)
+	r.db.invitations[i.ID] = invitation{Invitation: stored, codeHash: hash}
+
+	return code, nil
+}
+
+// Revoke marks an unredeemed invitation as revoked
+func (r *InvitationRepository) Revoke(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("invitation ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i, ok := r.db.invitations[id]
+	if !ok || i.RedeemedAt != nil || i.RevokedAt != nil {
+		return errors.New("invitation not found or already used")
+	}
+
+	now := time.Now()
+	i.RevokedAt = &now
+	r.db.invitations[id] = i
+	return nil
+}
+
+// GetByID retrieves an invitation by its ID
+func (r *InvitationRepository) GetByID(ctx context.Context, id string) (*models.Invitation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	i, ok := r.db.invitations[id]
+	if !ok {
+		return nil, fmt.Errorf("invitation %w", models.ErrNotFound)
+	}
+
+	result := i.Invitation
+	result.StudentIDs = clone(i.StudentIDs)
+	return &result, nil
+}
+
+// List retrieves all invitations, newest first. Like the database listing,
+// student links are left out.
+func (r *InvitationRepository) List(ctx context.Context) ([]models.Invitation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var invitations []models.Invitation
+	for _, i := range r.db.invitations {
+		result := i.Invitation
+		result.StudentIDs = nil
+		invitations = append(invitations, result)
+	}
+	sort.Slice(invitations, func(a, b int) bool {
+		return invitations[a].CreatedAt.After(invitations[b].CreatedAt)
+	})
+	return invitations, nil
+}
+
+// Redeem creates the user described by the invitation code and consumes the
+// invitation under the same lock
+func (r *InvitationRepository) Redeem(ctx context.Context, code string, user *models.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	hash := models.HashSecret(code)
+	var found *invitation
+	for id := range r.db.invitations {
+		if i := r.db.invitations[id]; i.codeHash == hash {
+			found = &i
+			break
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("invitation %w", models.ErrNotFound)
+	}
+	if !found.Redeemable(time.Now()) {
+		return models.ErrInvitationInvalid
+	}
+
+	user.Email = found.Email
+	user.Role = found.Role
+	user.StudentIDs = clone(found.StudentIDs)
+
+	if err := r.db.insertUser(user); err != nil {
+		return err
+	}
+	r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityUser, user.ID, nil, r.db.users[user.ID])
+
+	now := time.Now()
+	redeemedBy := user.ID
+	found.RedeemedAt = &now
+	found.RedeemedBy = &redeemedBy
+	r.db.invitations[found.ID] = *found
+	return nil
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// JobRepository keeps the last run of each background job in memory
+type JobRepository struct {
+	db *database
+}
+
+// SaveRun records a run, replacing the previous run of the same job
+func (r *JobRepository) SaveRun(ctx context.Context, run *models.JobRun) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.jobRuns[run.Name] = *run
+	return nil
+}
+
+// GetRun returns the last run of a job
+func (r *JobRepository) GetRun(ctx context.Context, name string) (*models.JobRun, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	run, ok := r.db.jobRuns[name]
+	if !ok {
+		return nil, fmt.Errorf("job run %w", models.ErrNotFound)
+	}
+	return &run, nil
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// paginate applies the conditions, sort order and cursor of opts to records
+// the way the Postgres listing queries do
+func paginate[T models.Listable](records []T, spec models.ListSpec, opts models.ListOptions) models.Page[T] {
+	opts = opts.WithDefaultSort(spec)
+
+	var matched []T
+	for _, record := range records {
+		if matches(record, opts) {
+			matched = append(matched, record)
+		}
+	}
+	total := len(matched)
+
+	// before reports whether a record at (value, id) sorts before b
+	before := func(value interface{}, id string, b T) bool {
+		c := compareValues(value, b.ListValue(opts.Sort))
+		if c == 0 {
+			c = strings.Compare(id, b.ListValue("id").(string))
+		}
+		if opts.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return before(matched[i].ListValue(opts.Sort), matched[i].ListValue("id").(string), matched[j])
+	})
+
+	if opts.After != nil {
+		start := sort.Search(len(matched), func(i int) bool {
+			return before(opts.After.Value, opts.After.ID, matched[i])
+		})
+		matched = matched[start:]
+	}
+	if opts.Limit > 0 && len(matched) > opts.Limit+1 {
+		matched = matched[:opts.Limit+1]
+	}
+
+	return models.NewPage(matched, opts, total)
+}
+
+// matches reports whether a record satisfies the conditions and IDs of opts
+func matches(record models.Listable, opts models.ListOptions) bool {
+	if opts.IDs != nil && !contains(opts.IDs, record.ListValue("id").(string)) {
+		return false
+	}
+
+	for _, cond := range opts.Conditions {
+		value := record.ListValue(cond.Field)
+		c := compareValues(value, cond.Value)
+		var ok bool
+		switch cond.Op {
+		case models.OpContains:
+			ok = strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(cond.Value)))
+		case models.OpLt:
+			ok = c < 0
+		case models.OpLte:
+			ok = c <= 0
+		case models.OpGt:
+			ok = c > 0
+		case models.OpGte:
+			ok = c >= 0
+		default:
+			ok = c == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues compares two field values of the same kind
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		b := b.(int)
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// NotificationRepository keeps inboxes and notification preferences in
+// memory
+type NotificationRepository struct {
+	db *database
+}
+
+// Create stores a notification unless the user already has one of the same
+// kind for the same entity
+func (r *NotificationRepository) Create(ctx context.Context, n *models.Notification) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.notifications {
+		if existing.UserID == n.UserID && existing.Kind == n.Kind && existing.EntityID == n.EntityID {
+			return false, nil
+		}
+	}
+
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	n.CreatedAt = time.Now()
+	r.db.notifications[n.ID] = *n
+	return true, nil
+}
+
+// List returns a page of the notifications of a user
+func (r *NotificationRepository) List(ctx context.Context, userID string, opts models.ListOptions) (models.Page[models.Notification], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var notifications []models.Notification
+	for _, notification := range r.db.notifications {
+		if notification.UserID == userID {
+			notifications = append(notifications, notification)
+		}
+	}
+	return paginate(notifications, models.NotificationListSpec, opts), nil
+}
+
+// MarkRead marks a notification of the user as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	notification, ok := r.db.notifications[id]
+	if !ok || notification.UserID != userID {
+		return fmt.Errorf("notification %w", models.ErrNotFound)
+	}
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		r.db.notifications[id] = notification
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	var count int64
+	for id, notification := range r.db.notifications {
+		if notification.UserID == userID && notification.ReadAt == nil {
+			notification.ReadAt = &now
+			r.db.notifications[id] = notification
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetPreferences returns the preferences of a user, or the defaults
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	preferences, ok := r.db.notifyPrefs[userID]
+	if !ok {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	preferences.Email = append([]models.NotificationKind{}, preferences.Email...)
+	preferences.Webhook = append([]models.NotificationKind{}, preferences.Webhook...)
+	return &preferences, nil
+}
+
+// SavePreferences replaces the preferences of a user
+func (r *NotificationRepository) SavePreferences(ctx context.Context, p *models.NotificationPreferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.users[p.UserID]; !ok {
+		return fmt.Errorf("user %w", models.ErrNotFound)
+	}
+	p.UpdatedAt = time.Now()
+	stored := *p
+	stored.Email = append([]models.NotificationKind{}, p.Email...)
+	stored.Webhook = append([]models.NotificationKind{}, p.Webhook...)
+	r.db.notifyPrefs[p.UserID] = stored
+	return nil
+}
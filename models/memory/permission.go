@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// defaultPermissions mirrors the permission catalog seeded by the migrations
+var defaultPermissions = []models.Permission{
+	{Name: "students:read", Description: "View student records"},
+	{Name: "students:write", Description: "Create and update students"},
+	{Name: "students:delete", Description: "Archive students"},
+	{Name: "users:read", Description: "View user accounts"},
+	{Name: "users:write", Description: "Update user accounts"},
+	{Name: "users:delete", Description: "Delete user accounts"},
+	{Name: "users:roles", Description: "Change user roles and parent-student links"},
+	{Name: "users:sessions", Description: "Revoke the sessions of other users"},
+	{Name: "invitations:manage", Description: "Create, list and revoke invitations"},
+	{Name: "attendance:read", Description: "View attendance records"},
+	{Name: "attendance:write", Description: "Record and update attendance"},
+	{Name: "assignments:read", Description: "View assignments"},
+	{Name: "assignments:write", Description: "Create, update and delete assignments"},
+	{Name: "grades:read", Description: "View grades"},
+	{Name: "grades:write", Description: "Create and update grades and list grades by assignment"},
+	{Name: "forum:read", Description: "View forum posts and comments"},
+	{Name: "forum:write", Description: "Create and edit own forum posts and comments"},
+	{Name: "forum:moderate", Description: "Delete forum posts written by others"},
+	{Name: "reports:read", Description: "Generate school-wide and student reports"},
+	{Name: "reports:children", Description: "Generate reports for linked students"},
+	{Name: "permissions:manage", Description: "View and edit role permissions"},
+	{Name: "classes:read", Description: "View classes and their rosters"},
+	{Name: "classes:manage", Description: "Create, update and delete classes and assign teachers and students"},
+	{Name: "students:restore", Description: "List and restore archived students"},
+	{Name: "students:purge", Description: "Permanently delete archived students after the retention period"},
+	{Name: "audit:read", Description: "View the change history of students, users, grades and attendance"},
+	{Name: "data:import", Description: "Import students, users and parent links from CSV files"},
+	{Name: "gradebook:manage", Description: "Manage the grading categories and gradebook settings of classes taught"},
+	{Name: "gradebook:configure", Description: "Manage letter-grade scales and subject-wide grading categories"},
+	{Name: "terms:read", Description: "View academic years and terms"},
+	{Name: "terms:manage", Description: "Create, update and delete academic years and terms"},
+	{Name: "terms:finalize", Description: "Finalize terms, locking their grades and taking report cards"},
+	{Name: "report-cards:read", Description: "View and download the report cards of finalized terms"},
+	{Name: "promotions:manage", Description: "Preview and apply year-end grade-level promotions"},
+	{Name: "submissions:read", Description: "View submitted work and download its files"},
+	{Name: "submissions:write", Description: "Hand in work for assignments on behalf of linked students"},
+	{Name: "jobs:manage", Description: "View the status of background jobs and run them on demand"},
+	{Name: "notifications:read", Description: "View own notifications and choose how they are delivered"},
+}
+
+// defaultRolePermissions mirrors the grants seeded by the migrations
+var defaultRolePermissions = map[models.UserRole][]string{
+	models.RoleFaculty: {
+		"students:read", "students:write", "students:delete",
+		"users:read", "users:write",
+		"attendance:read", "attendance:write",
+		"assignments:read", "assignments:write",
+		"grades:read", "grades:write",
+		"forum:read", "forum:write", "forum:moderate",
+		"reports:read",
+		"classes:read",
+		"gradebook:manage",
+		"terms:read", "report-cards:read",
+		"submissions:read",
+	},
+	models.RoleStaff: {
+		"students:read", "students:write", "students:delete",
+		"users:read", "users:write", "users:delete", "users:roles", "users:sessions",
+		"invitations:manage",
+		"attendance:read", "attendance:write",
+		"assignments:read",
+		"grades:read",
+		"forum:read", "forum:write", "forum:moderate",
+		"reports:read",
+		"permissions:manage",
+		"classes:read", "classes:manage",
+		"students:restore", "students:purge",
+		"audit:read",
+		"data:import",
+		"gradebook:manage", "gradebook:configure",
+		"terms:read", "terms:manage", "terms:finalize", "report-cards:read",
+		"promotions:manage",
+		"submissions:read",
+		"jobs:manage",
+	},
+	models.RoleParent: {
+		"students:read",
+		"assignments:read",
+		"grades:read",
+		"forum:read", "forum:write",
+		"reports:children",
+		"terms:read", "report-cards:read",
+		"submissions:read", "submissions:write",
+		"notifications:read",
+	},
+}
+
+// PermissionRepository keeps permissions and role grants in memory
+type PermissionRepository struct {
+	db *database
+}
+
+// List retrieves every known permission ordered by name
+func (r *PermissionRepository) List(ctx context.Context) ([]models.Permission, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var permissions []models.Permission
+	for _, permission := range r.db.permissions {
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].Name < permissions[j].Name })
+	return permissions, nil
+}
+
+// GetRolePermissions retrieves the permissions granted to each role
+func (r *PermissionRepository) GetRolePermissions(ctx context.Context) (map[models.UserRole][]string, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	grants := make(map[models.UserRole][]string, len(r.db.rolePermissions))
+	for role, permissions := range r.db.rolePermissions {
+		if len(permissions) == 0 {
+			continue
+		}
+		grants[role] = clone(permissions)
+		sort.Strings(grants[role])
+	}
+	return grants, nil
+}
+
+// SetRolePermissions replaces the permissions granted to a role
+func (r *PermissionRepository) SetRolePermissions(ctx context.Context, role models.UserRole, permissions []string) error {
+	if !role.IsValid() {
+		return fmt.Errorf("invalid user role: %s", role)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, permission := range permissions {
+		if _, ok := r.db.permissions[permission]; !ok {
+			return models.ErrUnknownPermission
+		}
+	}
+
+	r.db.rolePermissions[role] = models.UniqueStrings(permissions)
+	return nil
+}
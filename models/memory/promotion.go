@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// PromotionRepository applies promotions to the students in memory and
+// keeps their grade-level history
+type PromotionRepository struct {
+	db *database
+}
+
+// Apply checks every change before writing any, so that a conflict leaves
+// the students untouched
+func (r *PromotionRepository) Apply(ctx context.Context, changes []models.GradeChange, recordedBy string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, change := range changes {
+		if change.Action == models.PromotionSkip {
+			continue
+		}
+		student, ok := r.db.students[change.StudentID]
+		if !ok || student.ArchivedAt != nil || !student.IsActive || student.Grade != change.FromGrade {
+			return fmt.Errorf("student %s: %w", change.StudentID, models.ErrPromotionConflict)
+		}
+		for _, existing := range r.db.gradeHistory {
+			if existing.StudentID == change.StudentID && existing.EffectiveDate.Equal(models.Day(change.EffectiveDate)) {
+				return fmt.Errorf("student %s: %w", change.StudentID, models.ErrPromotionConflict)
+			}
+		}
+	}
+
+	now := time.Now()
+	for i := range changes {
+		change := &changes[i]
+		if change.Action == models.PromotionSkip {
+			continue
+		}
+		if change.ID == "" {
+			change.ID = uuid.New().String()
+		}
+		change.RecordedBy = recordedBy
+		change.CreatedAt = now
+		change.EffectiveDate = models.Day(change.EffectiveDate)
+
+		stored := *change
+		stored.StudentName = ""
+		stored.Reason = ""
+		r.db.gradeHistory[change.ID] = stored
+
+		if change.Action == models.PromotionHold {
+			continue
+		}
+		before := r.db.students[change.StudentID]
+		after := before
+		after.Grade = change.ToGrade
+		if change.Action == models.PromotionGraduate {
+			after.IsActive = false
+		}
+		r.db.students[change.StudentID] = after
+		r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityStudent, change.StudentID, before, after)
+	}
+	return nil
+}
+
+// ListHistory returns the grade-level history of a student
+func (r *PromotionRepository) ListHistory(ctx context.Context, studentID string) ([]models.GradeChange, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.db.studentGradeHistory(studentID), nil
+}
+
+// studentGradeHistory returns the grade changes of a student ordered by
+// effective date. The caller must hold the lock.
+func (db *database) studentGradeHistory(studentID string) []models.GradeChange {
+	var history []models.GradeChange
+	for _, change := range db.gradeHistory {
+		if change.StudentID == studentID {
+			history = append(history, change)
+		}
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].EffectiveDate.Before(history[j].EffectiveDate) })
+	return history
+}
+
+// gradeBefore returns the grade level a student had on the last day before
+// to, or the current one when to is zero. The caller must hold the lock.
+func (db *database) gradeBefore(student models.Student, to time.Time) string {
+	if to.IsZero() {
+		return student.Grade
+	}
+	return models.GradeAt(db.studentGradeHistory(student.ID), student.Grade, to.AddDate(0, 0, -1))
+}
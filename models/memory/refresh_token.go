@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// RefreshTokenRepository keeps refresh tokens in memory
+type RefreshTokenRepository struct {
+	db *database
+}
+
+// insertRefreshToken creates a token in the given family and returns the
+// token value. The caller must hold the write lock.
+func (db *database) insertRefreshToken(userID, familyID string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	value, hash, err := models.GenerateSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	token := models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	db.refreshTokens[token.ID] = token
+
+	return value, &token, nil
+}
+
+// findRefreshToken returns the token with the hash of the given value.
+// The caller must hold the lock.
+func (db *database) findRefreshToken(value string) (models.RefreshToken, bool) {
+	hash := models.HashSecret(value)
+	for _, token := range db.refreshTokens {
+		if token.TokenHash == hash {
+			return token, true
+		}
+	}
+	return models.RefreshToken{}, false
+}
+
+// revokeRefreshTokens revokes every active token matching the filter and
+// returns how many were revoked. The caller must hold the write lock.
+func (db *database) revokeRefreshTokens(now time.Time, match func(models.RefreshToken) bool) int64 {
+	var revoked int64
+	for id, token := range db.refreshTokens {
+		if token.RevokedAt == nil && match(token) {
+			revokedAt := now
+			token.RevokedAt = &revokedAt
+			db.refreshTokens[id] = token
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// Issue starts a new refresh token family for a user and returns the token value
+func (r *RefreshTokenRepository) Issue(ctx context.Context, userID string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	if userID == "" {
+		return "", nil, errors.New("user ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.insertRefreshToken(userID, uuid.New().String(), ttl)
+}
+
+// Rotate exchanges a valid refresh token for a new one in the same family.
+// Presenting a token that was already rotated revokes the whole family and
+// returns models.ErrRefreshTokenReused.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, value string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	current, ok := r.db.findRefreshToken(value)
+	if !ok {
+		return "", nil, models.ErrRefreshTokenInvalid
+	}
+
+	now := time.Now()
+
+	if current.RevokedAt != nil {
+		if current.ReplacedBy == nil {
+			// Revoked by logout rather than rotation
+			return "", nil, models.ErrRefreshTokenInvalid
+		}
+
+		// A rotated token was presented again, so it has leaked
+		r.db.revokeRefreshTokens(now, func(token models.RefreshToken) bool {
+			return token.FamilyID == current.FamilyID
+		})
+		return "", nil, models.ErrRefreshTokenReused
+	}
+
+	if now.After(current.ExpiresAt) {
+		return "", nil, models.ErrRefreshTokenInvalid
+	}
+
+	newValue, next, err := r.db.insertRefreshToken(current.UserID, current.FamilyID, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	current.RevokedAt = &now
+	current.ReplacedBy = &next.ID
+	r.db.refreshTokens[current.ID] = current
+
+	return newValue, next, nil
+}
+
+// Revoke revokes the session the given refresh token belongs to
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, value string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	current, ok := r.db.findRefreshToken(value)
+	if !ok {
+		return models.ErrRefreshTokenInvalid
+	}
+
+	revoked := r.db.revokeRefreshTokens(time.Now(), func(token models.RefreshToken) bool {
+		return token.FamilyID == current.FamilyID
+	})
+	if revoked == 0 {
+		return models.ErrRefreshTokenInvalid
+	}
+	return nil
+}
+
+// RevokeAll revokes every active refresh token of a user and returns the
+// number of tokens revoked
+func (r *RefreshTokenRepository) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.revokeRefreshTokens(time.Now(), func(token models.RefreshToken) bool {
+		return token.UserID == userID
+	}), nil
+}
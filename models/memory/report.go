@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/reporting"
+)
+
+// ReportRepository computes report data from the records in memory
+type ReportRepository struct {
+	db *database
+}
+
+// AttendanceSummaries summarizes attendance per student
+func (r *ReportRepository) AttendanceSummaries(ctx context.Context, filter reporting.AttendanceFilter) ([]reporting.AttendanceSummary, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var roster map[string]bool
+	if filter.ClassID != "" {
+		roster = make(map[string]bool)
+		for _, id := range r.db.classes[filter.ClassID].StudentIDs {
+			roster[id] = true
+		}
+	}
+
+	records := make(map[string][]models.Attendance)
+	grades := make(map[string]string)
+	for id, student := range r.db.students {
+		grades[id] = r.db.gradeBefore(student, filter.To)
+		if student.ArchivedAt != nil ||
+			(filter.Grade != "" && grades[id] != filter.Grade) ||
+			(roster != nil && !roster[id]) ||
+			(filter.StudentID != "" && id != filter.StudentID) ||
+			!inScope(filter.StudentIDs, id) {
+			continue
+		}
+		records[id] = nil
+	}
+	for _, record := range r.db.attendance {
+		if _, listed := records[record.StudentID]; !listed || record.Date.Before(filter.From) || !record.Date.Before(filter.To) {
+			continue
+		}
+		records[record.StudentID] = append(records[record.StudentID], record)
+	}
+
+	summaries := []reporting.AttendanceSummary{}
+	for id, studentRecords := range records {
+		student := r.db.students[id]
+		if !student.IsActive && len(studentRecords) == 0 {
+			continue
+		}
+		sort.Slice(studentRecords, func(i, j int) bool { return studentRecords[i].Date.Before(studentRecords[j].Date) })
+
+		summary := reporting.AttendanceSummary{StudentID: id, StudentName: student.Name, Grade: grades[id]}
+		streak := 0
+		for _, record := range studentRecords {
+			switch record.Status {
+			case models.AttendanceStatusPresent:
+				summary.Present++
+			case models.AttendanceStatusAbsent:
+				summary.Absent++
+			case models.AttendanceStatusTardy:
+				summary.Tardy++
+			case models.AttendanceStatusExcused:
+				summary.Excused++
+			}
+			summary.Total++
+
+			if record.Status != models.AttendanceStatusAbsent {
+				streak = 0
+				continue
+			}
+			streak++
+			if streak > summary.LongestAbsenceStreak {
+				summary.LongestAbsenceStreak = streak
+			}
+		}
+		summary.CurrentAbsenceStreak = streak
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].StudentName != summaries[j].StudentName {
+			return summaries[i].StudentName < summaries[j].StudentName
+		}
+		return summaries[i].StudentID < summaries[j].StudentID
+	})
+	return summaries, nil
+}
+
+// GradeSummaries summarizes the grades matching the filter of every student
+// that is not archived
+func (r *ReportRepository) GradeSummaries(ctx context.Context, filter reporting.GradeFilter) ([]reporting.GradeSummary, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	byStudent := make(map[string]*reporting.GradeSummary)
+	for id, student := range r.db.students {
+		if student.ArchivedAt == nil && inScope(filter.StudentIDs, id) {
+			byStudent[id] = &reporting.GradeSummary{StudentID: id, StudentName: student.Name, Grade: r.db.gradeBefore(student, filter.To)}
+		}
+	}
+
+	graded := make(map[string]bool)
+	for _, grade := range r.db.grades {
+		summary, exists := byStudent[grade.StudentID]
+		if !exists || !filter.Contains(r.db.assignments[grade.AssignmentID].DueDate) {
+			continue
+		}
+		summary.Assignments++
+		if grade.Status == models.AssignmentStatusMissing {
+			summary.Missing++
+			continue
+		}
+		if !graded[grade.StudentID] || grade.Score > summary.Highest {
+			summary.Highest = grade.Score
+		}
+		if !graded[grade.StudentID] || grade.Score < summary.Lowest {
+			summary.Lowest = grade.Score
+		}
+		graded[grade.StudentID] = true
+		summary.TotalScore += grade.Score
+		summary.TotalPossible += grade.MaxScore
+	}
+
+	summaries := []reporting.GradeSummary{}
+	for _, summary := range byStudent {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].StudentName != summaries[j].StudentName {
+			return summaries[i].StudentName < summaries[j].StudentName
+		}
+		return summaries[i].StudentID < summaries[j].StudentID
+	})
+	return summaries, nil
+}
+
+// StudentGrades returns the grades of a student matching the filter with
+// their assignments
+func (r *ReportRepository) StudentGrades(ctx context.Context, studentID string, filter reporting.GradeFilter) ([]reporting.GradeDetail, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	details := []reporting.GradeDetail{}
+	for _, grade := range r.db.grades {
+		if grade.StudentID != studentID {
+			continue
+		}
+		assignment, exists := r.db.assignments[grade.AssignmentID]
+		if !exists || !filter.Contains(assignment.DueDate) {
+			continue
+		}
+		details = append(details, reporting.GradeDetail{
+			GradeID:      grade.ID,
+			AssignmentID: grade.AssignmentID,
+			Assignment:   assignment.Title,
+			Subject:      assignment.Subject,
+			DueDate:      assignment.DueDate,
+			Score:        grade.Score,
+			MaxScore:     grade.MaxScore,
+			Status:       string(grade.Status),
+			Feedback:     grade.Feedback,
+			GradedAt:     grade.UpdatedAt,
+		})
+	}
+	sort.Slice(details, func(i, j int) bool {
+		if !details[i].DueDate.Equal(details[j].DueDate) {
+			return details[i].DueDate.Before(details[j].DueDate)
+		}
+		return details[i].Assignment < details[j].Assignment
+	})
+	return details, nil
+}
+
+// ClassGradebook returns the roster of a class with each student's grades
+// matching the filter for the assignments of the class
+func (r *ReportRepository) ClassGradebook(ctx context.Context, classID string, filter reporting.GradeFilter) ([]reporting.StudentGradebook, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	students := []reporting.StudentGradebook{}
+	byStudent := make(map[string]int)
+	for _, id := range r.db.classes[classID].StudentIDs {
+		student, exists := r.db.students[id]
+		if !exists || student.ArchivedAt != nil {
+			continue
+		}
+		byStudent[id] = len(students)
+		students = append(students, reporting.StudentGradebook{
+			StudentID:   id,
+			StudentName: student.Name,
+			Grade:       r.db.gradeBefore(student, filter.To),
+		})
+	}
+
+	var grades []models.Grade
+	for _, grade := range r.db.grades {
+		assignment := r.db.assignments[grade.AssignmentID]
+		if _, listed := byStudent[grade.StudentID]; listed && assignment.ClassID == classID && filter.Contains(assignment.DueDate) {
+			grades = append(grades, grade)
+		}
+	}
+	sort.Slice(grades, func(i, j int) bool {
+		a, b := r.db.assignments[grades[i].AssignmentID], r.db.assignments[grades[j].AssignmentID]
+		if !a.DueDate.Equal(b.DueDate) {
+			return a.DueDate.Before(b.DueDate)
+		}
+		return a.Title < b.Title
+	})
+	for _, grade := range grades {
+		student := &students[byStudent[grade.StudentID]]
+		student.Grades = append(student.Grades, reporting.ClassGrade{
+			AssignmentID: grade.AssignmentID,
+			CategoryID:   r.db.assignments[grade.AssignmentID].CategoryID,
+			Score:        grade.Score,
+			MaxScore:     grade.MaxScore,
+			Status:       string(grade.Status),
+		})
+	}
+
+	sort.SliceStable(students, func(i, j int) bool {
+		if students[i].StudentName != students[j].StudentName {
+			return students[i].StudentName < students[j].StudentName
+		}
+		return students[i].StudentID < students[j].StudentID
+	})
+	return students, nil
+}
@@ -0,0 +1,306 @@
+// Package memory implements the models repositories in process memory. It
+// is meant for tests and local experiments; nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// NewStore returns an empty store with the default permissions granted to
+// each role, as after running the migrations on a new database
+func NewStore() *models.Store {
+	db := newDatabase()
+	return &models.Store{
+		Students:      &StudentRepository{db: db},
+		Users:         &UserRepository{db: db},
+		RefreshTokens: &RefreshTokenRepository{db: db},
+		Invitations:   &InvitationRepository{db: db},
+		Permissions:   &PermissionRepository{db: db},
+		Classes:       &ClassRepository{db: db},
+		Grades:        &GradeRepository{db: db},
+		Gradebook:     &GradebookRepository{db: db},
+		Attendance:    &AttendanceRepository{db: db},
+		Forum:         &ForumRepository{db: db},
+		Audit:         &AuditRepository{db: db},
+		Import:        &ImportRepository{db: db},
+		Reports:       &ReportRepository{db: db},
+		Terms:         &TermRepository{db: db},
+		Promotions:    &PromotionRepository{db: db},
+		Submissions:   &SubmissionRepository{db: db},
+		Jobs:          &JobRepository{db: db},
+		Notifications: &NotificationRepository{db: db},
+	}
+}
+
+// database holds the records of every repository of a store behind one
+// lock, so that cascading deletes see a consistent view like foreign keys do
+type database struct {
+	mu sync.RWMutex
+
+	students        map[string]models.Student
+	users           map[string]models.User
+	refreshTokens   map[string]models.RefreshToken
+	invitations     map[string]invitation
+	permissions     map[string]models.Permission
+	rolePermissions map[models.UserRole][]string
+	classes         map[string]models.Class
+	assignments     map[string]models.Assignment
+	grades          map[string]models.Grade
+	categories      map[string]models.GradingCategory
+	scales          map[string]models.GradeScale
+	settings        map[string]models.GradebookSettings
+	attendance      map[string]models.Attendance
+	posts           map[string]models.ForumPost
+	comments        map[string]models.ForumComment
+	years           map[string]models.AcademicYear
+	terms           map[string]models.Term
+	reportCards     map[string]models.ReportCard
+	gradeHistory    map[string]models.GradeChange
+	submissions     map[string]models.Submission
+	jobRuns         map[string]models.JobRun
+	notifications   map[string]models.Notification
+	notifyPrefs     map[string]models.NotificationPreferences
+	audit           []models.AuditEntry
+}
+
+func newDatabase() *database {
+	db := &database{
+		students:        make(map[string]models.Student),
+		users:           make(map[string]models.User),
+		refreshTokens:   make(map[string]models.RefreshToken),
+		invitations:     make(map[string]invitation),
+		permissions:     make(map[string]models.Permission),
+		rolePermissions: make(map[models.UserRole][]string),
+		classes:         make(map[string]models.Class),
+		assignments:     make(map[string]models.Assignment),
+		grades:          make(map[string]models.Grade),
+		categories:      make(map[string]models.GradingCategory),
+		scales:          make(map[string]models.GradeScale),
+		settings:        make(map[string]models.GradebookSettings),
+		attendance:      make(map[string]models.Attendance),
+		posts:           make(map[string]models.ForumPost),
+		comments:        make(map[string]models.ForumComment),
+		years:           make(map[string]models.AcademicYear),
+		terms:           make(map[string]models.Term),
+		reportCards:     make(map[string]models.ReportCard),
+		gradeHistory:    make(map[string]models.GradeChange),
+		submissions:     make(map[string]models.Submission),
+		jobRuns:         make(map[string]models.JobRun),
+		notifications:   make(map[string]models.Notification),
+		notifyPrefs:     make(map[string]models.NotificationPreferences),
+	}
+	for _, permission := range defaultPermissions {
+		db.permissions[permission.Name] = permission
+	}
+	for role, permissions := range defaultRolePermissions {
+		db.rolePermissions[role] = append([]string(nil), permissions...)
+	}
+	return db
+}
+
+// deleteStudent removes a student and everything that references it,
+// recording the deletion of its grades and attendance in the audit log.
+// The caller must hold the write lock.
+func (db *database) deleteStudent(ctx context.Context, id string) {
+	delete(db.students, id)
+
+	for userID, user := range db.users {
+		if contains(user.StudentIDs, id) {
+			user.StudentIDs = without(user.StudentIDs, id)
+			db.users[userID] = user
+		}
+	}
+	for invitationID, i := range db.invitations {
+		if contains(i.StudentIDs, id) {
+			i.StudentIDs = without(i.StudentIDs, id)
+			db.invitations[invitationID] = i
+		}
+	}
+	for classID, class := range db.classes {
+		if contains(class.StudentIDs, id) {
+			class.StudentIDs = without(class.StudentIDs, id)
+			db.classes[classID] = class
+		}
+	}
+	for gradeID, grade := range db.grades {
+		if grade.StudentID == id {
+			delete(db.grades, gradeID)
+			db.recordAudit(ctx, models.AuditDelete, models.AuditEntityGrade, gradeID, grade, nil)
+		}
+	}
+	for attendanceID, attendance := range db.attendance {
+		if attendance.StudentID == id {
+			delete(db.attendance, attendanceID)
+			db.recordAudit(ctx, models.AuditDelete, models.AuditEntityAttendance, attendanceID, attendance, nil)
+		}
+	}
+	for cardID, card := range db.reportCards {
+		if card.StudentID == id {
+			delete(db.reportCards, cardID)
+		}
+	}
+	for changeID, change := range db.gradeHistory {
+		if change.StudentID == id {
+			delete(db.gradeHistory, changeID)
+		}
+	}
+	for submissionID, submission := range db.submissions {
+		if submission.StudentID == id {
+			delete(db.submissions, submissionID)
+		}
+	}
+	for notificationID, notification := range db.notifications {
+		if notification.StudentID == id {
+			delete(db.notifications, notificationID)
+		}
+	}
+	for postID, post := range db.posts {
+		if post.StudentID == id {
+			db.deletePost(postID)
+		}
+	}
+}
+
+// deleteUser removes a user and everything that references it.
+// The caller must hold the write lock.
+func (db *database) deleteUser(id string) {
+	delete(db.users, id)
+	delete(db.notifyPrefs, id)
+
+	for tokenID, token := range db.refreshTokens {
+		if token.UserID == id {
+			delete(db.refreshTokens, tokenID)
+		}
+	}
+	for invitationID, i := range db.invitations {
+		if i.CreatedBy == id {
+			delete(db.invitations, invitationID)
+		} else if i.RedeemedBy != nil && *i.RedeemedBy == id {
+			i.RedeemedBy = nil
+			db.invitations[invitationID] = i
+		}
+	}
+	for classID, class := range db.classes {
+		if contains(class.TeacherIDs, id) {
+			class.TeacherIDs = without(class.TeacherIDs, id)
+			db.classes[classID] = class
+		}
+	}
+	for postID, post := range db.posts {
+		if post.AuthorID == id {
+			db.deletePost(postID)
+		}
+	}
+	for commentID, comment := range db.comments {
+		if comment.AuthorID == id {
+			delete(db.comments, commentID)
+		}
+	}
+	for notificationID, notification := range db.notifications {
+		if notification.UserID == id {
+			delete(db.notifications, notificationID)
+		}
+	}
+}
+
+// deleteClass removes a class together with its assignments, grading
+// categories and gradebook settings. The caller must hold the write lock.
+func (db *database) deleteClass(ctx context.Context, id string) {
+	delete(db.classes, id)
+	delete(db.settings, id)
+
+	for categoryID, category := range db.categories {
+		if category.ClassID == id {
+			db.deleteCategory(categoryID)
+		}
+	}
+
+	for assignmentID, assignment := range db.assignments {
+		if assignment.ClassID == id {
+			db.deleteAssignment(ctx, assignmentID)
+		}
+	}
+}
+
+// deleteAssignment removes an assignment together with its grades,
+// recording their deletion in the audit log. The caller must hold the
+// write lock.
+func (db *database) deleteAssignment(ctx context.Context, id string) {
+	delete(db.assignments, id)
+
+	for gradeID, grade := range db.grades {
+		if grade.AssignmentID == id {
+			delete(db.grades, gradeID)
+			db.recordAudit(ctx, models.AuditDelete, models.AuditEntityGrade, gradeID, grade, nil)
+		}
+	}
+	for submissionID, submission := range db.submissions {
+		if submission.AssignmentID == id {
+			delete(db.submissions, submissionID)
+		}
+	}
+}
+
+// deleteCategory removes a grading category and leaves its assignments
+// uncategorized. The caller must hold the write lock.
+func (db *database) deleteCategory(id string) {
+	delete(db.categories, id)
+
+	for assignmentID, assignment := range db.assignments {
+		if assignment.CategoryID == id {
+			assignment.CategoryID = ""
+			db.assignments[assignmentID] = assignment
+		}
+	}
+}
+
+// deletePost removes a forum post together with its comments.
+// The caller must hold the write lock.
+func (db *database) deletePost(id string) {
+	delete(db.posts, id)
+
+	for commentID, comment := range db.comments {
+		if comment.PostID == id {
+			delete(db.comments, commentID)
+		}
+	}
+}
+
+// contains reports whether the value is in the slice
+// inScope reports whether the student is among studentIDs, which restricts
+// nothing when nil
+func inScope(studentIDs []string, studentID string) bool {
+	return studentIDs == nil || contains(studentIDs, studentID)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns a copy of the values with every occurrence of value removed
+func without(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// clone returns a copy of the slice that does not share its backing array.
+// Empty slices become nil, like the ID lists read from the database.
+func clone(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// StudentRepository keeps students in memory
+type StudentRepository struct {
+	db *database
+}
+
+// Create stores a new student
+func (r *StudentRepository) Create(ctx context.Context, s *models.Student) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.insertStudent(s); err != nil {
+		return err
+	}
+	r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityStudent, s.ID, nil, *s)
+	return nil
+}
+
+// insertStudent stores a validated student. The caller must hold the write
+// lock.
+func (db *database) insertStudent(s *models.Student) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	if _, ok := db.students[s.ID]; ok {
+		return fmt.Errorf("student %s already exists", s.ID)
+	}
+	if err := db.checkStudentEmail(s); err != nil {
+		return err
+	}
+
+	if s.EnrollmentDate.IsZero() {
+		s.EnrollmentDate = time.Now()
+	}
+	s.ArchivedAt = nil
+	s.ArchivedBy = ""
+	*s = withAge(*s)
+
+	db.students[s.ID] = *s
+	return nil
+}
+
+// Update replaces an existing student
+func (r *StudentRepository) Update(ctx context.Context, s *models.Student) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.students[s.ID]
+	if !ok || existing.ArchivedAt != nil {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+	if err := r.db.checkStudentEmail(s); err != nil {
+		return err
+	}
+
+	if s.EnrollmentDate.IsZero() {
+		s.EnrollmentDate = existing.EnrollmentDate
+	}
+	s.ArchivedAt = nil
+	s.ArchivedBy = ""
+	*s = withAge(*s)
+
+	r.db.students[s.ID] = *s
+	r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityStudent, s.ID, existing, *s)
+	return nil
+}
+
+// checkStudentEmail enforces the unique email of students. The caller must
+// hold the lock.
+func (db *database) checkStudentEmail(s *models.Student) error {
+	for id, student := range db.students {
+		if id != s.ID && student.Email == s.Email {
+			return errors.New("student email already exists")
+		}
+	}
+	return nil
+}
+
+// withAge derives the age of a stored student, as the Postgres store does
+// when it reads a row
+func withAge(s models.Student) models.Student {
+	s.Age = models.AgeOn(s.DateOfBirth, time.Now())
+	return s
+}
+
+// Archive deactivates a student and hides it from the other queries
+func (r *StudentRepository) Archive(ctx context.Context, id, archivedBy string) error {
+	if id == "" {
+		return errors.New("student ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	student, ok := r.db.students[id]
+	if !ok || student.ArchivedAt != nil {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+
+	before := student
+	now := time.Now()
+	student.IsActive = false
+	student.ArchivedAt = &now
+	student.ArchivedBy = archivedBy
+	r.db.students[id] = student
+	r.db.recordAudit(ctx, models.AuditArchive, models.AuditEntityStudent, id, before, student)
+	return nil
+}
+
+// Restore reactivates an archived student
+func (r *StudentRepository) Restore(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	student, ok := r.db.students[id]
+	if !ok || student.ArchivedAt == nil {
+		return fmt.Errorf("archived student %w", models.ErrNotFound)
+	}
+
+	before := student
+	student.IsActive = true
+	student.ArchivedAt = nil
+	student.ArchivedBy = ""
+	r.db.students[id] = student
+	r.db.recordAudit(ctx, models.AuditRestore, models.AuditEntityStudent, id, before, student)
+	return nil
+}
+
+// Purge removes a student archived before the given time, along with its
+// grades, attendance, forum posts and enrollments
+func (r *StudentRepository) Purge(ctx context.Context, id string, archivedBefore time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	student, ok := r.db.students[id]
+	if !ok {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+	if student.ArchivedAt == nil || student.ArchivedAt.After(archivedBefore) {
+		return models.ErrPurgeNotAllowed
+	}
+
+	r.db.deleteStudent(ctx, id)
+	r.db.recordAudit(ctx, models.AuditDelete, models.AuditEntityStudent, id, student, nil)
+	return nil
+}
+
+// GetByID retrieves a student by ID
+func (r *StudentRepository) GetByID(ctx context.Context, id string) (*models.Student, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	student, ok := r.db.students[id]
+	if !ok || student.ArchivedAt != nil {
+		return nil, fmt.Errorf("student %w", models.ErrNotFound)
+	}
+	student = withAge(student)
+	return &student, nil
+}
+
+// List retrieves a page of students
+func (r *StudentRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Student], error) {
+	return r.list(opts, false), nil
+}
+
+// ListArchived retrieves a page of archived students
+func (r *StudentRepository) ListArchived(ctx context.Context, opts models.ListOptions) (models.Page[models.Student], error) {
+	return r.list(opts, true), nil
+}
+
+// list retrieves a page of the archived or the current students
+func (r *StudentRepository) list(opts models.ListOptions, archived bool) models.Page[models.Student] {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var students []models.Student
+	for _, student := range r.db.students {
+		if (student.ArchivedAt != nil) == archived {
+			students = append(students, withAge(student))
+		}
+	}
+	return paginate(students, models.StudentListSpec, opts)
+}
+
+// ListByIDs retrieves the students with the given IDs
+func (r *StudentRepository) ListByIDs(ctx context.Context, ids []string) ([]models.Student, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	students := []models.Student{}
+	for _, id := range models.UniqueStrings(ids) {
+		if student, ok := r.db.students[id]; ok && student.ArchivedAt == nil {
+			students = append(students, withAge(student))
+		}
+	}
+	sortStudents(students)
+	return students, nil
+}
+
+func sortStudents(students []models.Student) {
+	sort.Slice(students, func(i, j int) bool {
+		if students[i].Name != students[j].Name {
+			return students[i].Name < students[j].Name
+		}
+		return students[i].ID < students[j].ID
+	})
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// SubmissionRepository stores submissions in memory
+type SubmissionRepository struct {
+	db *database
+}
+
+// Create stores a submission and gives the student's grade for the
+// assignment its status
+func (r *SubmissionRepository) Create(ctx context.Context, s *models.Submission) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	assignment, ok := r.db.assignments[s.AssignmentID]
+	if !ok {
+		return fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+	if _, ok := r.db.students[s.StudentID]; !ok {
+		return fmt.Errorf("student %w", models.ErrNotFound)
+	}
+	if err := r.db.checkTermOpen(s.AssignmentID); err != nil {
+		return err
+	}
+
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	s.SubmittedAt = time.Now()
+	s.Status = models.SubmissionStatus(s.SubmittedAt, assignment.DueDate)
+	for i := range s.Files {
+		if s.Files[i].ID == "" {
+			s.Files[i].ID = uuid.New().String()
+		}
+	}
+	if s.Files == nil {
+		s.Files = []models.SubmissionFile{}
+	}
+	stored := *s
+	stored.Files = append([]models.SubmissionFile(nil), s.Files...)
+	r.db.submissions[s.ID] = stored
+
+	for id, grade := range r.db.grades {
+		if grade.StudentID != s.StudentID || grade.AssignmentID != s.AssignmentID || grade.Status == s.Status {
+			continue
+		}
+		before := grade
+		grade.Status = s.Status
+		grade.UpdatedAt = s.SubmittedAt
+		r.db.grades[id] = grade
+		r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityGrade, id, before, grade)
+	}
+	return nil
+}
+
+// GetByID retrieves a submission by its ID
+func (r *SubmissionRepository) GetByID(ctx context.Context, id string) (*models.Submission, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	submission, ok := r.db.submissions[id]
+	if !ok {
+		return nil, fmt.Errorf("submission %w", models.ErrNotFound)
+	}
+	return &submission, nil
+}
+
+// ListByAssignmentID returns the submissions for an assignment, most recent
+// first, of the given students only when studentIDs is not nil
+func (r *SubmissionRepository) ListByAssignmentID(ctx context.Context, assignmentID string, studentIDs []string) ([]models.Submission, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.db.listSubmissions(func(s models.Submission) bool {
+		return s.AssignmentID == assignmentID && inScope(studentIDs, s.StudentID)
+	}), nil
+}
+
+// ListByStudentID returns the submissions of a student, most recent first
+func (r *SubmissionRepository) ListByStudentID(ctx context.Context, studentID string) ([]models.Submission, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.db.listSubmissions(func(s models.Submission) bool { return s.StudentID == studentID }), nil
+}
+
+// listSubmissions returns the submissions matching keep, most recent first.
+// The caller must hold the lock.
+func (db *database) listSubmissions(keep func(models.Submission) bool) []models.Submission {
+	var submissions []models.Submission
+	for _, submission := range db.submissions {
+		if keep(submission) {
+			submissions = append(submissions, submission)
+		}
+	}
+	sort.Slice(submissions, func(i, j int) bool {
+		if !submissions[i].SubmittedAt.Equal(submissions[j].SubmittedAt) {
+			return submissions[i].SubmittedAt.After(submissions[j].SubmittedAt)
+		}
+		return submissions[i].ID < submissions[j].ID
+	})
+	return submissions
+}
+
+// submittedStatus gives a grade the status of the latest submission of its
+// student for its assignment. Grades without a submission keep theirs. The
+// caller must hold the lock.
+func (db *database) submittedStatus(g *models.Grade) {
+	submissions := db.listSubmissions(func(s models.Submission) bool {
+		return s.StudentID == g.StudentID && s.AssignmentID == g.AssignmentID
+	})
+	if len(submissions) > 0 {
+		g.Status = submissions[0].Status
+	}
+}
@@ -0,0 +1,367 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// TermRepository keeps academic years, terms and report cards in memory
+type TermRepository struct {
+	db *database
+}
+
+// CreateYear stores a new academic year
+func (r *TermRepository) CreateYear(ctx context.Context, y *models.AcademicYear) error {
+	if err := y.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.db.yearOverlaps(y.StartDate, y.EndDate, "") {
+		return models.ErrTermOverlap
+	}
+
+	if y.ID == "" {
+		y.ID = uuid.New().String()
+	}
+	now := time.Now()
+	y.CreatedAt = now
+	y.UpdatedAt = now
+	y.Terms = []models.Term{}
+
+	stored := *y
+	stored.Terms = nil
+	r.db.years[y.ID] = stored
+	return nil
+}
+
+// UpdateYear renames or moves an academic year. Its terms must still fall
+// within its dates.
+func (r *TermRepository) UpdateYear(ctx context.Context, y *models.AcademicYear) error {
+	if y.ID == "" {
+		return errors.New("academic year ID is required")
+	}
+	if err := y.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.years[y.ID]
+	if !ok {
+		return fmt.Errorf("academic year %w", models.ErrNotFound)
+	}
+	if r.db.yearOverlaps(y.StartDate, y.EndDate, y.ID) {
+		return models.ErrTermOverlap
+	}
+	terms := r.db.yearTerms(y.ID)
+	for i := range terms {
+		if !terms[i].Within(y) {
+			return models.ErrTermOutsideYear
+		}
+	}
+
+	y.CreatedAt = existing.CreatedAt
+	y.UpdatedAt = time.Now()
+
+	stored := *y
+	stored.Terms = nil
+	r.db.years[y.ID] = stored
+	y.Terms = terms
+	return nil
+}
+
+// DeleteYear removes an academic year and its terms unless one of them is
+// finalized
+func (r *TermRepository) DeleteYear(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("academic year ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.years[id]; !ok {
+		return fmt.Errorf("academic year %w", models.ErrNotFound)
+	}
+	terms := r.db.yearTerms(id)
+	for _, term := range terms {
+		if term.Finalized() {
+			return models.ErrTermFinalized
+		}
+	}
+
+	delete(r.db.years, id)
+	for _, term := range terms {
+		delete(r.db.terms, term.ID)
+	}
+	return nil
+}
+
+// GetYearByID retrieves an academic year with its terms
+func (r *TermRepository) GetYearByID(ctx context.Context, id string) (*models.AcademicYear, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	year, ok := r.db.years[id]
+	if !ok {
+		return nil, fmt.Errorf("academic year %w", models.ErrNotFound)
+	}
+	year.Terms = r.db.yearTerms(id)
+	return &year, nil
+}
+
+// ListYears returns every academic year with its terms ordered by start date
+func (r *TermRepository) ListYears(ctx context.Context) ([]models.AcademicYear, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var years []models.AcademicYear
+	for _, year := range r.db.years {
+		year.Terms = r.db.yearTerms(year.ID)
+		years = append(years, year)
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].StartDate.Before(years[j].StartDate) })
+	return years, nil
+}
+
+// CreateTerm stores a new term within its academic year
+func (r *TermRepository) CreateTerm(ctx context.Context, t *models.Term) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.checkTerm(t); err != nil {
+		return err
+	}
+
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	t.FinalizedAt = nil
+	t.FinalizedBy = ""
+
+	r.db.terms[t.ID] = *t
+	return nil
+}
+
+// UpdateTerm renames or moves a term that is not finalized. The term stays
+// in its academic year.
+func (r *TermRepository) UpdateTerm(ctx context.Context, t *models.Term) error {
+	if t.ID == "" {
+		return errors.New("term ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.terms[t.ID]
+	if !ok {
+		return fmt.Errorf("term %w", models.ErrNotFound)
+	}
+	t.AcademicYearID = existing.AcademicYearID
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if existing.Finalized() {
+		return models.ErrTermFinalized
+	}
+	if err := r.db.checkTerm(t); err != nil {
+		return err
+	}
+
+	t.FinalizedAt = nil
+	t.FinalizedBy = ""
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now()
+
+	r.db.terms[t.ID] = *t
+	return nil
+}
+
+// DeleteTerm removes a term that is not finalized
+func (r *TermRepository) DeleteTerm(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("term ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	term, ok := r.db.terms[id]
+	if !ok {
+		return fmt.Errorf("term %w", models.ErrNotFound)
+	}
+	if term.Finalized() {
+		return models.ErrTermFinalized
+	}
+
+	delete(r.db.terms, id)
+	return nil
+}
+
+// GetTermByID retrieves a term by its ID
+func (r *TermRepository) GetTermByID(ctx context.Context, id string) (*models.Term, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	term, ok := r.db.terms[id]
+	if !ok {
+		return nil, fmt.Errorf("term %w", models.ErrNotFound)
+	}
+	return &term, nil
+}
+
+// GetTermByDate retrieves the term a day falls in
+func (r *TermRepository) GetTermByDate(ctx context.Context, date time.Time) (*models.Term, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	if term := r.db.termAt(date); term != nil {
+		return term, nil
+	}
+	return nil, fmt.Errorf("term %w", models.ErrNotFound)
+}
+
+// Finalize marks a term finalized and stores its report cards. The term is
+// marked before the snapshot is taken, so grade changes are refused while it
+// runs; it is unmarked again if the snapshot fails.
+func (r *TermRepository) Finalize(ctx context.Context, id, finalizedBy string, snapshot func(term *models.Term) ([]models.ReportCard, error)) (*models.Term, error) {
+	r.db.mu.Lock()
+	term, ok := r.db.terms[id]
+	if !ok {
+		r.db.mu.Unlock()
+		return nil, fmt.Errorf("term %w", models.ErrNotFound)
+	}
+	if term.Finalized() {
+		r.db.mu.Unlock()
+		return nil, models.ErrTermFinalized
+	}
+	open := term
+	now := time.Now()
+	term.FinalizedAt = &now
+	term.FinalizedBy = finalizedBy
+	term.UpdatedAt = now
+	r.db.terms[id] = term
+	r.db.mu.Unlock()
+
+	// The snapshot reads through the other repositories, which take the lock
+	cards, err := snapshot(&term)
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err != nil {
+		r.db.terms[id] = open
+		return nil, err
+	}
+	for _, card := range cards {
+		if card.ID == "" {
+			card.ID = uuid.New().String()
+		}
+		card.TermID = id
+		card.CreatedAt = now
+		r.db.reportCards[card.ID] = card
+	}
+	return &term, nil
+}
+
+// ListReportCards returns the report cards of a student, most recent term
+// first, without their data
+func (r *TermRepository) ListReportCards(ctx context.Context, studentID string) ([]models.ReportCard, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var cards []models.ReportCard
+	for _, card := range r.db.reportCards {
+		if card.StudentID == studentID {
+			card.Data = nil
+			card.Document = nil
+			cards = append(cards, card)
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		return r.db.terms[cards[i].TermID].StartDate.After(r.db.terms[cards[j].TermID].StartDate)
+	})
+	return cards, nil
+}
+
+// GetReportCardByID retrieves a report card with its data and document
+func (r *TermRepository) GetReportCardByID(ctx context.Context, id string) (*models.ReportCard, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	card, ok := r.db.reportCards[id]
+	if !ok {
+		return nil, fmt.Errorf("report card %w", models.ErrNotFound)
+	}
+	return &card, nil
+}
+
+// yearTerms returns the terms of a year ordered by start date. The caller
+// must hold the lock.
+func (db *database) yearTerms(yearID string) []models.Term {
+	terms := []models.Term{}
+	for _, term := range db.terms {
+		if term.AcademicYearID == yearID {
+			terms = append(terms, term)
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].StartDate.Before(terms[j].StartDate) })
+	return terms
+}
+
+// yearOverlaps reports whether a year other than exceptID overlaps the
+// dates. The caller must hold the lock.
+func (db *database) yearOverlaps(start, end time.Time, exceptID string) bool {
+	for id, year := range db.years {
+		if id != exceptID && !start.After(year.EndDate) && !end.Before(year.StartDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTerm checks that a term falls within its year and overlaps no other
+// term. The caller must hold the lock.
+func (db *database) checkTerm(t *models.Term) error {
+	year, ok := db.years[t.AcademicYearID]
+	if !ok {
+		return fmt.Errorf("academic year %w", models.ErrNotFound)
+	}
+	if !t.Within(&year) {
+		return models.ErrTermOutsideYear
+	}
+	for id, term := range db.terms {
+		if id != t.ID && !t.StartDate.After(term.EndDate) && !t.EndDate.Before(term.StartDate) {
+			return models.ErrTermOverlap
+		}
+	}
+	return nil
+}
+
+// termAt returns the term a day falls in, or nil. The caller must hold the
+// lock.
+func (db *database) termAt(date time.Time) *models.Term {
+	for _, term := range db.terms {
+		if term.Contains(date) {
+			return &term
+		}
+	}
+	return nil
+}
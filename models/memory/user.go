@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// UserRepository keeps users and their links to students in memory
+type UserRepository struct {
+	db *database
+}
+
+// Create stores a new user with a hash of its password
+func (r *UserRepository) Create(ctx context.Context, u *models.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.insertUser(u); err != nil {
+		return err
+	}
+	r.db.recordAudit(ctx, models.AuditCreate, models.AuditEntityUser, u.ID, nil, r.db.users[u.ID])
+	return nil
+}
+
+// insertUser validates and stores a new user. The caller must hold the
+// write lock.
+func (db *database) insertUser(u *models.User) error {
+	if err := u.Validate(); err != nil {
+		return err
+	}
+	if db.userExists(u.Username, u.Email, "") {
+		return models.ErrUserExists
+	}
+
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+	if err := u.HashPassword(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	stored := *u
+	stored.Password = ""
+	stored.StudentIDs = nil
+	if u.Role == models.RoleParent {
+		stored.StudentIDs = models.UniqueStrings(u.StudentIDs)
+	}
+	db.users[u.ID] = stored
+	return nil
+}
+
+// userExists reports whether a user other than exceptID already has the
+// username or email. The caller must hold the lock.
+func (db *database) userExists(username, email, exceptID string) bool {
+	for id, user := range db.users {
+		if id != exceptID && (user.Username == username || user.Email == email) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update updates an existing user. The password is only changed when set,
+// and parent links are only replaced when new ones are given.
+func (r *UserRepository) Update(ctx context.Context, u *models.User) error {
+	if u.ID == "" || u.Username == "" || u.Email == "" || u.FirstName == "" || u.LastName == "" || u.Role == "" {
+		return errors.New("invalid user data")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.db.userExists(u.Username, u.Email, u.ID) {
+		return models.ErrUserExists
+	}
+	existing, ok := r.db.users[u.ID]
+	if !ok {
+		return fmt.Errorf("user %w", models.ErrNotFound)
+	}
+
+	if u.Password != "" {
+		if err := u.HashPassword(); err != nil {
+			return err
+		}
+	} else {
+		u.PasswordHash = existing.PasswordHash
+	}
+	u.CreatedAt = existing.CreatedAt
+	u.UpdatedAt = time.Now()
+
+	stored := *u
+	stored.Password = ""
+	stored.StudentIDs = existing.StudentIDs
+	if u.Role == models.RoleParent && len(u.StudentIDs) > 0 {
+		stored.StudentIDs = models.UniqueStrings(u.StudentIDs)
+	}
+	r.db.users[u.ID] = stored
+	r.db.recordAudit(ctx, models.AuditUpdate, models.AuditEntityUser, u.ID, existing, stored)
+	return nil
+}
+
+// Delete removes a user along with its sessions, invitations, forum posts
+// and comments
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("user ID is required")
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.users[id]
+	if !ok {
+		return fmt.Errorf("user %w", models.ErrNotFound)
+	}
+
+	r.db.deleteUser(id)
+	r.db.recordAudit(ctx, models.AuditDelete, models.AuditEntityUser, id, existing, nil)
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	user, ok := r.db.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %w", models.ErrNotFound)
+	}
+	return copyUser(user), nil
+}
+
+// GetByUsername retrieves a user by username
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, user := range r.db.users {
+		if user.Username == username {
+			return copyUser(user), nil
+		}
+	}
+	return nil, fmt.Errorf("user %w", models.ErrNotFound)
+}
+
+// List retrieves a page of users
+func (r *UserRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.User], error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var users []models.User
+	for _, user := range r.db.users {
+		user.StudentIDs = nil
+		users = append(users, user)
+	}
+	return paginate(users, models.UserListSpec, opts), nil
+}
+
+// GetStudentIDsByParentID retrieves the IDs of all students linked to a parent
+func (r *UserRepository) GetStudentIDsByParentID(ctx context.Context, parentID string) ([]string, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return clone(r.db.users[parentID].StudentIDs), nil
+}
+
+// ListParentsByStudentID retrieves all parents associated with a student
+func (r *UserRepository) ListParentsByStudentID(ctx context.Context, studentID string) ([]models.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var parents []models.User
+	for _, user := range r.db.users {
+		if user.Role == models.RoleParent && contains(user.StudentIDs, studentID) {
+			user.StudentIDs = nil
+			parents = append(parents, user)
+		}
+	}
+	sort.Slice(parents, func(i, j int) bool { return parents[i].Username < parents[j].Username })
+	return parents, nil
+}
+
+// copyUser returns a copy of a stored user. Like the database, only parents
+// come back with their student links.
+func copyUser(user models.User) *models.User {
+	if user.Role == models.RoleParent {
+		user.StudentIDs = clone(user.StudentIDs)
+	} else {
+		user.StudentIDs = nil
+	}
+	return &user
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// NotificationKind is the event a notification tells a parent about
+type NotificationKind string
+
+const (
+	// NotificationAbsence is sent when a child is recorded absent or tardy
+	NotificationAbsence NotificationKind = "absence"
+	// NotificationLowGrade is sent when a child's grade is below the threshold
+	NotificationLowGrade NotificationKind = "low_grade"
+	// NotificationMissingAssignment is sent when a child's grade is marked missing
+	NotificationMissingAssignment NotificationKind = "missing_assignment"
+	// NotificationForumComment is sent when someone comments on a post about a child
+	NotificationForumComment NotificationKind = "forum_comment"
+)
+
+// NotificationKinds lists every kind of notification
+var NotificationKinds = []NotificationKind{NotificationAbsence, NotificationLowGrade, NotificationMissingAssignment, NotificationForumComment}
+
+// IsValid reports whether the kind is one of the known notification kinds
+func (k NotificationKind) IsValid() bool {
+	for _, kind := range NotificationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel is a way of delivering notifications outside the
+// in-app inbox, which receives every notification
+type NotificationChannel string
+
+const (
+	// ChannelEmail delivers notifications to the user's email address
+	ChannelEmail NotificationChannel = "email"
+	// ChannelWebhook posts notifications to the user's webhook URL
+	ChannelWebhook NotificationChannel = "webhook"
+)
+
+// Notification is a message in the inbox of a user about one of their
+// children. EntityType and EntityID name the record it was created for; a
+// user gets at most one notification of each kind per record.
+type Notification struct {
+	ID         string           `json:"id"`
+	UserID     string           `json:"user_id"`
+	StudentID  string           `json:"student_id"`
+	Kind       NotificationKind `json:"kind"`
+	Title      string           `json:"title"`
+	Body       string           `json:"body"`
+	EntityType string           `json:"entity_type"`
+	EntityID   string           `json:"entity_id"`
+	ReadAt     *time.Time       `json:"read_at,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// NotificationListSpec declares how the notifications of a user can be
+// sorted and filtered
+var NotificationListSpec = ListSpec{
+	Fields: map[string]FieldKind{
+		"created_at": KindTime,
+		"kind":       KindString,
+		"student_id": KindString,
+		"read":       KindBool,
+	},
+	Sortable:    []string{"created_at"},
+	DefaultSort: "-created_at",
+	Filters: map[string]Filter{
+		"kind":       {Field: "kind", Op: OpEq},
+		"student_id": {Field: "student_id", Op: OpEq},
+		"read":       {Field: "read", Op: OpEq},
+	},
+}
+
+// ListValue returns the value of a field of NotificationListSpec
+func (n Notification) ListValue(field string) interface{} {
+	switch field {
+	case "created_at":
+		return n.CreatedAt
+	case "kind":
+		return string(n.Kind)
+	case "student_id":
+		return n.StudentID
+	case "read":
+		return n.ReadAt != nil
+	}
+	return n.ID
+}
+
+// NotificationPreferences lists, for each delivery channel, the kinds of
+// notification a user wants delivered through it. Users who never saved
+// preferences get every kind by email and nothing by webhook.
+type NotificationPreferences struct {
+	UserID     string             `json:"user_id"`
+	Email      []NotificationKind `json:"email"`
+	Webhook    []NotificationKind `json:"webhook"`
+	WebhookURL string             `json:"webhook_url"`
+	UpdatedAt  time.Time          `json:"updated_at,omitempty"`
+}
+
+// DefaultNotificationPreferences returns the preferences of a user who
+// never saved any
+func DefaultNotificationPreferences(userID string) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:  userID,
+		Email:   append([]NotificationKind(nil), NotificationKinds...),
+		Webhook: []NotificationKind{},
+	}
+}
+
+// Validate checks the kinds of every channel and that webhooks have an
+// HTTPS URL to go to
+func (p *NotificationPreferences) Validate() error {
+	for _, kinds := range [][]NotificationKind{p.Email, p.Webhook} {
+		for _, kind := range kinds {
+			if !kind.IsValid() {
+				return fmt.Errorf("unknown notification kind: %s", kind)
+			}
+		}
+	}
+	if p.Email == nil {
+		p.Email = []NotificationKind{}
+	}
+	if p.Webhook == nil {
+		p.Webhook = []NotificationKind{}
+	}
+	if len(p.Webhook) > 0 && p.WebhookURL == "" {
+		return errors.New("a webhook_url is required to receive webhooks")
+	}
+	if p.WebhookURL != "" {
+		u, err := url.Parse(p.WebhookURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+			return errors.New("webhook_url must be an https URL without credentials")
+		}
+	}
+	return nil
+}
+
+// Wants reports whether the user wants notifications of the kind delivered
+// through the channel
+func (p *NotificationPreferences) Wants(channel NotificationChannel, kind NotificationKind) bool {
+	var kinds []NotificationKind
+	switch channel {
+	case ChannelEmail:
+		kinds = p.Email
+	case ChannelWebhook:
+		if p.WebhookURL == "" {
+			return false
+		}
+		kinds = p.Webhook
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package models
+
+import "errors"
+
+// ErrUnknownPermission is returned when a role is granted a permission that does not exist
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// Permission is a named action that can be granted to roles
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UniqueStrings returns the values with duplicates removed, keeping the first occurrence
+func UniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+	return unique
+}
@@ -0,0 +1,341 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AttendanceRepository stores attendance records in the attendance table
+type AttendanceRepository struct {
+	db *sql.DB
+}
+
+// Create saves a new attendance record to the database
+func (r *AttendanceRepository) Create(ctx context.Context, a *models.Attendance) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	// Generate UUID if ID is empty
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	query := `INSERT INTO attendance 
+			(id, student_id, date, status, excuse, recorded_by, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	err := audited(ctx, r.db, models.AuditCreate, models.AuditEntityAttendance, a.ID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, a.ID, a.StudentID, a.Date, a.Status, a.Excuse, a.RecordedBy, a.CreatedAt, a.UpdatedAt)
+		if err != nil {
+			log.Printf("Error executing INSERT: %v", err)
+			return fmt.Errorf("failed to execute insert query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return errors.New("failed to create attendance record: no rows affected")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully created attendance record with ID: %s", a.ID)
+	return nil
+}
+
+// Update updates an existing attendance record in the database
+func (r *AttendanceRepository) Update(ctx context.Context, a *models.Attendance) error {
+	if a.ID == "" {
+		return errors.New("attendance ID is required")
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	// Update timestamp
+	a.UpdatedAt = time.Now()
+
+	query := `UPDATE attendance SET 
+			student_id = $1, date = $2, status = $3, excuse = $4, recorded_by = $5, updated_at = $6 
+			WHERE id = $7`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	err := audited(ctx, r.db, models.AuditUpdate, models.AuditEntityAttendance, a.ID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, a.StudentID, a.Date, a.Status, a.Excuse, a.RecordedBy, a.UpdatedAt, a.ID)
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to execute update query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("attendance record %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully updated attendance record with ID: %s", a.ID)
+	return nil
+}
+
+// Delete removes an attendance record from the database
+func (r *AttendanceRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("attendance ID is required")
+	}
+
+	query := "DELETE FROM attendance WHERE id = $1"
+	log.Printf("Executing DELETE query: %s", query)
+
+	err := audited(ctx, r.db, models.AuditDelete, models.AuditEntityAttendance, id, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			log.Printf("Error executing DELETE: %v", err)
+			return fmt.Errorf("failed to execute delete query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("attendance record %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully deleted attendance record with ID: %s", id)
+	return nil
+}
+
+// Upsert creates or replaces attendance records in one transaction,
+// matching existing records on student and date. Whether a record was
+// created is taken from the insert itself, so a record inserted concurrently
+// after the existing one was looked up is still reported as updated.
+func (r *AttendanceRepository) Upsert(ctx context.Context, records []*models.Attendance) (created []bool, err error) {
+	for _, a := range records {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `INSERT INTO attendance
+			(id, student_id, date, status, excuse, recorded_by, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (student_id, date) DO UPDATE SET
+			status = EXCLUDED.status, excuse = EXCLUDED.excuse, recorded_by = EXCLUDED.recorded_by, updated_at = EXCLUDED.updated_at
+			RETURNING id, created_at, xmax = 0`
+	log.Printf("Executing INSERT query for %d records: %s", len(records), query)
+
+	now := time.Now()
+	created = make([]bool, len(records))
+	for i, a := range records {
+		// Lock the existing record, if any, to audit its previous state
+		var before, after []byte
+		var inserted bool
+		var existingID string
+		err = tx.QueryRowContext(ctx, "SELECT id FROM attendance WHERE student_id = $1 AND date = $2 FOR UPDATE", a.StudentID, a.Date).Scan(&existingID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			a.ID = uuid.New().String()
+		case err != nil:
+			log.Printf("Error checking existing attendance: %v", err)
+			return nil, fmt.Errorf("failed to check existing attendance: %w", err)
+		default:
+			a.ID = existingID
+			if before, err = rowJSON(ctx, tx, models.AuditEntityAttendance, a.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		a.UpdatedAt = now
+		err = tx.QueryRowContext(ctx, query, a.ID, a.StudentID, a.Date, a.Status, a.Excuse, a.RecordedBy, now, now).Scan(&a.ID, &a.CreatedAt, &inserted)
+		if err != nil {
+			log.Printf("Error executing INSERT: %v", err)
+			return nil, fmt.Errorf("failed to execute insert query: %w", err)
+		}
+
+		if after, err = rowJSON(ctx, tx, models.AuditEntityAttendance, a.ID); err != nil {
+			return nil, err
+		}
+		action := models.AuditUpdate
+		if inserted {
+			action = models.AuditCreate
+			created[i] = true
+		}
+		if err = recordAudit(ctx, tx, action, models.AuditEntityAttendance, a.ID, before, after); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully upserted %d attendance records", len(records))
+	return created, nil
+}
+
+// GetByID retrieves an attendance record by its ID
+func (r *AttendanceRepository) GetByID(ctx context.Context, id string) (*models.Attendance, error) {
+	query := `SELECT id, student_id, date, status, excuse, recorded_by, created_at, updated_at 
+			FROM attendance WHERE id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var attendance models.Attendance
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&attendance.ID,
+		&attendance.StudentID,
+		&attendance.Date,
+		&attendance.Status,
+		&attendance.Excuse,
+		&attendance.RecordedBy,
+		&attendance.CreatedAt,
+		&attendance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "attendance record")
+	}
+
+	log.Printf("Successfully retrieved attendance record with ID: %s", attendance.ID)
+	return &attendance, nil
+}
+
+// attendanceColumns maps the fields of models.AttendanceListSpec to columns
+var attendanceColumns = map[string]string{"id": "id", "date": "date", "status": "status"}
+
+// ListByStudentID retrieves a page of the attendance records for a student
+func (r *AttendanceRepository) ListByStudentID(ctx context.Context, studentID string, opts models.ListOptions) (models.Page[models.Attendance], error) {
+	opts = opts.WithDefaultSort(models.AttendanceListSpec)
+	list := newListQuery("attendance", attendanceColumns, opts).where("student_id = %s", studentID)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.Attendance]{}, err
+	}
+
+	query, args := list.page("id, student_id, date, status, excuse, recorded_by, created_at, updated_at")
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return models.Page[models.Attendance]{}, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var attendances []models.Attendance
+	for rows.Next() {
+		var attendance models.Attendance
+		err := rows.Scan(
+			&attendance.ID,
+			&attendance.StudentID,
+			&attendance.Date,
+			&attendance.Status,
+			&attendance.Excuse,
+			&attendance.RecordedBy,
+			&attendance.CreatedAt,
+			&attendance.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return models.Page[models.Attendance]{}, fmt.Errorf("failed to scan attendance row: %w", err)
+		}
+		attendances = append(attendances, attendance)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return models.Page[models.Attendance]{}, fmt.Errorf("error iterating attendance rows: %w", err)
+	}
+
+	return models.NewPage(attendances, opts, total), nil
+}
+
+// ListByDateRange retrieves the attendance records within a date range, of
+// the given students only when studentIDs is not nil
+func (r *AttendanceRepository) ListByDateRange(ctx context.Context, startDate, endDate time.Time, studentIDs []string) ([]models.Attendance, error) {
+	query := `SELECT id, student_id, date, status, excuse, recorded_by, created_at, updated_at 
+			FROM attendance WHERE date BETWEEN $1 AND $2 AND ($3::text[] IS NULL OR student_id = ANY($3)) ORDER BY date`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate, pq.Array(studentIDs))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var attendances []models.Attendance
+	for rows.Next() {
+		var attendance models.Attendance
+		err := rows.Scan(
+			&attendance.ID,
+			&attendance.StudentID,
+			&attendance.Date,
+			&attendance.Status,
+			&attendance.Excuse,
+			&attendance.RecordedBy,
+			&attendance.CreatedAt,
+			&attendance.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan attendance row: %w", err)
+		}
+		attendances = append(attendances, attendance)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating attendance rows: %w", err)
+	}
+
+	return attendances, nil
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// AuditRepository reads the audit_log table. Entries are written by the
+// other repositories through audited and recordAudit.
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// auditFields are the columns scanned by List
+const auditFields = "id, COALESCE(actor_id, ''), action, entity_type, entity_id, before, after, COALESCE(request_id, ''), created_at"
+
+// auditColumns maps the fields of models.AuditListSpec to columns
+var auditColumns = map[string]string{
+	"id":          "id",
+	"created_at":  "created_at",
+	"actor_id":    "actor_id",
+	"action":      "action",
+	"entity_type": "entity_type",
+	"entity_id":   "entity_id",
+}
+
+// auditTables maps the audited entity types to their tables
+var auditTables = map[string]string{
+	models.AuditEntityGrade:      "grades",
+	models.AuditEntityAttendance: "attendance",
+	models.AuditEntityUser:       "users",
+	models.AuditEntityStudent:    "students",
+}
+
+// List retrieves a page of audit entries
+func (r *AuditRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.AuditEntry], error) {
+	opts = opts.WithDefaultSort(models.AuditListSpec)
+	list := newListQuery("audit_log", auditColumns, opts)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.AuditEntry]{}, err
+	}
+
+	query, args := list.page(auditFields)
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return models.Page[models.AuditEntry]{}, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return models.Page[models.AuditEntry]{}, fmt.Errorf("failed to scan audit entry row: %w", err)
+		}
+		entry.Before = jsonOrNull(before)
+		entry.After = jsonOrNull(after)
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return models.Page[models.AuditEntry]{}, fmt.Errorf("error iterating audit entry rows: %w", err)
+	}
+
+	return models.NewPage(entries, opts, total), nil
+}
+
+// audited runs change in a transaction and records it in the audit log
+// with the entity's row before and after the change
+func audited(ctx context.Context, db *sql.DB, action models.AuditAction, entityType, entityID string, change func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	before, err := rowJSON(ctx, tx, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if err = change(tx); err != nil {
+		return err
+	}
+	after, err := rowJSON(ctx, tx, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if err = recordAudit(ctx, tx, action, entityType, entityID, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// auditCascade records the deletion of the grades and attendance records
+// selected by query before a delete within tx removes them through ON DELETE
+// CASCADE, which would otherwise leave no trace in the audit log
+func auditCascade(ctx context.Context, tx *sql.Tx, entityType, query string, args ...interface{}) error {
+	ids, err := queryIDs(ctx, tx, query, args...)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		before, err := rowJSON(ctx, tx, entityType, id)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, models.AuditDelete, entityType, id, before, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowJSON returns the row of an audited entity as JSON, or nil if it does
+// not exist, and locks it until the transaction ends. Password hashes are
+// left out.
+func rowJSON(ctx context.Context, tx *sql.Tx, entityType, id string) (json.RawMessage, error) {
+	query := "SELECT to_jsonb(t) - 'password_hash' FROM " + auditTables[entityType] + " t WHERE id = $1 FOR UPDATE"
+
+	var data []byte
+	err := tx.QueryRowContext(ctx, query, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error reading %s for the audit log: %v", entityType, err)
+		return nil, fmt.Errorf("failed to read %s for the audit log: %w", entityType, err)
+	}
+	return data, nil
+}
+
+// recordAudit appends an entry to the audit log within tx, attributed to
+// the actor and request of the context
+func recordAudit(ctx context.Context, tx *sql.Tx, action models.AuditAction, entityType, entityID string, before, after json.RawMessage) error {
+	query := `INSERT INTO audit_log (id, actor_id, action, entity_type, entity_id, before, after, request_id)
+			VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, NULLIF($8, ''))`
+
+	_, err := tx.ExecContext(ctx, query,
+		uuid.New().String(),
+		models.AuditActor(ctx),
+		action,
+		entityType,
+		entityID,
+		jsonParam(before),
+		jsonParam(after),
+		models.AuditRequestID(ctx),
+	)
+	if err != nil {
+		log.Printf("Error writing audit entry: %v", err)
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// jsonParam passes JSON to a JSONB parameter; the driver would send a byte
+// slice as bytea
+func jsonParam(data json.RawMessage) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// jsonOrNull returns the scanned JSON, or the JSON null for NULL columns
+func jsonOrNull(data []byte) json.RawMessage {
+	if data == nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// expectRowJSON expects audited to read the row of an entity, which is
+// missing when row is empty
+func expectRowJSON(mock sqlmock.Sqlmock, table string, id driver.Value, row string) {
+	rows := sqlmock.NewRows([]string{"row"})
+	if row != "" {
+		rows.AddRow([]byte(row))
+	}
+	mock.ExpectQuery(`SELECT to_jsonb\(t\) - 'password_hash' FROM ` + table + ` t WHERE id = \$1 FOR UPDATE`).
+		WithArgs(id).
+		WillReturnRows(rows)
+}
+
+// expectAuditEntry expects an audit entry without actor or request
+func expectAuditEntry(mock sqlmock.Sqlmock, action models.AuditAction, entityType string, id driver.Value) {
+	mock.ExpectExec(`INSERT INTO audit_log`).
+		WithArgs(sqlmock.AnyArg(), "", action, entityType, id, sqlmock.AnyArg(), sqlmock.AnyArg(), "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+// expectTermOpen expects a grade change to check that no finalized term
+// covers its assignments
+func expectTermOpen(mock sqlmock.Sqlmock, assignmentID, gradeID string) {
+	mock.ExpectQuery(`SELECT t.finalized_at IS NOT NULL FROM terms t .* FOR SHARE OF t`).
+		WithArgs(assignmentID, gradeID).
+		WillReturnRows(sqlmock.NewRows([]string{"finalized"}))
+}
+
+// expectNoSubmission expects a grade change to look up the latest submission
+// of the student for the assignment and find none
+func expectNoSubmission(mock sqlmock.Sqlmock, studentID, assignmentID string) {
+	mock.ExpectQuery(`SELECT status FROM submissions WHERE student_id = \$1 AND assignment_id = \$2`).
+		WithArgs(studentID, assignmentID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+}
+
+// Test that grade updates are recorded with the row before and after
+func TestGradeRepository_UpdateGradeAudited(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	ctx := models.WithAuditRequestID(models.WithAuditActor(context.Background(), "teacher-1"), "req-1")
+	grade := &models.Grade{
+		ID:           "g1",
+		StudentID:    "s1",
+		AssignmentID: "a1",
+		Score:        90,
+		MaxScore:     100,
+		Status:       models.AssignmentStatusCompleted,
+		GradedBy:     "teacher-1",
+	}
+
+	mock.ExpectBegin()
+	expectRowJSON(mock, "grades", "g1", `{"id": "g1", "score": 80}`)
+	expectTermOpen(mock, "a1", "g1")
+	expectNoSubmission(mock, "s1", "a1")
+	mock.ExpectExec(`UPDATE grades SET`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRowJSON(mock, "grades", "g1", `{"id": "g1", "score": 90}`)
+	mock.ExpectExec(`INSERT INTO audit_log \(id, actor_id, action, entity_type, entity_id, before, after, request_id\)`).
+		WithArgs(sqlmock.AnyArg(), "teacher-1", models.AuditUpdate, "grade", "g1", `{"id": "g1", "score": 80}`, `{"id": "g1", "score": 90}`, "req-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = store.Grades.UpdateGrade(ctx, grade)
+	require.NoError(t, err)
+
+	// A failed change is rolled back without an entry
+	mock.ExpectBegin()
+	expectRowJSON(mock, "grades", "g1", "")
+	expectTermOpen(mock, "a1", "g1")
+	expectNoSubmission(mock, "s1", "a1")
+	mock.ExpectExec(`UPDATE grades SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = store.Grades.UpdateGrade(ctx, grade)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+
+	// Grades of a finalized term are not changed
+	mock.ExpectBegin()
+	expectRowJSON(mock, "grades", "g1", `{"id": "g1", "score": 80}`)
+	mock.ExpectQuery(`FROM terms t`).
+		WithArgs("a1", "g1").
+		WillReturnRows(sqlmock.NewRows([]string{"finalized"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = store.Grades.UpdateGrade(ctx, grade)
+	assert.ErrorIs(t, err, models.ErrTermFinalized)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test that deleting an assignment records the deletion of each of its
+// grades before the cascade removes them
+func TestGradeRepository_DeleteAssignmentAudited(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	ctx := models.WithAuditActor(context.Background(), "teacher-1")
+
+	mock.ExpectBegin()
+	expectTermOpen(mock, "a1", "")
+	mock.ExpectQuery(`SELECT id FROM grades WHERE assignment_id = \$1`).
+		WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("g1").AddRow("g2"))
+	for _, id := range []string{"g1", "g2"} {
+		expectRowJSON(mock, "grades", id, `{"id": "`+id+`", "score": 80}`)
+		mock.ExpectExec(`INSERT INTO audit_log`).
+			WithArgs(sqlmock.AnyArg(), "teacher-1", models.AuditDelete, "grade", id, `{"id": "`+id+`", "score": 80}`, nil, "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectExec(`DELETE FROM assignments WHERE id = \$1`).
+		WithArgs("a1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = store.Grades.DeleteAssignment(ctx, "a1")
+	require.NoError(t, err)
+
+	// Assignments of a finalized term are kept with their grades
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM terms t`).
+		WithArgs("a1", "").
+		WillReturnRows(sqlmock.NewRows([]string{"finalized"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = store.Grades.DeleteAssignment(ctx, "a1")
+	assert.ErrorIs(t, err, models.ErrTermFinalized)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test that a record inserted by another request after the upsert looked for
+// it is reported and audited as updated, not created
+func TestAttendanceRepository_UpsertConcurrentInsert(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	date := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	records := []*models.Attendance{
+		{StudentID: "s1", Date: date, Status: models.AttendanceStatusAbsent, RecordedBy: "teacher-1"},
+		{StudentID: "s2", Date: date, Status: models.AttendanceStatusPresent, RecordedBy: "teacher-1"},
+	}
+
+	// The record of s1 was inserted concurrently, so its previous state is
+	// unknown
+	mock.ExpectBegin()
+	for i, want := range []struct {
+		id     string
+		action models.AuditAction
+	}{{"a1", models.AuditUpdate}, {"a2", models.AuditCreate}} {
+		id, action := want.id, want.action
+		mock.ExpectQuery(`SELECT id FROM attendance WHERE student_id = \$1 AND date = \$2 FOR UPDATE`).
+			WithArgs(records[i].StudentID, date).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`INSERT INTO attendance .* ON CONFLICT \(student_id, date\) DO UPDATE SET .* RETURNING id, created_at, xmax = 0`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "inserted"}).AddRow(id, date, action == models.AuditCreate))
+		expectRowJSON(mock, "attendance", id, `{"id": "`+id+`"}`)
+		mock.ExpectExec(`INSERT INTO audit_log`).
+			WithArgs(sqlmock.AnyArg(), "", action, "attendance", id, nil, `{"id": "`+id+`"}`, "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	created, err := store.Attendance.Upsert(context.Background(), records)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, created)
+	assert.Equal(t, "a1", records[0].ID, "the concurrently inserted record is kept")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test List Method
+func TestAuditRepository_List(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	opts := models.ListOptions{Conditions: []models.Condition{
+		{Field: "entity_type", Op: models.OpEq, Value: "grade"},
+		{Field: "entity_id", Op: models.OpEq, Value: "g1"},
+	}}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM audit_log WHERE entity_type = \$1 AND entity_id = \$2`).
+		WithArgs("grade", "g1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`FROM audit_log WHERE entity_type = \$1 AND entity_id = \$2 ORDER BY created_at DESC, id DESC`).
+		WithArgs("grade", "g1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_id", "action", "entity_type", "entity_id", "before", "after", "request_id", "created_at"}).
+			AddRow("e1", "teacher-1", "create", "grade", "g1", nil, []byte(`{"id": "g1"}`), "", time.Now()))
+
+	page, err := store.Audit.List(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, json.RawMessage("null"), page.Items[0].Before)
+	assert.JSONEq(t, `{"id": "g1"}`, string(page.Items[0].After))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
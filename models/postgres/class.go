@@ -0,0 +1,328 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ClassRepository stores classes in the classes table and their rosters in
+// class_teachers and class_students
+type ClassRepository struct {
+	db *sql.DB
+}
+
+// Create persists a new class and its roster
+func (r *ClassRepository) Create(ctx context.Context, c *models.Class) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `INSERT INTO classes (id, name, subject, term, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err = tx.ExecContext(ctx, query, c.ID, c.Name, c.Subject, c.Term, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	if err = insertRoster(ctx, tx, c); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully created class with ID: %s", c.ID)
+	return nil
+}
+
+// Update updates a class and replaces its roster
+func (r *ClassRepository) Update(ctx context.Context, c *models.Class) error {
+	if c.ID == "" {
+		return errors.New("class ID is required")
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	c.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := "UPDATE classes SET name = $1, subject = $2, term = $3, updated_at = $4 WHERE id = $5"
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := tx.ExecContext(ctx, query, c.Name, c.Subject, c.Term, c.UpdatedAt, c.ID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		err = fmt.Errorf("class %w", models.ErrNotFound)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM class_teachers WHERE class_id = $1", c.ID); err != nil {
+		log.Printf("Error clearing class teachers: %v", err)
+		return fmt.Errorf("failed to clear class teachers: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM class_students WHERE class_id = $1", c.ID); err != nil {
+		log.Printf("Error clearing class students: %v", err)
+		return fmt.Errorf("failed to clear class students: %w", err)
+	}
+
+	if err = insertRoster(ctx, tx, c); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully updated class with ID: %s", c.ID)
+	return nil
+}
+
+// insertRoster inserts the class teachers and students
+func insertRoster(ctx context.Context, tx *sql.Tx, c *models.Class) error {
+	c.TeacherIDs = models.UniqueStrings(c.TeacherIDs)
+	c.StudentIDs = models.UniqueStrings(c.StudentIDs)
+
+	if len(c.TeacherIDs) > 0 {
+		var faculty int
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE id = ANY($1) AND role = $2", pq.Array(c.TeacherIDs), models.RoleFaculty).Scan(&faculty)
+		if err != nil {
+			log.Printf("Error checking class teachers: %v", err)
+			return fmt.Errorf("failed to check class teachers: %w", err)
+		}
+		if faculty != len(c.TeacherIDs) {
+			return models.ErrInvalidTeacher
+		}
+	}
+
+	for _, teacherID := range c.TeacherIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO class_teachers (class_id, teacher_id) VALUES ($1, $2)", c.ID, teacherID); err != nil {
+			log.Printf("Error assigning class teacher: %v", err)
+			return fmt.Errorf("failed to assign class teacher: %w", err)
+		}
+	}
+
+	for _, studentID := range c.StudentIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO class_students (class_id, student_id) VALUES ($1, $2)", c.ID, studentID); err != nil {
+			log.Printf("Error enrolling student in class: %v", err)
+			return fmt.Errorf("failed to enroll student in class: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Delete removes a class. Its roster and assignments are removed with it,
+// and the deletion of their grades is recorded in the audit log.
+func (r *ClassRepository) Delete(ctx context.Context, id string) (err error) {
+	if id == "" {
+		return errors.New("class ID is required")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	err = auditCascade(ctx, tx, models.AuditEntityGrade, `SELECT g.id FROM grades g
+			JOIN assignments a ON a.id = g.assignment_id WHERE a.class_id = $1 ORDER BY g.id`, id)
+	if err != nil {
+		return err
+	}
+
+	query := "DELETE FROM classes WHERE id = $1"
+	log.Printf("Executing DELETE query: %s", query)
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("class %w", models.ErrNotFound)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully deleted class with ID: %s", id)
+	return nil
+}
+
+// GetByID retrieves a class and its roster by ID
+func (r *ClassRepository) GetByID(ctx context.Context, id string) (*models.Class, error) {
+	query := "SELECT id, name, subject, term, created_at, updated_at FROM classes WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var class models.Class
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&class.ID,
+		&class.Name,
+		&class.Subject,
+		&class.Term,
+		&class.CreatedAt,
+		&class.UpdatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "class")
+	}
+
+	class.TeacherIDs, err = queryIDs(ctx, r.db, "SELECT teacher_id FROM class_teachers WHERE class_id = $1", class.ID)
+	if err != nil {
+		return nil, err
+	}
+	class.StudentIDs, err = queryIDs(ctx, r.db, "SELECT student_id FROM class_students WHERE class_id = $1", class.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &class, nil
+}
+
+// List retrieves all classes without their rosters
+func (r *ClassRepository) List(ctx context.Context) ([]models.Class, error) {
+	return r.queryClasses(ctx, "SELECT id, name, subject, term, created_at, updated_at FROM classes ORDER BY term, name")
+}
+
+// ListByTeacherID retrieves the classes a faculty member teaches, without
+// their rosters
+func (r *ClassRepository) ListByTeacherID(ctx context.Context, teacherID string) ([]models.Class, error) {
+	return r.queryClasses(ctx, `SELECT c.id, c.name, c.subject, c.term, c.created_at, c.updated_at
+			FROM classes c
+			JOIN class_teachers ct ON c.id = ct.class_id
+			WHERE ct.teacher_id = $1
+			ORDER BY c.term, c.name`, teacherID)
+}
+
+// queryClasses runs a query returning class rows
+func (r *ClassRepository) queryClasses(ctx context.Context, query string, args ...interface{}) ([]models.Class, error) {
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []models.Class
+	for rows.Next() {
+		var class models.Class
+		err := rows.Scan(
+			&class.ID,
+			&class.Name,
+			&class.Subject,
+			&class.Term,
+			&class.CreatedAt,
+			&class.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan class row: %w", err)
+		}
+		classes = append(classes, class)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating class rows: %w", err)
+	}
+
+	return classes, nil
+}
+
+// GetIDsByTeacherID returns the IDs of the classes a faculty member teaches
+func (r *ClassRepository) GetIDsByTeacherID(ctx context.Context, teacherID string) ([]string, error) {
+	return queryIDs(ctx, r.db, "SELECT class_id FROM class_teachers WHERE teacher_id = $1", teacherID)
+}
+
+// GetStudentIDsByTeacherID returns the students enrolled in any class the
+// faculty member teaches
+func (r *ClassRepository) GetStudentIDsByTeacherID(ctx context.Context, teacherID string) ([]string, error) {
+	return queryIDs(ctx, r.db, `SELECT DISTINCT cs.student_id
+			FROM class_students cs
+			JOIN class_teachers ct ON cs.class_id = ct.class_id
+			WHERE ct.teacher_id = $1`, teacherID)
+}
+
+// IsStudentEnrolled reports whether the student is enrolled in the class
+func (r *ClassRepository) IsStudentEnrolled(ctx context.Context, classID, studentID string) (bool, error) {
+	var enrolled bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM class_students WHERE class_id = $1 AND student_id = $2)", classID, studentID).Scan(&enrolled)
+	if err != nil {
+		log.Printf("Error checking class enrollment: %v", err)
+		return false, fmt.Errorf("failed to check class enrollment: %w", err)
+	}
+	return enrolled, nil
+}
@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// ForumRepository stores forum posts and comments in the forum_posts and
+// forum_comments tables
+type ForumRepository struct {
+	db *sql.DB
+}
+
+// CreatePost persists a new forum post to the database
+func (r *ForumRepository) CreatePost(ctx context.Context, fp *models.ForumPost) error {
+	if err := fp.Validate(); err != nil {
+		return err
+	}
+
+	// Generate UUID if ID is empty
+	if fp.ID == "" {
+		fp.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	fp.CreatedAt = now
+	fp.UpdatedAt = now
+
+	query := `INSERT INTO forum_posts 
+			(id, title, content, author_id, student_id, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, fp.ID, fp.Title, fp.Content, fp.AuthorID, fp.StudentID, fp.CreatedAt, fp.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("failed to create forum post: no rows affected")
+	}
+
+	log.Printf("Successfully created forum post with ID: %s", fp.ID)
+	return nil
+}
+
+// UpdatePost updates an existing forum post in the database
+func (r *ForumRepository) UpdatePost(ctx context.Context, fp *models.ForumPost) error {
+	if fp.ID == "" {
+		return errors.New("forum post ID is required")
+	}
+	if err := fp.Validate(); err != nil {
+		return err
+	}
+
+	// Update timestamp
+	fp.UpdatedAt = time.Now()
+
+	query := `UPDATE forum_posts SET 
+			title = $1, content = $2, updated_at = $3 
+			WHERE id = $4 AND author_id = $5`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, fp.Title, fp.Content, fp.UpdatedAt, fp.ID, fp.AuthorID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("forum post not found or you are not authorized to update it")
+	}
+
+	log.Printf("Successfully updated forum post with ID: %s", fp.ID)
+	return nil
+}
+
+// DeletePost removes a forum post and its comments from the database
+func (r *ForumRepository) DeletePost(ctx context.Context, id, authorID string) error {
+	if id == "" {
+		return errors.New("forum post ID is required")
+	}
+
+	// First delete all comments associated with this post
+	_, err := r.db.ExecContext(ctx, "DELETE FROM forum_comments WHERE post_id = $1", id)
+	if err != nil {
+		log.Printf("Error deleting comments for post: %v", err)
+		return fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	// Then delete the post
+	query := "DELETE FROM forum_posts WHERE id = $1"
+	if authorID != "" {
+		query += " AND author_id = $2" // Only allow deletion by the author
+	}
+
+	log.Printf("Executing DELETE query: %s", query)
+
+	var result sql.Result
+	if authorID != "" {
+		result, err = r.db.ExecContext(ctx, query, id, authorID)
+	} else {
+		result, err = r.db.ExecContext(ctx, query, id)
+	}
+
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("forum post not found or you are not authorized to delete it")
+	}
+
+	log.Printf("Successfully deleted forum post with ID: %s", id)
+	return nil
+}
+
+// GetPostByID retrieves a forum post by its ID
+func (r *ForumRepository) GetPostByID(ctx context.Context, id string) (*models.ForumPost, error) {
+	query := `SELECT id, title, content, author_id, student_id, created_at, updated_at 
+			FROM forum_posts WHERE id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var post models.ForumPost
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&post.ID,
+		&post.Title,
+		&post.Content,
+		&post.AuthorID,
+		&post.StudentID,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "forum post")
+	}
+
+	log.Printf("Successfully retrieved forum post with ID: %s", post.ID)
+	return &post, nil
+}
+
+// forumPostColumns maps the fields of models.ForumPostListSpec to columns
+var forumPostColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"title":      "title",
+	"author_id":  "author_id",
+}
+
+// ListPostsByStudentID retrieves a page of the forum posts related to a
+// student
+func (r *ForumRepository) ListPostsByStudentID(ctx context.Context, studentID string, opts models.ListOptions) (models.Page[models.ForumPost], error) {
+	opts = opts.WithDefaultSort(models.ForumPostListSpec)
+	list := newListQuery("forum_posts", forumPostColumns, opts).where("student_id = %s", studentID)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.ForumPost]{}, err
+	}
+
+	query, args := list.page("id, title, content, author_id, student_id, created_at, updated_at")
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return models.Page[models.ForumPost]{}, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []models.ForumPost
+	for rows.Next() {
+		var post models.ForumPost
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Content,
+			&post.AuthorID,
+			&post.StudentID,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return models.Page[models.ForumPost]{}, fmt.Errorf("failed to scan forum post row: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return models.Page[models.ForumPost]{}, fmt.Errorf("error iterating forum post rows: %w", err)
+	}
+
+	return models.NewPage(posts, opts, total), nil
+}
+
+// CreateComment persists a new forum comment to the database
+func (r *ForumRepository) CreateComment(ctx context.Context, fc *models.ForumComment) error {
+	if err := fc.Validate(); err != nil {
+		return err
+	}
+
+	// Generate UUID if ID is empty
+	if fc.ID == "" {
+		fc.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	fc.CreatedAt = now
+	fc.UpdatedAt = now
+
+	query := `INSERT INTO forum_comments 
+			(id, post_id, content, author_id, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, fc.ID, fc.PostID, fc.Content, fc.AuthorID, fc.CreatedAt, fc.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("failed to create forum comment: no rows affected")
+	}
+
+	log.Printf("Successfully created forum comment with ID: %s", fc.ID)
+	return nil
+}
+
+// UpdateComment updates an existing forum comment in the database
+func (r *ForumRepository) UpdateComment(ctx context.Context, fc *models.ForumComment) error {
+	if fc.ID == "" || fc.Content == "" || fc.AuthorID == "" {
+		return errors.New("invalid forum comment data")
+	}
+
+	// Update timestamp
+	fc.UpdatedAt = time.Now()
+
+	query := `UPDATE forum_comments SET 
+			content = $1, updated_at = $2 
+			WHERE id = $3 AND author_id = $4`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, fc.Content, fc.UpdatedAt, fc.ID, fc.AuthorID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("forum comment not found or you are not authorized to update it")
+	}
+
+	log.Printf("Successfully updated forum comment with ID: %s", fc.ID)
+	return nil
+}
+
+// ListCommentsByPostID retrieves all comments for a forum post
+func (r *ForumRepository) ListCommentsByPostID(ctx context.Context, postID string) ([]models.ForumComment, error) {
+	query := `SELECT id, post_id, content, author_id, created_at, updated_at 
+			FROM forum_comments WHERE post_id = $1 ORDER BY created_at ASC`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.ForumComment
+	for rows.Next() {
+		var comment models.ForumComment
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.Content,
+			&comment.AuthorID,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan forum comment row: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating forum comment rows: %w", err)
+	}
+
+	return comments, nil
+}
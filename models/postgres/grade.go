@@ -0,0 +1,657 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// GradeRepository stores assignments and grades in the assignments and
+// grades tables
+type GradeRepository struct {
+	db *sql.DB
+}
+
+// CreateAssignment persists a new assignment to the database. Its due date
+// may not fall in a finalized term.
+func (r *GradeRepository) CreateAssignment(ctx context.Context, a *models.Assignment) (err error) {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if a.ClassID == "" {
+		return models.ErrAssignmentClassRequired
+	}
+
+	// Generate UUID if ID is empty
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	if err = checkDateOpen(ctx, tx, a.DueDate); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO assignments 
+			(id, title, description, subject, due_date, class_id, category_id, max_score, created_by, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	result, err := tx.ExecContext(ctx, query, a.ID, a.Title, a.Description, a.Subject, a.DueDate, a.ClassID, a.CategoryID, a.MaxScore, a.CreatedBy, a.CreatedAt, a.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("failed to create assignment: no rows affected")
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully created assignment with ID: %s", a.ID)
+	return nil
+}
+
+// UpdateAssignment updates an existing assignment in the database. Neither
+// its current nor its new due date may fall in a finalized term.
+func (r *GradeRepository) UpdateAssignment(ctx context.Context, a *models.Assignment) (err error) {
+	if a.ID == "" {
+		return errors.New("assignment ID is required")
+	}
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	// Update timestamp
+	a.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	if err = checkTermOpen(ctx, tx, a.ID, ""); err != nil {
+		return err
+	}
+	if err = checkDateOpen(ctx, tx, a.DueDate); err != nil {
+		return err
+	}
+
+	query := `UPDATE assignments SET 
+			title = $1, description = $2, subject = $3, due_date = $4, class_id = NULLIF($5, ''), category_id = NULLIF($6, ''), max_score = $7, created_by = $8, updated_at = $9 
+			WHERE id = $10`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := tx.ExecContext(ctx, query, a.Title, a.Description, a.Subject, a.DueDate, a.ClassID, a.CategoryID, a.MaxScore, a.CreatedBy, a.UpdatedAt, a.ID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully updated assignment with ID: %s", a.ID)
+	return nil
+}
+
+// DeleteAssignment removes an assignment from the database together with
+// its grades, whose deletion is recorded in the audit log, unless it is due
+// in a finalized term
+func (r *GradeRepository) DeleteAssignment(ctx context.Context, id string) (err error) {
+	if id == "" {
+		return errors.New("assignment ID is required")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	if err = checkTermOpen(ctx, tx, id, ""); err != nil {
+		return err
+	}
+	if err = auditCascade(ctx, tx, models.AuditEntityGrade, "SELECT id FROM grades WHERE assignment_id = $1 ORDER BY id", id); err != nil {
+		return err
+	}
+
+	query := "DELETE FROM assignments WHERE id = $1"
+	log.Printf("Executing DELETE query: %s", query)
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("assignment %w", models.ErrNotFound)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully deleted assignment with ID: %s", id)
+	return nil
+}
+
+// GetAssignmentByID retrieves an assignment by its ID
+func (r *GradeRepository) GetAssignmentByID(ctx context.Context, id string) (*models.Assignment, error) {
+	query := `SELECT id, title, description, subject, due_date, COALESCE(class_id, ''), COALESCE(category_id, ''), max_score, created_by, created_at, updated_at 
+			FROM assignments WHERE id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var assignment models.Assignment
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&assignment.ID,
+		&assignment.Title,
+		&assignment.Description,
+		&assignment.Subject,
+		&assignment.DueDate,
+		&assignment.ClassID,
+		&assignment.CategoryID,
+		&assignment.MaxScore,
+		&assignment.CreatedBy,
+		&assignment.CreatedAt,
+		&assignment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "assignment")
+	}
+
+	log.Printf("Successfully retrieved assignment with ID: %s", assignment.ID)
+	return &assignment, nil
+}
+
+// assignmentColumns maps the fields of models.AssignmentListSpec to columns
+var assignmentColumns = map[string]string{
+	"id":          "id",
+	"title":       "title",
+	"subject":     "subject",
+	"due_date":    "due_date",
+	"class_id":    "class_id",
+	"category_id": "category_id",
+}
+
+// ListAssignments retrieves a page of assignments
+func (r *GradeRepository) ListAssignments(ctx context.Context, opts models.ListOptions) (models.Page[models.Assignment], error) {
+	opts = opts.WithDefaultSort(models.AssignmentListSpec)
+	list := newListQuery("assignments", assignmentColumns, opts)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.Assignment]{}, err
+	}
+
+	query, args := list.page("id, title, description, subject, due_date, COALESCE(class_id, ''), COALESCE(category_id, ''), max_score, created_by, created_at, updated_at")
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return models.Page[models.Assignment]{}, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var assignments []models.Assignment
+	for rows.Next() {
+		var assignment models.Assignment
+		err := rows.Scan(
+			&assignment.ID,
+			&assignment.Title,
+			&assignment.Description,
+			&assignment.Subject,
+			&assignment.DueDate,
+			&assignment.ClassID,
+			&assignment.CategoryID,
+			&assignment.MaxScore,
+			&assignment.CreatedBy,
+			&assignment.CreatedAt,
+			&assignment.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return models.Page[models.Assignment]{}, fmt.Errorf("failed to scan assignment row: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return models.Page[models.Assignment]{}, fmt.Errorf("error iterating assignment rows: %w", err)
+	}
+
+	return models.NewPage(assignments, opts, total), nil
+}
+
+// CreateGrade persists a new grade to the database
+func (r *GradeRepository) CreateGrade(ctx context.Context, g *models.Grade) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	// Generate UUID if ID is empty
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+
+	// Set timestamps
+	now := time.Now()
+	g.CreatedAt = now
+	g.UpdatedAt = now
+
+	query := `INSERT INTO grades 
+			(id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	err := audited(ctx, r.db, models.AuditCreate, models.AuditEntityGrade, g.ID, func(tx *sql.Tx) error {
+		if err := checkTermOpen(ctx, tx, g.AssignmentID, g.ID); err != nil {
+			return err
+		}
+		if err := submittedStatus(ctx, tx, g); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, query, g.ID, g.StudentID, g.AssignmentID, g.Score, g.MaxScore, g.Status, g.Feedback, g.GradedBy, g.CreatedAt, g.UpdatedAt)
+		if err != nil {
+			log.Printf("Error executing INSERT: %v", err)
+			return fmt.Errorf("failed to execute insert query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return errors.New("failed to create grade: no rows affected")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully created grade with ID: %s", g.ID)
+	return nil
+}
+
+// UpdateGrade updates an existing grade in the database
+func (r *GradeRepository) UpdateGrade(ctx context.Context, g *models.Grade) error {
+	if g.ID == "" {
+		return errors.New("grade ID is required")
+	}
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	// Update timestamp
+	g.UpdatedAt = time.Now()
+
+	query := `UPDATE grades SET 
+			student_id = $1, assignment_id = $2, score = $3, max_score = $4, status = $5, feedback = $6, graded_by = $7, updated_at = $8 
+			WHERE id = $9`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	err := audited(ctx, r.db, models.AuditUpdate, models.AuditEntityGrade, g.ID, func(tx *sql.Tx) error {
+		if err := checkTermOpen(ctx, tx, g.AssignmentID, g.ID); err != nil {
+			return err
+		}
+		if err := submittedStatus(ctx, tx, g); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, query, g.StudentID, g.AssignmentID, g.Score, g.MaxScore, g.Status, g.Feedback, g.GradedBy, g.UpdatedAt, g.ID)
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to execute update query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("grade %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully updated grade with ID: %s", g.ID)
+	return nil
+}
+
+// checkTermOpen fails with models.ErrTermFinalized when the assignment, or
+// the one the grade currently belongs to, is due in a finalized term. The
+// terms are share-locked so that a term cannot be finalized until the
+// transaction ends.
+func checkTermOpen(ctx context.Context, tx *sql.Tx, assignmentID, gradeID string) error {
+	query := `SELECT t.finalized_at IS NOT NULL FROM terms t
+			JOIN assignments a ON a.due_date::date BETWEEN t.start_date AND t.end_date
+			WHERE a.id = $1 OR a.id = (SELECT assignment_id FROM grades WHERE id = $2)
+			FOR SHARE OF t`
+	return checkTermsOpen(ctx, tx, query, assignmentID, gradeID)
+}
+
+// checkDateOpen fails with models.ErrTermFinalized when the due date falls
+// in a finalized term, share-locking the term like checkTermOpen
+func checkDateOpen(ctx context.Context, tx *sql.Tx, dueDate time.Time) error {
+	query := `SELECT finalized_at IS NOT NULL FROM terms
+			WHERE CAST($1 AS TIMESTAMPTZ)::date BETWEEN start_date AND end_date
+			FOR SHARE`
+	return checkTermsOpen(ctx, tx, query, dueDate)
+}
+
+// checkTermsOpen runs a query selecting whether terms are finalized and
+// fails with models.ErrTermFinalized if any is
+func checkTermsOpen(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) error {
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return fmt.Errorf("failed to check the term: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var finalized bool
+		if err := rows.Scan(&finalized); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return fmt.Errorf("failed to scan term row: %w", err)
+		}
+		if finalized {
+			return models.ErrTermFinalized
+		}
+	}
+	return rows.Err()
+}
+
+// GetGradeByID retrieves a grade by its ID
+func (r *GradeRepository) GetGradeByID(ctx context.Context, id string) (*models.Grade, error) {
+	query := `SELECT id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at 
+			FROM grades WHERE id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var grade models.Grade
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&grade.ID,
+		&grade.StudentID,
+		&grade.AssignmentID,
+		&grade.Score,
+		&grade.MaxScore,
+		&grade.Status,
+		&grade.Feedback,
+		&grade.GradedBy,
+		&grade.CreatedAt,
+		&grade.UpdatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "grade")
+	}
+
+	log.Printf("Successfully retrieved grade with ID: %s", grade.ID)
+	return &grade, nil
+}
+
+// ListGradesByStudentID retrieves all grades for a student
+func (r *GradeRepository) ListGradesByStudentID(ctx context.Context, studentID string) ([]models.Grade, error) {
+	query := `SELECT id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at 
+			FROM grades WHERE student_id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var grades []models.Grade
+	for rows.Next() {
+		var grade models.Grade
+		err := rows.Scan(
+			&grade.ID,
+			&grade.StudentID,
+			&grade.AssignmentID,
+			&grade.Score,
+			&grade.MaxScore,
+			&grade.Status,
+			&grade.Feedback,
+			&grade.GradedBy,
+			&grade.CreatedAt,
+			&grade.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan grade row: %w", err)
+		}
+		grades = append(grades, grade)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating grade rows: %w", err)
+	}
+
+	return grades, nil
+}
+
+// ListGradesByAssignmentID retrieves the grades for an assignment, of the
+// given students only when studentIDs is not nil
+func (r *GradeRepository) ListGradesByAssignmentID(ctx context.Context, assignmentID string, studentIDs []string) ([]models.Grade, error) {
+	query := `SELECT id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at 
+			FROM grades WHERE assignment_id = $1 AND ($2::text[] IS NULL OR student_id = ANY($2))`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID, pq.Array(studentIDs))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var grades []models.Grade
+	for rows.Next() {
+		var grade models.Grade
+		err := rows.Scan(
+			&grade.ID,
+			&grade.StudentID,
+			&grade.AssignmentID,
+			&grade.Score,
+			&grade.MaxScore,
+			&grade.Status,
+			&grade.Feedback,
+			&grade.GradedBy,
+			&grade.CreatedAt,
+			&grade.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan grade row: %w", err)
+		}
+		grades = append(grades, grade)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating grade rows: %w", err)
+	}
+
+	return grades, nil
+}
+
+// MarkMissing gives a missing grade to every active student on the roster
+// of an assignment due in the period who has neither a grade nor a
+// submission for it. Each insert checks again for a grade or submission, and
+// the unique grade per student and assignment keeps a concurrent run or
+// teacher from grading twice.
+func (r *GradeRepository) MarkMissing(ctx context.Context, dueFrom, dueBefore time.Time) (created []models.Grade, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	var assignmentIDs []string
+	assignmentIDs, err = queryIDs(ctx, tx, `SELECT id FROM assignments
+			WHERE class_id IS NOT NULL AND due_date >= $1 AND due_date < $2 ORDER BY due_date, id`, dueFrom, dueBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, assignmentID := range assignmentIDs {
+		if err = checkTermOpen(ctx, tx, assignmentID, ""); errors.Is(err, models.ErrTermFinalized) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var studentIDs []string
+		studentIDs, err = queryIDs(ctx, tx, `SELECT cs.student_id FROM class_students cs
+				JOIN assignments a ON a.class_id = cs.class_id
+				JOIN students s ON s.id = cs.student_id
+				WHERE a.id = $1 AND s.is_active AND s.archived_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM grades g WHERE g.student_id = cs.student_id AND g.assignment_id = a.id)
+				AND NOT EXISTS (SELECT 1 FROM submissions sb WHERE sb.student_id = cs.student_id AND sb.assignment_id = a.id)
+				ORDER BY cs.student_id`, assignmentID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, studentID := range studentIDs {
+			var grade *models.Grade
+			if grade, err = insertMissingGrade(ctx, tx, assignmentID, studentID); err != nil {
+				return nil, err
+			}
+			if grade != nil {
+				created = append(created, *grade)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully marked %d grades missing", len(created))
+	return created, nil
+}
+
+// insertMissingGrade creates the missing grade of a student for an
+// assignment unless a grade or submission exists by now, and audits it. It
+// returns nil when no grade was created.
+func insertMissingGrade(ctx context.Context, tx *sql.Tx, assignmentID, studentID string) (*models.Grade, error) {
+	g := models.Grade{
+		ID:           uuid.New().String(),
+		StudentID:    studentID,
+		AssignmentID: assignmentID,
+		Status:       models.AssignmentStatusMissing,
+		Feedback:     models.MissingFeedback,
+		CreatedAt:    time.Now(),
+	}
+	g.UpdatedAt = g.CreatedAt
+	query := `INSERT INTO grades (id, student_id, assignment_id, score, max_score, status, feedback, graded_by, created_at, updated_at)
+			SELECT $1, $2, a.id, 0, a.max_score, $3, $4, a.created_by, $5, $5 FROM assignments a
+			WHERE a.id = $6 AND NOT EXISTS (SELECT 1 FROM submissions WHERE student_id = $2 AND assignment_id = a.id)
+			ON CONFLICT (student_id, assignment_id) DO NOTHING
+			RETURNING max_score, graded_by`
+
+	err := tx.QueryRowContext(ctx, query, g.ID, g.StudentID, g.Status, g.Feedback, g.CreatedAt, g.AssignmentID).Scan(&g.MaxScore, &g.GradedBy)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return nil, fmt.Errorf("failed to mark grade missing: %w", err)
+	}
+
+	after, err := rowJSON(ctx, tx, models.AuditEntityGrade, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, models.AuditCreate, models.AuditEntityGrade, g.ID, nil, after); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
@@ -0,0 +1,350 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// GradebookRepository stores grading categories in grading_categories,
+// letter-grade scales in grade_scales and class settings in
+// gradebook_settings
+type GradebookRepository struct {
+	db *sql.DB
+}
+
+// categoryFields lists the columns scanned by scanCategory
+const categoryFields = "id, COALESCE(class_id, ''), COALESCE(subject, ''), name, weight, created_at, updated_at"
+
+// scanCategory scans a row selected with categoryFields
+func scanCategory(row interface{ Scan(...interface{}) error }, c *models.GradingCategory) error {
+	return row.Scan(&c.ID, &c.ClassID, &c.Subject, &c.Name, &c.Weight, &c.CreatedAt, &c.UpdatedAt)
+}
+
+// CreateCategory persists a new grading category
+func (r *GradebookRepository) CreateCategory(ctx context.Context, c *models.GradingCategory) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	query := `INSERT INTO grading_categories (id, class_id, subject, name, weight, created_at, updated_at)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err := r.db.ExecContext(ctx, query, c.ID, c.ClassID, c.Subject, c.Name, c.Weight, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	log.Printf("Successfully created grading category with ID: %s", c.ID)
+	return nil
+}
+
+// UpdateCategory updates the name and weight of a category. The class or
+// subject it belongs to cannot change, as its assignments were checked
+// against it.
+func (r *GradebookRepository) UpdateCategory(ctx context.Context, c *models.GradingCategory) error {
+	if c.ID == "" {
+		return errors.New("category ID is required")
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	c.UpdatedAt = time.Now()
+
+	query := `UPDATE grading_categories SET name = $1, weight = $2, updated_at = $3
+			WHERE id = $4
+			RETURNING COALESCE(class_id, ''), COALESCE(subject, ''), created_at`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	err := r.db.QueryRowContext(ctx, query, c.Name, c.Weight, c.UpdatedAt, c.ID).Scan(&c.ClassID, &c.Subject, &c.CreatedAt)
+	if err != nil {
+		return notFound(err, "category")
+	}
+
+	log.Printf("Successfully updated grading category with ID: %s", c.ID)
+	return nil
+}
+
+// DeleteCategory removes a category. The foreign key leaves its
+// assignments uncategorized.
+func (r *GradebookRepository) DeleteCategory(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("category ID is required")
+	}
+
+	query := "DELETE FROM grading_categories WHERE id = $1"
+	log.Printf("Executing DELETE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("category %w", models.ErrNotFound)
+	}
+
+	log.Printf("Successfully deleted grading category with ID: %s", id)
+	return nil
+}
+
+// GetCategoryByID retrieves a grading category by its ID
+func (r *GradebookRepository) GetCategoryByID(ctx context.Context, id string) (*models.GradingCategory, error) {
+	query := "SELECT " + categoryFields + " FROM grading_categories WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var category models.GradingCategory
+	if err := scanCategory(r.db.QueryRowContext(ctx, query, id), &category); err != nil {
+		return nil, notFound(err, "category")
+	}
+	return &category, nil
+}
+
+// ListCategories returns the categories of a class and of a subject
+func (r *GradebookRepository) ListCategories(ctx context.Context, classID, subject string) ([]models.GradingCategory, error) {
+	query := "SELECT " + categoryFields + ` FROM grading_categories
+			WHERE ($1::text <> '' AND class_id = $1) OR ($2::text <> '' AND subject = $2)
+			ORDER BY name, id`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, classID, subject)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []models.GradingCategory
+	for rows.Next() {
+		var category models.GradingCategory
+		if err := scanCategory(rows, &category); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan category row: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating category rows: %w", err)
+	}
+
+	return categories, nil
+}
+
+// CreateScale persists a new letter-grade scale
+func (r *GradebookRepository) CreateScale(ctx context.Context, s *models.GradeScale) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	steps, err := json.Marshal(s.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode scale steps: %w", err)
+	}
+
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	query := `INSERT INTO grade_scales (id, name, steps, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err = r.db.ExecContext(ctx, query, s.ID, s.Name, string(steps), s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	log.Printf("Successfully created grade scale with ID: %s", s.ID)
+	return nil
+}
+
+// UpdateScale replaces the name and steps of a scale
+func (r *GradebookRepository) UpdateScale(ctx context.Context, s *models.GradeScale) error {
+	if s.ID == "" {
+		return errors.New("scale ID is required")
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	steps, err := json.Marshal(s.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode scale steps: %w", err)
+	}
+
+	s.UpdatedAt = time.Now()
+
+	query := `UPDATE grade_scales SET name = $1, steps = $2, updated_at = $3
+			WHERE id = $4
+			RETURNING created_at`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	err = r.db.QueryRowContext(ctx, query, s.Name, string(steps), s.UpdatedAt, s.ID).Scan(&s.CreatedAt)
+	if err != nil {
+		return notFound(err, "scale")
+	}
+
+	log.Printf("Successfully updated grade scale with ID: %s", s.ID)
+	return nil
+}
+
+// DeleteScale removes a scale. The foreign key clears it from the settings
+// of the classes using it.
+func (r *GradebookRepository) DeleteScale(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("scale ID is required")
+	}
+
+	query := "DELETE FROM grade_scales WHERE id = $1"
+	log.Printf("Executing DELETE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("scale %w", models.ErrNotFound)
+	}
+
+	log.Printf("Successfully deleted grade scale with ID: %s", id)
+	return nil
+}
+
+// scanScale scans a row of id, name, steps, created_at and updated_at
+func scanScale(row interface{ Scan(...interface{}) error }, s *models.GradeScale) error {
+	var steps []byte
+	if err := row.Scan(&s.ID, &s.Name, &steps, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(steps, &s.Steps); err != nil {
+		return fmt.Errorf("failed to decode scale steps: %w", err)
+	}
+	return nil
+}
+
+// GetScaleByID retrieves a letter-grade scale by its ID
+func (r *GradebookRepository) GetScaleByID(ctx context.Context, id string) (*models.GradeScale, error) {
+	query := "SELECT id, name, steps, created_at, updated_at FROM grade_scales WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var scale models.GradeScale
+	if err := scanScale(r.db.QueryRowContext(ctx, query, id), &scale); err != nil {
+		return nil, notFound(err, "scale")
+	}
+	return &scale, nil
+}
+
+// ListScales returns every letter-grade scale ordered by name
+func (r *GradebookRepository) ListScales(ctx context.Context) ([]models.GradeScale, error) {
+	query := "SELECT id, name, steps, created_at, updated_at FROM grade_scales ORDER BY name"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var scales []models.GradeScale
+	for rows.Next() {
+		var scale models.GradeScale
+		if err := scanScale(rows, &scale); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan scale row: %w", err)
+		}
+		scales = append(scales, scale)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating scale rows: %w", err)
+	}
+
+	return scales, nil
+}
+
+// GetSettings retrieves the gradebook settings of a class
+func (r *GradebookRepository) GetSettings(ctx context.Context, classID string) (*models.GradebookSettings, error) {
+	query := `SELECT class_id, COALESCE(grade_scale_id, ''), missing_policy, updated_at
+			FROM gradebook_settings WHERE class_id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var settings models.GradebookSettings
+	err := r.db.QueryRowContext(ctx, query, classID).Scan(
+		&settings.ClassID,
+		&settings.GradeScaleID,
+		&settings.MissingPolicy,
+		&settings.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DefaultGradebookSettings(classID), nil
+	}
+	if err != nil {
+		return nil, notFound(err, "gradebook settings")
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the gradebook settings of a class
+func (r *GradebookRepository) SaveSettings(ctx context.Context, s *models.GradebookSettings) error {
+	if s.ClassID == "" {
+		return errors.New("class ID is required")
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.UpdatedAt = time.Now()
+
+	query := `INSERT INTO gradebook_settings (class_id, grade_scale_id, missing_policy, updated_at)
+			VALUES ($1, NULLIF($2, ''), $3, $4)
+			ON CONFLICT (class_id) DO UPDATE SET
+				grade_scale_id = EXCLUDED.grade_scale_id,
+				missing_policy = EXCLUDED.missing_policy,
+				updated_at = EXCLUDED.updated_at`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err := r.db.ExecContext(ctx, query, s.ClassID, s.GradeScaleID, s.MissingPolicy, s.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	log.Printf("Successfully saved gradebook settings of class: %s", s.ClassID)
+	return nil
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// ImportRepository writes import batches in a single transaction
+type ImportRepository struct {
+	db *sql.DB
+}
+
+// Import creates the students, users and parent links of the batch. Each
+// row runs under a savepoint so that a failing row is reported without
+// aborting the transaction, and every row is checked even when an earlier
+// one failed. The transaction is only committed when no row failed and
+// this is not a dry run.
+func (r *ImportRepository) Import(ctx context.Context, batch *models.ImportBatch, dryRun bool) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	var rowErrors models.ImportErrors
+	row := func(file string, line int, write func() error) error {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_row"); err != nil {
+			return fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		if writeErr := write(); writeErr != nil {
+			rowErrors = append(rowErrors, models.ImportError{File: file, Line: line, Message: writeErr.Error()})
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); err != nil {
+				return fmt.Errorf("failed to roll back to savepoint: %w", err)
+			}
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+			return fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		return nil
+	}
+
+	for i := range batch.Students {
+		s := &batch.Students[i].Student
+		err = row(models.ImportFileStudents, batch.Students[i].Line, func() error {
+			return importStudent(ctx, tx, s)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range batch.Users {
+		u := &batch.Users[i].User
+		err = row(models.ImportFileUsers, batch.Users[i].Line, func() error {
+			return importUser(ctx, tx, u)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, link := range batch.ParentLinks {
+		err = row(models.ImportFileParentLinks, link.Line, func() error {
+			return importParentLink(ctx, tx, link)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		err = rowErrors
+		return err
+	}
+	if dryRun {
+		if err := tx.Rollback(); err != nil {
+			log.Printf("Error rolling back transaction: %v", err)
+		}
+		log.Printf("Dry run imported %d students, %d users and %d parent links", len(batch.Students), len(batch.Users), len(batch.ParentLinks))
+		return nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully imported %d students, %d users and %d parent links", len(batch.Students), len(batch.Users), len(batch.ParentLinks))
+	return nil
+}
+
+// importStudent creates a student and records it in the audit log
+func importStudent(ctx context.Context, tx *sql.Tx, s *models.Student) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM students WHERE email = $1", s.Email).Scan(&count); err != nil {
+		log.Printf("Error checking existing student: %v", err)
+		return fmt.Errorf("failed to check existing student: %w", err)
+	}
+	if count > 0 {
+		return errors.New("student email already exists")
+	}
+
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	if err := insertStudent(ctx, tx, s); err != nil {
+		return err
+	}
+	return auditCreated(ctx, tx, models.AuditEntityStudent, s.ID)
+}
+
+// importUser creates a user and records it in the audit log
+func importUser(ctx context.Context, tx *sql.Tx, u *models.User) error {
+	if err := insertUser(ctx, tx, u); err != nil {
+		return err
+	}
+	return auditCreated(ctx, tx, models.AuditEntityUser, u.ID)
+}
+
+// importParentLink links a parent to a student, either of which may have
+// been created earlier in the same transaction
+func importParentLink(ctx context.Context, tx *sql.Tx, link models.ImportParentLink) error {
+	var parentID string
+	var role models.UserRole
+	err := tx.QueryRowContext(ctx, "SELECT id, role FROM users WHERE username = $1", link.ParentUsername).Scan(&parentID, &role)
+	if err != nil {
+		return notFound(err, "parent")
+	}
+	if role != models.RoleParent {
+		return fmt.Errorf("user %s is not a parent", link.ParentUsername)
+	}
+
+	var studentID string
+	err = tx.QueryRowContext(ctx, "SELECT id FROM students WHERE email = $1 AND archived_at IS NULL", link.StudentEmail).Scan(&studentID)
+	if err != nil {
+		return notFound(err, "student")
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO parent_student (parent_id, student_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", parentID, studentID)
+	if err != nil {
+		log.Printf("Error associating parent with student: %v", err)
+		return fmt.Errorf("failed to associate parent with student: %w", err)
+	}
+	return nil
+}
+
+// auditCreated records the creation of an entity inserted within tx
+func auditCreated(ctx context.Context, tx *sql.Tx, entityType, id string) error {
+	after, err := rowJSON(ctx, tx, entityType, id)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, models.AuditCreate, entityType, id, nil, after)
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// Test that a failing row is rolled back to its savepoint, the remaining rows
+// are still checked and nothing is committed
+func TestImportRepository_Import(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	student := func(email string) models.Student {
+		return models.Student{Name: "Ann", Email: email, DateOfBirth: time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), Grade: "5", IsActive: true}
+	}
+	batch := &models.ImportBatch{
+		Students: []models.ImportStudent{
+			{Line: 2, Student: student("taken@example.com")},
+			{Line: 3, Student: student("new@example.com")},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM students WHERE email = \$1`).
+		WithArgs("taken@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM students WHERE email = \$1`).
+		WithArgs("new@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(`INSERT INTO students`).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRowJSON(mock, "students", sqlmock.AnyArg(), `{"email": "new@example.com"}`)
+	expectAuditEntry(mock, models.AuditCreate, "student", sqlmock.AnyArg())
+	mock.ExpectExec(`RELEASE SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = store.Import.Import(context.Background(), batch, false)
+	var rowErrors models.ImportErrors
+	require.ErrorAs(t, err, &rowErrors)
+	assert.Equal(t, models.ImportErrors{{File: "students", Line: 2, Message: "student email already exists"}}, rowErrors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
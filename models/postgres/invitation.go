@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// InvitationRepository stores invitations in the invitations table
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+// Create persists a new invitation and returns its code. The code is only
+// available here; the database stores its hash.
+func (r *InvitationRepository) Create(ctx context.Context, i *models.Invitation) (string, error) {
+	if err := i.Validate(); err != nil {
+		return "", err
+	}
+
+	code, hash, err := models.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	i.CreatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `INSERT INTO invitations
+			(id, code_hash, email, role, expires_at, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err = tx.ExecContext(ctx, query, i.ID, hash, i.Email, i.Role, i.ExpiresAt, i.CreatedBy, i.CreatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return "", fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	for _, studentID := range i.StudentIDs {
+		_, err = tx.ExecContext(ctx, "INSERT INTO invitation_students (invitation_id, student_id) VALUES ($1, $2)", i.ID, studentID)
+		if err != nil {
+			log.Printf("Error linking invitation to student: %v", err)
+			return "", fmt.Errorf("failed to link invitation to student: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully created invitation with ID: %s", i.ID)
+	return code, nil
+}
+
+// Revoke marks an unredeemed invitation as revoked
+func (r *InvitationRepository) Revoke(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("invitation ID is required")
+	}
+
+	query := "UPDATE invitations SET revoked_at = $1 WHERE id = $2 AND redeemed_at IS NULL AND revoked_at IS NULL"
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("invitation not found or already used")
+	}
+
+	log.Printf("Successfully revoked invitation with ID: %s", id)
+	return nil
+}
+
+// GetByID retrieves an invitation by its ID
+func (r *InvitationRepository) GetByID(ctx context.Context, id string) (*models.Invitation, error) {
+	query := `SELECT id, email, role, expires_at, created_by, created_at, redeemed_at, redeemed_by, revoked_at
+			FROM invitations WHERE id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var invitation models.Invitation
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.ExpiresAt,
+		&invitation.CreatedBy,
+		&invitation.CreatedAt,
+		&invitation.RedeemedAt,
+		&invitation.RedeemedBy,
+		&invitation.RevokedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "invitation")
+	}
+
+	invitation.StudentIDs, err = getInvitationStudentIDs(ctx, r.db, invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// List retrieves all invitations, newest first
+func (r *InvitationRepository) List(ctx context.Context) ([]models.Invitation, error) {
+	query := `SELECT id, email, role, expires_at, created_by, created_at, redeemed_at, redeemed_by, revoked_at
+			FROM invitations ORDER BY created_at DESC`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []models.Invitation
+	for rows.Next() {
+		var invitation models.Invitation
+		err := rows.Scan(
+			&invitation.ID,
+			&invitation.Email,
+			&invitation.Role,
+			&invitation.ExpiresAt,
+			&invitation.CreatedBy,
+			&invitation.CreatedAt,
+			&invitation.RedeemedAt,
+			&invitation.RedeemedBy,
+			&invitation.RevokedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan invitation row: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating invitation rows: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// Redeem creates the user described by the invitation code. The role, email
+// and student links come from the invitation; the invitee only chooses
+// username, password and name. The invitation is consumed in the same
+// transaction, so a code can never create more than one account.
+func (r *InvitationRepository) Redeem(ctx context.Context, code string, user *models.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `SELECT id, email, role, expires_at, redeemed_at, revoked_at
+			FROM invitations WHERE code_hash = $1 FOR UPDATE`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var invitation models.Invitation
+	err = tx.QueryRowContext(ctx, query, models.HashSecret(code)).Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.ExpiresAt,
+		&invitation.RedeemedAt,
+		&invitation.RevokedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("invitation %w", models.ErrNotFound)
+		return err
+	}
+	if err != nil {
+		log.Printf("Error scanning row: %v", err)
+		return fmt.Errorf("failed to scan invitation row: %w", err)
+	}
+
+	if !invitation.Redeemable(time.Now()) {
+		err = models.ErrInvitationInvalid
+		return err
+	}
+
+	studentIDs, err := getInvitationStudentIDs(ctx, tx, invitation.ID)
+	if err != nil {
+		return err
+	}
+
+	user.Email = invitation.Email
+	user.Role = invitation.Role
+	user.StudentIDs = studentIDs
+
+	if err = insertUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	if err = auditCreated(ctx, tx, models.AuditEntityUser, user.ID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE invitations SET redeemed_at = $1, redeemed_by = $2 WHERE id = $3", time.Now(), user.ID, invitation.ID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Invitation %s redeemed by user %s", invitation.ID, user.ID)
+	return nil
+}
+
+// getInvitationStudentIDs returns the students linked to an invitation
+func getInvitationStudentIDs(ctx context.Context, q queryer, invitationID string) ([]string, error) {
+	return queryIDs(ctx, q, "SELECT student_id FROM invitation_students WHERE invitation_id = $1", invitationID)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// JobRepository stores the last run of each background job in job_runs
+type JobRepository struct {
+	db *sql.DB
+}
+
+// SaveRun records a run, replacing the previous run of the same job
+func (r *JobRepository) SaveRun(ctx context.Context, run *models.JobRun) error {
+	query := `INSERT INTO job_runs (name, status, result, error, instance, started_at, finished_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (name) DO UPDATE SET status = EXCLUDED.status, result = EXCLUDED.result, error = EXCLUDED.error,
+			instance = EXCLUDED.instance, started_at = EXCLUDED.started_at, finished_at = EXCLUDED.finished_at`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err := r.db.ExecContext(ctx, query, run.Name, run.Status, run.Result, run.Error, run.Instance, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to save job run: %w", err)
+	}
+	return nil
+}
+
+// GetRun returns the last run of a job
+func (r *JobRepository) GetRun(ctx context.Context, name string) (*models.JobRun, error) {
+	query := `SELECT name, status, result, error, instance, started_at, finished_at FROM job_runs WHERE name = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var run models.JobRun
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&run.Name, &run.Status, &run.Result, &run.Error, &run.Instance, &run.StartedAt, &run.FinishedAt)
+	if err != nil {
+		return nil, notFound(err, "job run")
+	}
+	return &run, nil
+}
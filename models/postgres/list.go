@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/lib/pq"
+)
+
+// likeEscaper escapes the LIKE wildcards in a contains filter
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// listQuery builds the SQL for one page of a listing from ListOptions.
+// columns maps the fields of the listing's ListSpec, and "id", to columns.
+type listQuery struct {
+	from    string
+	columns map[string]string
+	opts    models.ListOptions
+	conds   []string
+	args    []interface{}
+}
+
+// newListQuery returns the listing of the given FROM clause filtered by the
+// conditions and IDs of opts
+func newListQuery(from string, columns map[string]string, opts models.ListOptions) *listQuery {
+	q := &listQuery{from: from, columns: columns, opts: opts}
+
+	for _, cond := range opts.Conditions {
+		column := columns[cond.Field]
+		switch cond.Op {
+		case models.OpContains:
+			q.conds = append(q.conds, fmt.Sprintf("%s ILIKE '%%' || %s || '%%'", column, q.arg(likeEscaper.Replace(fmt.Sprint(cond.Value)))))
+		case models.OpLt:
+			q.conds = append(q.conds, fmt.Sprintf("%s < %s", column, q.arg(cond.Value)))
+		case models.OpLte:
+			q.conds = append(q.conds, fmt.Sprintf("%s <= %s", column, q.arg(cond.Value)))
+		case models.OpGt:
+			q.conds = append(q.conds, fmt.Sprintf("%s > %s", column, q.arg(cond.Value)))
+		case models.OpGte:
+			q.conds = append(q.conds, fmt.Sprintf("%s >= %s", column, q.arg(cond.Value)))
+		default:
+			q.conds = append(q.conds, fmt.Sprintf("%s = %s", column, q.arg(cond.Value)))
+		}
+	}
+
+	if opts.IDs != nil {
+		q.conds = append(q.conds, fmt.Sprintf("%s = ANY(%s)", columns["id"], q.arg(pq.Array(opts.IDs))))
+	}
+
+	return q
+}
+
+// where adds a condition that always applies; each %s in cond is replaced
+// by the placeholder of the matching value
+func (q *listQuery) where(cond string, values ...interface{}) *listQuery {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = q.arg(value)
+	}
+	q.conds = append(q.conds, fmt.Sprintf(cond, placeholders...))
+	return q
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *listQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// count returns the number of rows matching the listing across all pages
+func (q *listQuery) count(ctx context.Context, db *sql.DB) (int, error) {
+	query := "SELECT COUNT(*) FROM " + q.from + whereClause(q.conds)
+	log.Printf("Executing SELECT query: %s", query)
+
+	var total int
+	if err := db.QueryRowContext(ctx, query, q.args...).Scan(&total); err != nil {
+		log.Printf("Error counting rows: %v", err)
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return total, nil
+}
+
+// page returns the query selecting the given columns for the requested
+// page, with its arguments. It fetches one row more than the limit so
+// models.NewPage can tell whether another page follows.
+func (q *listQuery) page(columns string) (string, []interface{}) {
+	p := &listQuery{
+		conds: append([]string(nil), q.conds...),
+		args:  append([]interface{}(nil), q.args...),
+	}
+
+	sortColumn := q.columns[q.opts.Sort]
+	idColumn := q.columns["id"]
+	direction, compare := "ASC", ">"
+	if q.opts.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if q.opts.After != nil {
+		p.conds = append(p.conds, fmt.Sprintf("(%s, %s) %s (%s, %s)",
+			sortColumn, idColumn, compare, p.arg(q.opts.After.Value), p.arg(q.opts.After.ID)))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s %s, %s %s",
+		columns, q.from, whereClause(p.conds), sortColumn, direction, idColumn, direction)
+	if q.opts.Limit > 0 {
+		query += " LIMIT " + p.arg(q.opts.Limit+1)
+	}
+
+	return query, p.args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
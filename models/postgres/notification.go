@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// NotificationRepository stores inboxes in notifications and the delivery
+// choices of users in notification_preferences
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// notificationColumns maps the fields of models.NotificationListSpec to columns
+var notificationColumns = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+	"kind":       "kind",
+	"student_id": "student_id",
+	"read":       "(read_at IS NOT NULL)",
+}
+
+// Create stores a notification unless the user already has one of the same
+// kind for the same entity
+func (r *NotificationRepository) Create(ctx context.Context, n *models.Notification) (bool, error) {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	n.CreatedAt = time.Now()
+
+	query := `INSERT INTO notifications (id, user_id, student_id, kind, title, body, entity_type, entity_id, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (user_id, kind, entity_id) DO NOTHING`
+	log.Printf("Executing INSERT query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, n.ID, n.UserID, n.StudentID, n.Kind, n.Title, n.Body, n.EntityType, n.EntityID, n.CreatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return false, fmt.Errorf("failed to execute insert query: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// List returns a page of the notifications of a user
+func (r *NotificationRepository) List(ctx context.Context, userID string, opts models.ListOptions) (models.Page[models.Notification], error) {
+	opts = opts.WithDefaultSort(models.NotificationListSpec)
+	list := newListQuery("notifications", notificationColumns, opts).where("user_id = %s", userID)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.Notification]{}, err
+	}
+
+	query, args := list.page("id, user_id, student_id, kind, title, body, entity_type, entity_id, read_at, created_at")
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return models.Page[models.Notification]{}, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(&n.ID, &n.UserID, &n.StudentID, &n.Kind, &n.Title, &n.Body, &n.EntityType, &n.EntityID, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return models.Page[models.Notification]{}, fmt.Errorf("failed to scan notification row: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return models.Page[models.Notification]{}, fmt.Errorf("error iterating notification rows: %w", err)
+	}
+
+	return models.NewPage(notifications, opts, total), nil
+}
+
+// MarkRead marks a notification of the user as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	query := "UPDATE notifications SET read_at = COALESCE(read_at, $1) WHERE id = $2 AND user_id = $3"
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id, userID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("notification %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	query := "UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL"
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return 0, fmt.Errorf("failed to execute update query: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return rows, nil
+}
+
+// GetPreferences returns the preferences of a user, or the defaults
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	query := "SELECT user_id, email, webhook, webhook_url, updated_at FROM notification_preferences WHERE user_id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var p models.NotificationPreferences
+	var email, webhook []string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&p.UserID, pq.Array(&email), pq.Array(&webhook), &p.WebhookURL, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to read notification preferences: %w", err)
+	}
+	p.Email = notificationKinds(email)
+	p.Webhook = notificationKinds(webhook)
+	return &p, nil
+}
+
+// SavePreferences replaces the preferences of a user
+func (r *NotificationRepository) SavePreferences(ctx context.Context, p *models.NotificationPreferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	p.UpdatedAt = time.Now()
+
+	query := `INSERT INTO notification_preferences (user_id, email, webhook, webhook_url, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email, webhook = EXCLUDED.webhook,
+			webhook_url = EXCLUDED.webhook_url, updated_at = EXCLUDED.updated_at`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err := r.db.ExecContext(ctx, query, p.UserID, pq.Array(kindStrings(p.Email)), pq.Array(kindStrings(p.Webhook)), p.WebhookURL, p.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// notificationKinds converts the kinds read from a TEXT[] column
+func notificationKinds(values []string) []models.NotificationKind {
+	kinds := make([]models.NotificationKind, len(values))
+	for i, value := range values {
+		kinds[i] = models.NotificationKind(value)
+	}
+	return kinds
+}
+
+// kindStrings converts kinds for a TEXT[] column
+func kindStrings(kinds []models.NotificationKind) []string {
+	values := make([]string, len(kinds))
+	for i, kind := range kinds {
+		values[i] = string(kind)
+	}
+	return values
+}
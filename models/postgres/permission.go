@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/lib/pq"
+)
+
+// PermissionRepository stores permissions and role grants in the
+// permissions and role_permissions tables
+type PermissionRepository struct {
+	db *sql.DB
+}
+
+// List retrieves every known permission
+func (r *PermissionRepository) List(ctx context.Context) ([]models.Permission, error) {
+	query := "SELECT name, description FROM permissions ORDER BY name"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var permission models.Permission
+		if err := rows.Scan(&permission.Name, &permission.Description); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan permission row: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating permission rows: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// GetRolePermissions retrieves the permissions granted to each role
+func (r *PermissionRepository) GetRolePermissions(ctx context.Context) (map[models.UserRole][]string, error) {
+	query := "SELECT role, permission FROM role_permissions ORDER BY role, permission"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	grants := make(map[models.UserRole][]string)
+	for rows.Next() {
+		var role models.UserRole
+		var permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan role permission row: %w", err)
+		}
+		grants[role] = append(grants[role], permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating role permission rows: %w", err)
+	}
+
+	return grants, nil
+}
+
+// SetRolePermissions replaces the permissions granted to a role
+func (r *PermissionRepository) SetRolePermissions(ctx context.Context, role models.UserRole, permissions []string) error {
+	if !role.IsValid() {
+		return fmt.Errorf("invalid user role: %s", role)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	// Reject the whole update if any name is unknown, rather than relying on
+	// the foreign key to fail halfway through the inserts
+	var known int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM permissions WHERE name = ANY($1)", pq.Array(permissions)).Scan(&known)
+	if err != nil {
+		log.Printf("Error checking permission names: %v", err)
+		return fmt.Errorf("failed to check permission names: %w", err)
+	}
+	if known != len(models.UniqueStrings(permissions)) {
+		err = models.ErrUnknownPermission
+		return err
+	}
+
+	query := "DELETE FROM role_permissions WHERE role = $1"
+	log.Printf("Executing DELETE query: %s", query)
+
+	if _, err = tx.ExecContext(ctx, query, role); err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	for _, permission := range models.UniqueStrings(permissions) {
+		_, err = tx.ExecContext(ctx, "INSERT INTO role_permissions (role, permission) VALUES ($1, $2)", role, permission)
+		if err != nil {
+			log.Printf("Error granting permission: %v", err)
+			return fmt.Errorf("failed to grant permission: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Updated permissions of role %s", role)
+	return nil
+}
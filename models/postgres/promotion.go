@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PromotionRepository applies promotions to students and keeps their
+// grade-level history in student_grade_history
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+// Apply updates the students and writes their history in one transaction.
+// Each student change is audited like an update through StudentRepository.
+func (r *PromotionRepository) Apply(ctx context.Context, changes []models.GradeChange, recordedBy string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	// The student must still be active in the grade level the change was
+	// planned from
+	update := `UPDATE students SET grade = $1, is_active = is_active AND NOT $2
+			WHERE id = $3 AND grade = $4 AND is_active AND archived_at IS NULL`
+	insert := `INSERT INTO student_grade_history
+			(id, student_id, from_grade, to_grade, action, effective_date, recorded_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)`
+	log.Printf("Applying %d promotions", len(changes))
+
+	now := time.Now()
+	for i := range changes {
+		change := &changes[i]
+		if change.Action == models.PromotionSkip {
+			continue
+		}
+
+		var before, after json.RawMessage
+		if before, err = rowJSON(ctx, tx, models.AuditEntityStudent, change.StudentID); err != nil {
+			return err
+		}
+
+		graduated := change.Action == models.PromotionGraduate
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, update, change.ToGrade, graduated, change.StudentID, change.FromGrade)
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to update student %s: %w", change.StudentID, err)
+		}
+		var rows int64
+		if rows, err = result.RowsAffected(); err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("student %s: %w", change.StudentID, models.ErrPromotionConflict)
+		}
+
+		if change.ID == "" {
+			change.ID = uuid.New().String()
+		}
+		change.RecordedBy = recordedBy
+		change.CreatedAt = now
+		change.EffectiveDate = models.Day(change.EffectiveDate)
+		_, err = tx.ExecContext(ctx, insert, change.ID, change.StudentID, change.FromGrade, change.ToGrade, change.Action, change.EffectiveDate, recordedBy, now)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return fmt.Errorf("student %s: %w", change.StudentID, models.ErrPromotionConflict)
+			}
+			log.Printf("Error executing INSERT: %v", err)
+			return fmt.Errorf("failed to record grade change of student %s: %w", change.StudentID, err)
+		}
+
+		// Students held back keep the same row; only the history records them
+		if change.Action == models.PromotionHold {
+			continue
+		}
+		if after, err = rowJSON(ctx, tx, models.AuditEntityStudent, change.StudentID); err != nil {
+			return err
+		}
+		if err = recordAudit(ctx, tx, models.AuditUpdate, models.AuditEntityStudent, change.StudentID, before, after); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully applied %d promotions", len(changes))
+	return nil
+}
+
+// ListHistory returns the grade-level history of a student
+func (r *PromotionRepository) ListHistory(ctx context.Context, studentID string) ([]models.GradeChange, error) {
+	query := `SELECT id, student_id, from_grade, to_grade, action, effective_date, COALESCE(recorded_by, ''), created_at
+			FROM student_grade_history WHERE student_id = $1 ORDER BY effective_date`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var history []models.GradeChange
+	for rows.Next() {
+		var change models.GradeChange
+		err := rows.Scan(
+			&change.ID,
+			&change.StudentID,
+			&change.FromGrade,
+			&change.ToGrade,
+			&change.Action,
+			&change.EffectiveDate,
+			&change.RecordedBy,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan grade history row: %w", err)
+		}
+		change.EffectiveDate = models.Day(change.EffectiveDate)
+		history = append(history, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating grade history rows: %w", err)
+	}
+
+	return history, nil
+}
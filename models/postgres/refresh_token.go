@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// RefreshTokenRepository stores refresh tokens in the refresh_tokens table
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// insertRefreshToken creates a token row in the given family and returns the token value
+func insertRefreshToken(ctx context.Context, tx *sql.Tx, userID, familyID string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	value, hash, err := models.GenerateSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	token := &models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	query := `INSERT INTO refresh_tokens
+			(id, user_id, family_id, token_hash, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err = tx.ExecContext(ctx, query, token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return "", nil, fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	return value, token, nil
+}
+
+// Issue starts a new refresh token family for a user and returns the token value
+func (r *RefreshTokenRepository) Issue(ctx context.Context, userID string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	if userID == "" {
+		return "", nil, errors.New("user ID is required")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	value, token, err := insertRefreshToken(ctx, tx, userID, uuid.New().String(), ttl)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+		return "", nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Issued refresh token for user with ID: %s", userID)
+	return value, token, nil
+}
+
+// Rotate exchanges a valid refresh token for a new one in the same family.
+// Presenting a token that was already rotated revokes the whole family and
+// returns models.ErrRefreshTokenReused.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, value string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, replaced_by
+			FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var current models.RefreshToken
+	err = tx.QueryRowContext(ctx, query, models.HashSecret(value)).Scan(
+		&current.ID,
+		&current.UserID,
+		&current.FamilyID,
+		&current.TokenHash,
+		&current.ExpiresAt,
+		&current.CreatedAt,
+		&current.RevokedAt,
+		&current.ReplacedBy,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil, models.ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		log.Printf("Error scanning row: %v", err)
+		return "", nil, fmt.Errorf("failed to scan refresh token row: %w", err)
+	}
+
+	now := time.Now()
+
+	if current.RevokedAt != nil {
+		if current.ReplacedBy == nil {
+			// Revoked by logout rather than rotation
+			return "", nil, models.ErrRefreshTokenInvalid
+		}
+
+		// A rotated token was presented again, so it has leaked. Revoke
+		// every token in the family, including the legitimate successor.
+		_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL", now, current.FamilyID)
+		if err != nil {
+			log.Printf("Error revoking refresh token family: %v", err)
+			return "", nil, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		if err = tx.Commit(); err != nil {
+			log.Printf("Error committing transaction: %v", err)
+			return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		committed = true
+
+		log.Printf("Refresh token reuse detected for user %s, revoked family %s", current.UserID, current.FamilyID)
+		return "", nil, models.ErrRefreshTokenReused
+	}
+
+	if now.After(current.ExpiresAt) {
+		return "", nil, models.ErrRefreshTokenInvalid
+	}
+
+	newValue, next, err := insertRefreshToken(ctx, tx, current.UserID, current.FamilyID, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = $1, replaced_by = $2 WHERE id = $3", now, next.ID, current.ID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return "", nil, fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	log.Printf("Rotated refresh token for user with ID: %s", current.UserID)
+	return newValue, next, nil
+}
+
+// Revoke revokes the session the given refresh token belongs to
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, value string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1
+			WHERE revoked_at IS NULL AND family_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $2)`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), models.HashSecret(value))
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return models.ErrRefreshTokenInvalid
+	}
+
+	return nil
+}
+
+// RevokeAll revokes every active refresh token of a user and
+// returns the number of tokens revoked
+func (r *RefreshTokenRepository) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	query := "UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL"
+	log.Printf("Executing UPDATE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return 0, fmt.Errorf("failed to execute update query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	log.Printf("Revoked %d refresh tokens for user with ID: %s", rows, userID)
+	return rows, nil
+}
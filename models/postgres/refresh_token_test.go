@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+var refreshTokenColumns = []string{"id", "user_id", "family_id", "token_hash", "expires_at", "created_at", "revoked_at", "replaced_by"}
+
+// Test rotating a valid refresh token
+func TestRefreshTokenRepository_Rotate(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	hash := models.HashSecret("current")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+		WithArgs(hash).
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow("t1", "u1", "f1", hash, time.Now().Add(time.Hour), time.Now(), nil, nil))
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), "u1", "f1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = \$1, replaced_by = \$2 WHERE id = \$3`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "t1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	value, next, err := store.RefreshTokens.Rotate(context.Background(), "current", time.Hour)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, value)
+	assert.NotEqual(t, "current", value)
+	assert.Equal(t, "u1", next.UserID)
+	assert.Equal(t, "f1", next.FamilyID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test that presenting a rotated refresh token revokes the whole family
+func TestRefreshTokenRepository_Rotate_ReuseRevokesFamily(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	hash := models.HashSecret("rotated")
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+		WithArgs(hash).
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow("t1", "u1", "f1", hash, time.Now().Add(time.Hour), time.Now(), time.Now(), "t2"))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at = \$1 WHERE family_id = \$2 AND revoked_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), "f1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, _, err = store.RefreshTokens.Rotate(context.Background(), "rotated", time.Hour)
+	assert.ErrorIs(t, err, models.ErrRefreshTokenReused)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test rotating an unknown refresh token
+func TestRefreshTokenRepository_Rotate_Unknown(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash = \$1 FOR UPDATE`).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, _, err = store.RefreshTokens.Rotate(context.Background(), "unknown", time.Hour)
+	assert.ErrorIs(t, err, models.ErrRefreshTokenInvalid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
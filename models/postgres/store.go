@@ -0,0 +1,79 @@
+// Package postgres implements the models repositories on PostgreSQL
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/reporting"
+)
+
+// NewStore returns a store whose repositories use the given database
+func NewStore(db *sql.DB) *models.Store {
+	return &models.Store{
+		Students:      &StudentRepository{db: db},
+		Users:         &UserRepository{db: db},
+		RefreshTokens: &RefreshTokenRepository{db: db},
+		Invitations:   &InvitationRepository{db: db},
+		Permissions:   &PermissionRepository{db: db},
+		Classes:       &ClassRepository{db: db},
+		Grades:        &GradeRepository{db: db},
+		Gradebook:     &GradebookRepository{db: db},
+		Attendance:    &AttendanceRepository{db: db},
+		Forum:         &ForumRepository{db: db},
+		Audit:         &AuditRepository{db: db},
+		Import:        &ImportRepository{db: db},
+		Reports:       reporting.NewPostgres(db),
+		Terms:         &TermRepository{db: db},
+		Promotions:    &PromotionRepository{db: db},
+		Submissions:   &SubmissionRepository{db: db},
+		Jobs:          &JobRepository{db: db},
+		Notifications: &NotificationRepository{db: db},
+	}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryIDs runs a query returning a single string column
+func queryIDs(ctx context.Context, q queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Error scanning ID: %v", err)
+			return nil, fmt.Errorf("failed to scan ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating ID rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// notFound converts sql.ErrNoRows into models.ErrNotFound and wraps other
+// scan errors
+func notFound(err error, what string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s %w", what, models.ErrNotFound)
+	}
+	log.Printf("Error scanning row: %v", err)
+	return fmt.Errorf("failed to scan %s row: %w", what, err)
+}
@@ -0,0 +1,350 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// StudentRepository stores students in the students table
+type StudentRepository struct {
+	db *sql.DB
+}
+
+func (r *StudentRepository) Create(ctx context.Context, s *models.Student) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	// Generate UUID if ID is empty
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+
+	err := audited(ctx, r.db, models.AuditCreate, models.AuditEntityStudent, s.ID, func(tx *sql.Tx) error {
+		return insertStudent(ctx, tx, s)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully created student with ID: %s", s.ID)
+	return nil
+}
+
+// insertStudent inserts a validated student with an ID within tx
+func insertStudent(ctx context.Context, tx *sql.Tx, s *models.Student) error {
+	if s.EnrollmentDate.IsZero() {
+		s.EnrollmentDate = time.Now()
+	}
+	s.Age = models.AgeOn(s.DateOfBirth, time.Now())
+
+	query := `INSERT INTO students (id, name, email, date_of_birth, grade, enrollment_date, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	log.Printf("Executing INSERT query: %s with values: [%s, %s, %s, %s]", query, s.ID, s.Name, s.Email, s.Grade)
+
+	result, err := tx.ExecContext(ctx, query, s.ID, s.Name, s.Email, s.DateOfBirth, s.Grade, s.EnrollmentDate, s.IsActive)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return errors.New("failed to create student: no rows affected")
+	}
+	return nil
+}
+
+// studentFields are the columns scanned by scanStudent
+const studentFields = "id, name, email, date_of_birth, grade, enrollment_date, is_active, archived_at, COALESCE(archived_by, '')"
+
+// studentColumns maps the fields of models.StudentListSpec to columns. Age
+// is computed from the date of birth like models.AgeOn does.
+var studentColumns = map[string]string{
+	"id":              "id",
+	"name":            "name",
+	"email":           "email",
+	"age":             "DATE_PART('year', AGE(date_of_birth))::INTEGER",
+	"grade":           "grade",
+	"enrollment_date": "enrollment_date",
+	"is_active":       "is_active",
+}
+
+// scanStudent reads a row of studentFields and derives the student's age
+func scanStudent(row interface{ Scan(...interface{}) error }) (models.Student, error) {
+	var student models.Student
+	err := row.Scan(
+		&student.ID,
+		&student.Name,
+		&student.Email,
+		&student.DateOfBirth,
+		&student.Grade,
+		&student.EnrollmentDate,
+		&student.IsActive,
+		&student.ArchivedAt,
+		&student.ArchivedBy,
+	)
+	student.Age = models.AgeOn(student.DateOfBirth, time.Now())
+	return student, err
+}
+
+func (r *StudentRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.Student], error) {
+	return r.list(ctx, opts, "archived_at IS NULL")
+}
+
+// ListArchived retrieves a page of archived students
+func (r *StudentRepository) ListArchived(ctx context.Context, opts models.ListOptions) (models.Page[models.Student], error) {
+	return r.list(ctx, opts, "archived_at IS NOT NULL")
+}
+
+// list retrieves a page of the students matching the archive condition
+func (r *StudentRepository) list(ctx context.Context, opts models.ListOptions, archived string) (models.Page[models.Student], error) {
+	opts = opts.WithDefaultSort(models.StudentListSpec)
+	list := newListQuery("students", studentColumns, opts).where(archived)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.Student]{}, err
+	}
+
+	query, args := list.page(studentFields)
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return models.Page[models.Student]{}, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		student, err := scanStudent(rows)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return models.Page[models.Student]{}, fmt.Errorf("failed to scan student row: %w", err)
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return models.Page[models.Student]{}, fmt.Errorf("error iterating student rows: %w", err)
+	}
+
+	return models.NewPage(students, opts, total), nil
+}
+
+func (r *StudentRepository) GetByID(ctx context.Context, id string) (*models.Student, error) {
+	query := "SELECT " + studentFields + " FROM students WHERE id = $1 AND archived_at IS NULL"
+	log.Printf("Executing SELECT query: %s with value: %s", query, id)
+
+	student, err := scanStudent(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err, "student")
+	}
+
+	log.Printf("Successfully retrieved student with ID: %s", student.ID)
+	return &student, nil
+}
+
+// ListByIDs retrieves the students with the given IDs
+func (r *StudentRepository) ListByIDs(ctx context.Context, ids []string) ([]models.Student, error) {
+	if len(ids) == 0 {
+		return []models.Student{}, nil
+	}
+
+	query := "SELECT " + studentFields + " FROM students WHERE id = ANY($1) AND archived_at IS NULL"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var students []models.Student
+	for rows.Next() {
+		student, err := scanStudent(rows)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan student row: %w", err)
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating student rows: %w", err)
+	}
+
+	return students, nil
+}
+
+func (r *StudentRepository) Update(ctx context.Context, s *models.Student) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	query := `UPDATE students SET name = $1, email = $2, date_of_birth = $3, grade = $4,
+			enrollment_date = COALESCE($5, enrollment_date), is_active = $6 WHERE id = $7 AND archived_at IS NULL`
+	log.Printf("Executing UPDATE query: %s with values: [%s, %s, %s, %t, %s]", query, s.Name, s.Email, s.Grade, s.IsActive, s.ID)
+
+	var enrollmentDate interface{}
+	if !s.EnrollmentDate.IsZero() {
+		enrollmentDate = s.EnrollmentDate
+	}
+	err := audited(ctx, r.db, models.AuditUpdate, models.AuditEntityStudent, s.ID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, s.Name, s.Email, s.DateOfBirth, s.Grade, enrollmentDate, s.IsActive, s.ID)
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to execute update query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("student %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Age = models.AgeOn(s.DateOfBirth, time.Now())
+	log.Printf("Successfully updated student with ID: %s", s.ID)
+	return nil
+}
+
+// Archive deactivates a student and hides it from the other queries
+func (r *StudentRepository) Archive(ctx context.Context, id, archivedBy string) error {
+	if id == "" {
+		return errors.New("student ID is required")
+	}
+
+	query := `UPDATE students SET is_active = false, archived_at = CURRENT_TIMESTAMP, archived_by = $2
+			WHERE id = $1 AND archived_at IS NULL`
+	log.Printf("Executing UPDATE query: %s with values: [%s, %s]", query, id, archivedBy)
+
+	err := audited(ctx, r.db, models.AuditArchive, models.AuditEntityStudent, id, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, id, archivedBy)
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to execute update query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("student %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully archived student with ID: %s", id)
+	return nil
+}
+
+// Restore reactivates an archived student
+func (r *StudentRepository) Restore(ctx context.Context, id string) error {
+	query := `UPDATE students SET is_active = true, archived_at = NULL, archived_by = NULL
+			WHERE id = $1 AND archived_at IS NOT NULL`
+	log.Printf("Executing UPDATE query: %s with value: %s", query, id)
+
+	err := audited(ctx, r.db, models.AuditRestore, models.AuditEntityStudent, id, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to execute update query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("archived student %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully restored student with ID: %s", id)
+	return nil
+}
+
+// Purge deletes a student archived before the given time. The schema
+// cascades the deletion to the student's attendance, grades, forum posts,
+// parent links and enrollments, recording the deleted grades and attendance
+// in the audit log.
+func (r *StudentRepository) Purge(ctx context.Context, id string, archivedBefore time.Time) error {
+	var archivedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, "SELECT archived_at FROM students WHERE id = $1", id).Scan(&archivedAt)
+	if err != nil {
+		return notFound(err, "student")
+	}
+	if !archivedAt.Valid || archivedAt.Time.After(archivedBefore) {
+		return models.ErrPurgeNotAllowed
+	}
+
+	// The archive condition is checked again in case the student was
+	// restored in the meantime
+	query := "DELETE FROM students WHERE id = $1 AND archived_at <= $2"
+	log.Printf("Executing DELETE query: %s with value: %s", query, id)
+
+	err = audited(ctx, r.db, models.AuditDelete, models.AuditEntityStudent, id, func(tx *sql.Tx) error {
+		if err := auditCascade(ctx, tx, models.AuditEntityGrade, "SELECT id FROM grades WHERE student_id = $1 ORDER BY id", id); err != nil {
+			return err
+		}
+		if err := auditCascade(ctx, tx, models.AuditEntityAttendance, "SELECT id FROM attendance WHERE student_id = $1 ORDER BY id", id); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, query, id, archivedBefore)
+		if err != nil {
+			log.Printf("Error executing DELETE: %v", err)
+			return fmt.Errorf("failed to execute delete query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return models.ErrPurgeNotAllowed
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully purged student with ID: %s", id)
+	return nil
+}
@@ -0,0 +1,298 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// studentRowColumns are the columns of a student row
+var studentRowColumns = []string{"id", "name", "email", "date_of_birth", "grade", "enrollment_date", "is_active", "archived_at", "archived_by"}
+
+// Test Create Method
+func TestStudentRepository_Create(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	student := &models.Student{
+		ID:             "1",
+		Name:           "John Doe",
+		Email:          "john@example.com",
+		DateOfBirth:    time.Date(2006, 3, 2, 0, 0, 0, 0, time.UTC),
+		Grade:          "A+",
+		EnrollmentDate: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+		IsActive:       true,
+	}
+
+	// Successful Insert
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", "")
+	mock.ExpectExec(`INSERT INTO students \(id, name, email, date_of_birth, grade, enrollment_date, is_active\)`).
+		WithArgs(student.ID, student.Name, student.Email, student.DateOfBirth, student.Grade, student.EnrollmentDate, true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRowJSON(mock, "students", "1", `{"id": "1"}`)
+	expectAuditEntry(mock, models.AuditCreate, "student", "1")
+	mock.ExpectCommit()
+
+	err = store.Students.Create(context.Background(), student)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AgeOn(student.DateOfBirth, time.Now()), student.Age)
+
+	// Failed Insert
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", "")
+	mock.ExpectExec(`INSERT INTO students \(id, name, email, date_of_birth, grade, enrollment_date, is_active\)`).
+		WithArgs(student.ID, student.Name, student.Email, student.DateOfBirth, student.Grade, student.EnrollmentDate, true).
+		WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
+
+	err = store.Students.Create(context.Background(), student)
+	assert.Error(t, err)
+	assert.Equal(t, "failed to execute insert query: insert error", err.Error())
+}
+
+// Test List Method
+func TestStudentRepository_List(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	rows := sqlmock.NewRows(studentRowColumns).
+		AddRow("1", "John Doe", "john@example.com", time.Date(2006, 3, 2, 0, 0, 0, 0, time.UTC), "A+", time.Now(), true, nil, "").
+		AddRow("2", "Jane Doe", "jane@example.com", time.Date(2004, 5, 6, 0, 0, 0, 0, time.UTC), "A", time.Now(), true, nil, "")
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM students`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT id, name, email, date_of_birth, grade, enrollment_date, is_active, archived_at, COALESCE\(archived_by, ''\) FROM students WHERE archived_at IS NULL ORDER BY name ASC, id ASC`).
+		WillReturnRows(rows)
+
+	page, err := store.Students.List(context.Background(), models.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, 2, page.Total)
+	assert.Empty(t, page.NextCursor)
+}
+
+// Test List Method with a limit, filter and cursor
+func TestStudentRepository_ListPage(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	opts, err := models.ParseListOptions(url.Values{"limit": {"1"}, "sort": {"-grade"}, "name": {"doe_"}}, models.StudentListSpec)
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM students WHERE name ILIKE '%' \|\| \$1 \|\| '%'`).
+		WithArgs(`doe\_`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`FROM students WHERE name ILIKE '%' \|\| \$1 \|\| '%' AND archived_at IS NULL ORDER BY grade DESC, id DESC LIMIT \$2`).
+		WithArgs(`doe\_`, 2).
+		WillReturnRows(sqlmock.NewRows(studentRowColumns).
+			AddRow("2", "Jane Doe_", "jane@example.com", time.Date(2004, 5, 6, 0, 0, 0, 0, time.UTC), "B", time.Now(), true, nil, "").
+			AddRow("1", "John Doe_", "john@example.com", time.Date(2006, 3, 2, 0, 0, 0, 0, time.UTC), "A", time.Now(), true, nil, ""))
+
+	page, err := store.Students.List(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "2", page.Items[0].ID)
+	assert.Equal(t, 2, page.Total)
+	require.NotEmpty(t, page.NextCursor)
+
+	// The cursor continues after the last student of the first page
+	opts, err = models.ParseListOptions(url.Values{"limit": {"1"}, "sort": {"-grade"}, "name": {"doe_"}, "cursor": {page.NextCursor}}, models.StudentListSpec)
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM students`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`WHERE name ILIKE '%' \|\| \$1 \|\| '%' AND archived_at IS NULL AND \(grade, id\) < \(\$2, \$3\) ORDER BY grade DESC, id DESC LIMIT \$4`).
+		WithArgs(`doe\_`, "B", "2", 2).
+		WillReturnRows(sqlmock.NewRows(studentRowColumns).
+			AddRow("1", "John Doe_", "john@example.com", time.Date(2006, 3, 2, 0, 0, 0, 0, time.UTC), "A", time.Now(), true, nil, ""))
+
+	page, err = store.Students.List(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "1", page.Items[0].ID)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test GetByID Method
+func TestStudentRepository_GetByID(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	// Successful Fetch
+	row := sqlmock.NewRows(studentRowColumns).
+		AddRow("1", "John Doe", "john@example.com", time.Date(2006, 3, 2, 0, 0, 0, 0, time.UTC), "A+", time.Now(), true, nil, "")
+	mock.ExpectQuery(`FROM students WHERE id = \$1 AND archived_at IS NULL`).
+		WithArgs("1").
+		WillReturnRows(row)
+
+	student, err := store.Students.GetByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", student.Name)
+	assert.Equal(t, models.AgeOn(student.DateOfBirth, time.Now()), student.Age)
+
+	// Not Found
+	mock.ExpectQuery(`FROM students WHERE id = \$1`).
+		WithArgs("2").
+		WillReturnError(errors.New("not found"))
+
+	student, err = store.Students.GetByID(context.Background(), "2")
+	assert.Error(t, err)
+	assert.Nil(t, student)
+}
+
+// Test Update Method
+func TestStudentRepository_Update(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	student := &models.Student{
+		ID:          "1",
+		Name:        "Updated Name",
+		Email:       "john@example.com",
+		DateOfBirth: time.Date(2006, 3, 2, 0, 0, 0, 0, time.UTC),
+		Grade:       "A+",
+	}
+
+	// Successful Update; a zero enrollment date keeps the stored one
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", `{"id": "1"}`)
+	mock.ExpectExec(`UPDATE students SET name = \$1, email = \$2, date_of_birth = \$3, grade = \$4,\s+enrollment_date = COALESCE\(\$5, enrollment_date\), is_active = \$6 WHERE id = \$7`).
+		WithArgs(student.Name, student.Email, student.DateOfBirth, student.Grade, nil, false, student.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRowJSON(mock, "students", "1", `{"id": "1"}`)
+	expectAuditEntry(mock, models.AuditUpdate, "student", "1")
+	mock.ExpectCommit()
+
+	err = store.Students.Update(context.Background(), student)
+	assert.NoError(t, err)
+
+	// No Rows Affected (Student Not Found)
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", "")
+	mock.ExpectExec(`UPDATE students SET`).
+		WithArgs(student.Name, student.Email, student.DateOfBirth, student.Grade, nil, false, student.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = store.Students.Update(context.Background(), student)
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Equal(t, "student not found", err.Error())
+}
+
+// Test Archive Method
+func TestStudentRepository_Archive(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	// Successful Archive
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", `{"id": "1", "archived_at": null}`)
+	mock.ExpectExec(`UPDATE students SET is_active = false, archived_at = CURRENT_TIMESTAMP, archived_by = \$2\s+WHERE id = \$1 AND archived_at IS NULL`).
+		WithArgs("1", "staff-1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRowJSON(mock, "students", "1", `{"id": "1", "archived_at": "2026-10-17T12:00:00+00:00"}`)
+	expectAuditEntry(mock, models.AuditArchive, "student", "1")
+	mock.ExpectCommit()
+
+	err = store.Students.Archive(context.Background(), "1", "staff-1")
+	assert.NoError(t, err)
+
+	// Missing or already archived
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", "")
+	mock.ExpectExec(`UPDATE students SET is_active = false`).
+		WithArgs("1", "staff-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = store.Students.Archive(context.Background(), "1", "staff-1")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+	assert.Equal(t, "student not found", err.Error())
+}
+
+// Test Purge Method
+func TestStudentRepository_Purge(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	store := NewStore(mockDB)
+
+	cutoff := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Not archived
+	mock.ExpectQuery(`SELECT archived_at FROM students WHERE id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at"}).AddRow(nil))
+	err = store.Students.Purge(context.Background(), "1", cutoff)
+	assert.ErrorIs(t, err, models.ErrPurgeNotAllowed)
+
+	// Archived within the retention period
+	mock.ExpectQuery(`SELECT archived_at FROM students WHERE id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at"}).AddRow(cutoff.Add(time.Hour)))
+	err = store.Students.Purge(context.Background(), "1", cutoff)
+	assert.ErrorIs(t, err, models.ErrPurgeNotAllowed)
+
+	// Archived before the cutoff; the grades and attendance removed with
+	// the student are recorded first
+	mock.ExpectQuery(`SELECT archived_at FROM students WHERE id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at"}).AddRow(cutoff.Add(-time.Hour)))
+	mock.ExpectBegin()
+	expectRowJSON(mock, "students", "1", `{"id": "1"}`)
+	mock.ExpectQuery(`SELECT id FROM grades WHERE student_id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("g1"))
+	expectRowJSON(mock, "grades", "g1", `{"id": "g1"}`)
+	expectAuditEntry(mock, models.AuditDelete, "grade", "g1")
+	mock.ExpectQuery(`SELECT id FROM attendance WHERE student_id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("at1"))
+	expectRowJSON(mock, "attendance", "at1", `{"id": "at1"}`)
+	expectAuditEntry(mock, models.AuditDelete, "attendance", "at1")
+	mock.ExpectExec(`DELETE FROM students WHERE id = \$1 AND archived_at <= \$2`).
+		WithArgs("1", cutoff).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectRowJSON(mock, "students", "1", "")
+	expectAuditEntry(mock, models.AuditDelete, "student", "1")
+	mock.ExpectCommit()
+	err = store.Students.Purge(context.Background(), "1", cutoff)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SubmissionRepository stores submissions in submissions and the files
+// attached to them in submission_files
+type SubmissionRepository struct {
+	db *sql.DB
+}
+
+// submissionFields lists the columns scanned by scanSubmission
+const submissionFields = "id, assignment_id, student_id, text, status, COALESCE(submitted_by, ''), submitted_at"
+
+// scanSubmission scans a row selected with submissionFields
+func scanSubmission(row interface{ Scan(...interface{}) error }, s *models.Submission) error {
+	return row.Scan(&s.ID, &s.AssignmentID, &s.StudentID, &s.Text, &s.Status, &s.SubmittedBy, &s.SubmittedAt)
+}
+
+// Create stores a submission with its files and gives the student's grades
+// for the assignment its status, auditing each grade it changes
+func (r *SubmissionRepository) Create(ctx context.Context, s *models.Submission) (err error) {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	if err = checkTermOpen(ctx, tx, s.AssignmentID, ""); err != nil {
+		return err
+	}
+	var due time.Time
+	err = tx.QueryRowContext(ctx, "SELECT due_date FROM assignments WHERE id = $1", s.AssignmentID).Scan(&due)
+	if err != nil {
+		err = notFound(err, "assignment")
+		return err
+	}
+
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	s.SubmittedAt = time.Now()
+	s.Status = models.SubmissionStatus(s.SubmittedAt, due)
+
+	query := `INSERT INTO submissions (id, assignment_id, student_id, text, status, submitted_by, submitted_at)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)`
+	log.Printf("Executing INSERT query: %s", query)
+	if _, err = tx.ExecContext(ctx, query, s.ID, s.AssignmentID, s.StudentID, s.Text, s.Status, s.SubmittedBy, s.SubmittedAt); err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	query = `INSERT INTO submission_files (id, submission_id, name, content_type, size, storage_key)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+	for i := range s.Files {
+		f := &s.Files[i]
+		if f.ID == "" {
+			f.ID = uuid.New().String()
+		}
+		if _, err = tx.ExecContext(ctx, query, f.ID, s.ID, f.Name, f.ContentType, f.Size, f.Key); err != nil {
+			log.Printf("Error executing INSERT: %v", err)
+			return fmt.Errorf("failed to store submission file %s: %w", f.Name, err)
+		}
+	}
+
+	var gradeIDs []string
+	gradeIDs, err = queryIDs(ctx, tx, "SELECT id FROM grades WHERE student_id = $1 AND assignment_id = $2 AND status <> $3",
+		s.StudentID, s.AssignmentID, s.Status)
+	if err != nil {
+		return err
+	}
+	for _, id := range gradeIDs {
+		var before, after json.RawMessage
+		if before, err = rowJSON(ctx, tx, models.AuditEntityGrade, id); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, "UPDATE grades SET status = $1, updated_at = $2 WHERE id = $3", s.Status, s.SubmittedAt, id); err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to update grade %s: %w", id, err)
+		}
+		if after, err = rowJSON(ctx, tx, models.AuditEntityGrade, id); err != nil {
+			return err
+		}
+		if err = recordAudit(ctx, tx, models.AuditUpdate, models.AuditEntityGrade, id, before, after); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully created submission with ID: %s", s.ID)
+	return nil
+}
+
+// GetByID retrieves a submission with its files
+func (r *SubmissionRepository) GetByID(ctx context.Context, id string) (*models.Submission, error) {
+	query := "SELECT " + submissionFields + " FROM submissions WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var s models.Submission
+	if err := scanSubmission(r.db.QueryRowContext(ctx, query, id), &s); err != nil {
+		return nil, notFound(err, "submission")
+	}
+	submissions := []models.Submission{s}
+	if err := r.loadFiles(ctx, submissions); err != nil {
+		return nil, err
+	}
+	return &submissions[0], nil
+}
+
+// ListByAssignmentID returns the submissions for an assignment, most recent
+// first, of the given students only when studentIDs is not nil
+func (r *SubmissionRepository) ListByAssignmentID(ctx context.Context, assignmentID string, studentIDs []string) ([]models.Submission, error) {
+	return r.list(ctx, "assignment_id", assignmentID, studentIDs)
+}
+
+// ListByStudentID returns the submissions of a student, most recent first
+func (r *SubmissionRepository) ListByStudentID(ctx context.Context, studentID string) ([]models.Submission, error) {
+	return r.list(ctx, "student_id", studentID, nil)
+}
+
+// list returns the submissions whose column equals value with their files,
+// restricted to the given students when studentIDs is not nil
+func (r *SubmissionRepository) list(ctx context.Context, column, value string, studentIDs []string) ([]models.Submission, error) {
+	query := "SELECT " + submissionFields + " FROM submissions WHERE " + column + " = $1 AND ($2::text[] IS NULL OR student_id = ANY($2)) ORDER BY submitted_at DESC, id"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, value, pq.Array(studentIDs))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var submissions []models.Submission
+	for rows.Next() {
+		var s models.Submission
+		if err := scanSubmission(rows, &s); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan submission row: %w", err)
+		}
+		submissions = append(submissions, s)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating submission rows: %w", err)
+	}
+
+	if err := r.loadFiles(ctx, submissions); err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// loadFiles reads the files of the submissions in one query
+func (r *SubmissionRepository) loadFiles(ctx context.Context, submissions []models.Submission) error {
+	if len(submissions) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Submission, len(submissions))
+	ids := make([]string, len(submissions))
+	for i := range submissions {
+		submissions[i].Files = []models.SubmissionFile{}
+		byID[submissions[i].ID] = &submissions[i]
+		ids[i] = submissions[i].ID
+	}
+
+	query := `SELECT submission_id, id, name, content_type, size, storage_key
+			FROM submission_files WHERE submission_id = ANY($1) ORDER BY name, id`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var submissionID string
+		var f models.SubmissionFile
+		if err := rows.Scan(&submissionID, &f.ID, &f.Name, &f.ContentType, &f.Size, &f.Key); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return fmt.Errorf("failed to scan submission file row: %w", err)
+		}
+		s := byID[submissionID]
+		s.Files = append(s.Files, f)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return fmt.Errorf("error iterating submission file rows: %w", err)
+	}
+	return nil
+}
+
+// submittedStatus gives a grade the status of the latest submission of its
+// student for its assignment. Grades without a submission keep theirs.
+func submittedStatus(ctx context.Context, tx *sql.Tx, g *models.Grade) error {
+	query := `SELECT status FROM submissions WHERE student_id = $1 AND assignment_id = $2
+			ORDER BY submitted_at DESC LIMIT 1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var status models.AssignmentStatus
+	err := tx.QueryRowContext(ctx, query, g.StudentID, g.AssignmentID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return fmt.Errorf("failed to read the submission status: %w", err)
+	}
+	g.Status = status
+	return nil
+}
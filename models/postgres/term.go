@@ -0,0 +1,536 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// TermRepository stores academic years in academic_years, their terms in
+// terms and the report cards of finalized terms in report_cards
+type TermRepository struct {
+	db *sql.DB
+}
+
+// termFields lists the columns scanned by scanTerm
+const termFields = "id, academic_year_id, name, start_date, end_date, finalized_at, COALESCE(finalized_by, ''), created_at, updated_at"
+
+// scanTerm scans a row selected with termFields
+func scanTerm(row interface{ Scan(...interface{}) error }, t *models.Term) error {
+	var finalizedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.AcademicYearID, &t.Name, &t.StartDate, &t.EndDate, &finalizedAt, &t.FinalizedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if finalizedAt.Valid {
+		t.FinalizedAt = &finalizedAt.Time
+	}
+	t.StartDate, t.EndDate = models.Day(t.StartDate), models.Day(t.EndDate)
+	return nil
+}
+
+// overlapping converts exclusion violations of the date ranges into
+// models.ErrTermOverlap
+func overlapping(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23P01" {
+		return models.ErrTermOverlap
+	}
+	return err
+}
+
+// CreateYear persists a new academic year
+func (r *TermRepository) CreateYear(ctx context.Context, y *models.AcademicYear) error {
+	if err := y.Validate(); err != nil {
+		return err
+	}
+
+	if y.ID == "" {
+		y.ID = uuid.New().String()
+	}
+	now := time.Now()
+	y.CreatedAt = now
+	y.UpdatedAt = now
+	y.Terms = []models.Term{}
+
+	query := `INSERT INTO academic_years (id, name, start_date, end_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err := r.db.ExecContext(ctx, query, y.ID, y.Name, y.StartDate, y.EndDate, y.CreatedAt, y.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", overlapping(err))
+	}
+
+	log.Printf("Successfully created academic year with ID: %s", y.ID)
+	return nil
+}
+
+// UpdateYear renames or moves an academic year. Its terms must still fall
+// within its dates.
+func (r *TermRepository) UpdateYear(ctx context.Context, y *models.AcademicYear) (err error) {
+	if y.ID == "" {
+		return errors.New("academic year ID is required")
+	}
+	if err := y.Validate(); err != nil {
+		return err
+	}
+
+	y.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	query := `UPDATE academic_years SET name = $1, start_date = $2, end_date = $3, updated_at = $4
+			WHERE id = $5
+			RETURNING created_at`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	if err = tx.QueryRowContext(ctx, query, y.Name, y.StartDate, y.EndDate, y.UpdatedAt, y.ID).Scan(&y.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("academic year %w", models.ErrNotFound)
+		}
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", overlapping(err))
+	}
+
+	var outside bool
+	query = "SELECT EXISTS (SELECT 1 FROM terms WHERE academic_year_id = $1 AND (start_date < $2 OR end_date > $3))"
+	if err = tx.QueryRowContext(ctx, query, y.ID, y.StartDate, y.EndDate).Scan(&outside); err != nil {
+		log.Printf("Error checking terms: %v", err)
+		return fmt.Errorf("failed to check terms: %w", err)
+	}
+	if outside {
+		err = models.ErrTermOutsideYear
+		return err
+	}
+
+	if y.Terms, err = listTerms(ctx, tx, y.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully updated academic year with ID: %s", y.ID)
+	return nil
+}
+
+// DeleteYear removes an academic year and its terms unless one of them is
+// finalized
+func (r *TermRepository) DeleteYear(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("academic year ID is required")
+	}
+
+	query := `DELETE FROM academic_years WHERE id = $1
+			AND NOT EXISTS (SELECT 1 FROM terms WHERE academic_year_id = $1 AND finalized_at IS NOT NULL)`
+	log.Printf("Executing DELETE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		// Tell a missing year from one that has a finalized term
+		if _, err := r.GetYearByID(ctx, id); err != nil {
+			return err
+		}
+		return models.ErrTermFinalized
+	}
+
+	log.Printf("Successfully deleted academic year with ID: %s", id)
+	return nil
+}
+
+// GetYearByID retrieves an academic year with its terms
+func (r *TermRepository) GetYearByID(ctx context.Context, id string) (*models.AcademicYear, error) {
+	query := "SELECT id, name, start_date, end_date, created_at, updated_at FROM academic_years WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var year models.AcademicYear
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt, &year.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err, "academic year")
+	}
+	year.StartDate, year.EndDate = models.Day(year.StartDate), models.Day(year.EndDate)
+
+	if year.Terms, err = listTerms(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	return &year, nil
+}
+
+// ListYears returns every academic year with its terms, reading all terms in
+// one query
+func (r *TermRepository) ListYears(ctx context.Context) ([]models.AcademicYear, error) {
+	query := "SELECT id, name, start_date, end_date, created_at, updated_at FROM academic_years ORDER BY start_date"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var years []models.AcademicYear
+	for rows.Next() {
+		var year models.AcademicYear
+		if err := rows.Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt, &year.UpdatedAt); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan academic year row: %w", err)
+		}
+		year.StartDate, year.EndDate = models.Day(year.StartDate), models.Day(year.EndDate)
+		year.Terms = []models.Term{}
+		years = append(years, year)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating academic year rows: %w", err)
+	}
+
+	terms, err := listTerms(ctx, r.db, "")
+	if err != nil {
+		return nil, err
+	}
+	byYear := make(map[string]int, len(years))
+	for i, year := range years {
+		byYear[year.ID] = i
+	}
+	for _, term := range terms {
+		if i, ok := byYear[term.AcademicYearID]; ok {
+			years[i].Terms = append(years[i].Terms, term)
+		}
+	}
+
+	return years, nil
+}
+
+// listTerms returns the terms of a year, or of every year when yearID is
+// empty, ordered by start date
+func listTerms(ctx context.Context, q queryer, yearID string) ([]models.Term, error) {
+	query := "SELECT " + termFields + " FROM terms WHERE $1::text = '' OR academic_year_id = $1 ORDER BY start_date"
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := q.QueryContext(ctx, query, yearID)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	terms := []models.Term{}
+	for rows.Next() {
+		var term models.Term
+		if err := scanTerm(rows, &term); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan term row: %w", err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating term rows: %w", err)
+	}
+
+	return terms, nil
+}
+
+// CreateTerm persists a new term within its academic year
+func (r *TermRepository) CreateTerm(ctx context.Context, t *models.Term) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if err := r.checkWithinYear(ctx, t); err != nil {
+		return err
+	}
+
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	t.FinalizedAt = nil
+	t.FinalizedBy = ""
+
+	query := `INSERT INTO terms (id, academic_year_id, name, start_date, end_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err := r.db.ExecContext(ctx, query, t.ID, t.AcademicYearID, t.Name, t.StartDate, t.EndDate, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", overlapping(err))
+	}
+
+	log.Printf("Successfully created term with ID: %s", t.ID)
+	return nil
+}
+
+// UpdateTerm renames or moves a term that is not finalized. The term stays
+// in its academic year.
+func (r *TermRepository) UpdateTerm(ctx context.Context, t *models.Term) error {
+	if t.ID == "" {
+		return errors.New("term ID is required")
+	}
+	existing, err := r.GetTermByID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	t.AcademicYearID = existing.AcademicYearID
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if err := r.checkWithinYear(ctx, t); err != nil {
+		return err
+	}
+
+	t.UpdatedAt = time.Now()
+
+	query := `UPDATE terms SET name = $1, start_date = $2, end_date = $3, updated_at = $4
+			WHERE id = $5 AND finalized_at IS NULL
+			RETURNING created_at`
+	log.Printf("Executing UPDATE query: %s", query)
+
+	err = r.db.QueryRowContext(ctx, query, t.Name, t.StartDate, t.EndDate, t.UpdatedAt, t.ID).Scan(&t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrTermFinalized
+	}
+	if err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return fmt.Errorf("failed to execute update query: %w", overlapping(err))
+	}
+
+	log.Printf("Successfully updated term with ID: %s", t.ID)
+	return nil
+}
+
+// checkWithinYear fails with models.ErrTermOutsideYear when the term does
+// not fall within its academic year
+func (r *TermRepository) checkWithinYear(ctx context.Context, t *models.Term) error {
+	query := "SELECT start_date, end_date FROM academic_years WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var year models.AcademicYear
+	if err := r.db.QueryRowContext(ctx, query, t.AcademicYearID).Scan(&year.StartDate, &year.EndDate); err != nil {
+		return notFound(err, "academic year")
+	}
+	year.StartDate, year.EndDate = models.Day(year.StartDate), models.Day(year.EndDate)
+
+	if !t.Within(&year) {
+		return models.ErrTermOutsideYear
+	}
+	return nil
+}
+
+// DeleteTerm removes a term that is not finalized
+func (r *TermRepository) DeleteTerm(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("term ID is required")
+	}
+	term, err := r.GetTermByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if term.Finalized() {
+		return models.ErrTermFinalized
+	}
+
+	query := "DELETE FROM terms WHERE id = $1 AND finalized_at IS NULL"
+	log.Printf("Executing DELETE query: %s", query)
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing DELETE: %v", err)
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting affected rows: %v", err)
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return models.ErrTermFinalized
+	}
+
+	log.Printf("Successfully deleted term with ID: %s", id)
+	return nil
+}
+
+// GetTermByID retrieves a term by its ID
+func (r *TermRepository) GetTermByID(ctx context.Context, id string) (*models.Term, error) {
+	query := "SELECT " + termFields + " FROM terms WHERE id = $1"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var term models.Term
+	if err := scanTerm(r.db.QueryRowContext(ctx, query, id), &term); err != nil {
+		return nil, notFound(err, "term")
+	}
+	return &term, nil
+}
+
+// GetTermByDate retrieves the term a day falls in
+func (r *TermRepository) GetTermByDate(ctx context.Context, date time.Time) (*models.Term, error) {
+	query := "SELECT " + termFields + " FROM terms WHERE $1 BETWEEN start_date AND end_date"
+	log.Printf("Executing SELECT query: %s", query)
+
+	var term models.Term
+	if err := scanTerm(r.db.QueryRowContext(ctx, query, models.Day(date)), &term); err != nil {
+		return nil, notFound(err, "term")
+	}
+	return &term, nil
+}
+
+// Finalize marks a term finalized and stores its report cards in one
+// transaction. The term row stays locked until the transaction ends, and
+// grade changes share-lock the term they fall in, so grades cannot change
+// while the snapshot is taken and are refused afterwards.
+func (r *TermRepository) Finalize(ctx context.Context, id, finalizedBy string, snapshot func(term *models.Term) ([]models.ReportCard, error)) (term *models.Term, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error beginning transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("Error rolling back transaction: %v", rbErr)
+			}
+		}
+	}()
+
+	term = &models.Term{}
+	query := "SELECT " + termFields + " FROM terms WHERE id = $1 FOR UPDATE"
+	log.Printf("Executing SELECT query: %s", query)
+	if err = scanTerm(tx.QueryRowContext(ctx, query, id), term); err != nil {
+		err = notFound(err, "term")
+		return nil, err
+	}
+	if term.Finalized() {
+		err = models.ErrTermFinalized
+		return nil, err
+	}
+
+	now := time.Now()
+	query = "UPDATE terms SET finalized_at = $1, finalized_by = NULLIF($2, ''), updated_at = $1 WHERE id = $3"
+	log.Printf("Executing UPDATE query: %s", query)
+	if _, err = tx.ExecContext(ctx, query, now, finalizedBy, id); err != nil {
+		log.Printf("Error executing UPDATE: %v", err)
+		return nil, fmt.Errorf("failed to execute update query: %w", err)
+	}
+	term.FinalizedAt = &now
+	term.FinalizedBy = finalizedBy
+	term.UpdatedAt = now
+
+	cards, err := snapshot(term)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `INSERT INTO report_cards (id, term_id, student_id, student_name, data, document, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	log.Printf("Executing INSERT query: %s", query)
+	for i := range cards {
+		card := &cards[i]
+		if card.ID == "" {
+			card.ID = uuid.New().String()
+		}
+		card.TermID = id
+		card.CreatedAt = now
+		_, err = tx.ExecContext(ctx, query, card.ID, card.TermID, card.StudentID, card.StudentName, string(card.Data), string(card.Document), card.CreatedAt)
+		if err != nil {
+			log.Printf("Error executing INSERT: %v", err)
+			return nil, fmt.Errorf("failed to store report card of student %s: %w", card.StudentID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully finalized term %s with %d report cards", id, len(cards))
+	return term, nil
+}
+
+// ListReportCards returns the report cards of a student, most recent term
+// first, without their data
+func (r *TermRepository) ListReportCards(ctx context.Context, studentID string) ([]models.ReportCard, error) {
+	query := `SELECT rc.id, rc.term_id, rc.student_id, rc.student_name, rc.created_at
+			FROM report_cards rc
+			JOIN terms t ON t.id = rc.term_id
+			WHERE rc.student_id = $1
+			ORDER BY t.start_date DESC`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []models.ReportCard
+	for rows.Next() {
+		var card models.ReportCard
+		if err := rows.Scan(&card.ID, &card.TermID, &card.StudentID, &card.StudentName, &card.CreatedAt); err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan report card row: %w", err)
+		}
+		cards = append(cards, card)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating report card rows: %w", err)
+	}
+
+	return cards, nil
+}
+
+// GetReportCardByID retrieves a report card with its data and document
+func (r *TermRepository) GetReportCardByID(ctx context.Context, id string) (*models.ReportCard, error) {
+	query := `SELECT id, term_id, student_id, student_name, data, document, created_at
+			FROM report_cards WHERE id = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var card models.ReportCard
+	var data, document []byte
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&card.ID, &card.TermID, &card.StudentID, &card.StudentName, &data, &document, &card.CreatedAt)
+	if err != nil {
+		return nil, notFound(err, "report card")
+	}
+	card.Data, card.Document = data, document
+	return &card, nil
+}
@@ -0,0 +1,346 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/google/uuid"
+)
+
+// UserRepository stores users in the users table and parent links in parent_student
+type UserRepository struct {
+	db *sql.DB
+}
+
+// Create persists a new user to the database
+func (r *UserRepository) Create(ctx context.Context, u *models.User) error {
+	// Generate UUID if ID is empty so the audit entry can refer to the user
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+
+	err := audited(ctx, r.db, models.AuditCreate, models.AuditEntityUser, u.ID, func(tx *sql.Tx) error {
+		return insertUser(ctx, tx, u)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully created user with ID: %s", u.ID)
+	return nil
+}
+
+// insertUser inserts the user and its parent-student links within a transaction
+func insertUser(ctx context.Context, tx *sql.Tx, u *models.User) error {
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	// Check if username or email already exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE username = $1 OR email = $2", u.Username, u.Email).Scan(&count)
+	if err != nil {
+		log.Printf("Error checking existing user: %v", err)
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if count > 0 {
+		return models.ErrUserExists
+	}
+
+	// Generate UUID if ID is empty
+	if u.ID == "" {
+		u.ID = uuid.New().String()
+	}
+
+	if err := u.HashPassword(); err != nil {
+		return err
+	}
+
+	// Set timestamps
+	now := time.Now()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	// Insert user
+	query := `INSERT INTO users
+			(id, username, email, password_hash, first_name, last_name, role, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	log.Printf("Executing INSERT query: %s", query)
+
+	_, err = tx.ExecContext(ctx, query, u.ID, u.Username, u.Email, u.PasswordHash, u.FirstName, u.LastName, u.Role, u.CreatedAt, u.UpdatedAt)
+	if err != nil {
+		log.Printf("Error executing INSERT: %v", err)
+		return fmt.Errorf("failed to execute insert query: %w", err)
+	}
+
+	// For parents, associate with students
+	if u.Role == models.RoleParent && len(u.StudentIDs) > 0 {
+		for _, studentID := range u.StudentIDs {
+			_, err = tx.ExecContext(ctx, "INSERT INTO parent_student (parent_id, student_id) VALUES ($1, $2)", u.ID, studentID)
+			if err != nil {
+				log.Printf("Error associating parent with student: %v", err)
+				return fmt.Errorf("failed to associate parent with student: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Update updates an existing user in the database
+func (r *UserRepository) Update(ctx context.Context, u *models.User) error {
+	if u.ID == "" || u.Username == "" || u.Email == "" || u.FirstName == "" || u.LastName == "" || u.Role == "" {
+		return errors.New("invalid user data")
+	}
+
+	// Check if username or email already exists for another user
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE (username = $1 OR email = $2) AND id != $3",
+		u.Username, u.Email, u.ID).Scan(&count)
+	if err != nil {
+		log.Printf("Error checking existing user: %v", err)
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+	if count > 0 {
+		return models.ErrUserExists
+	}
+
+	// Update timestamp
+	u.UpdatedAt = time.Now()
+
+	err = audited(ctx, r.db, models.AuditUpdate, models.AuditEntityUser, u.ID, func(tx *sql.Tx) error {
+		// Update password if provided
+		var result sql.Result
+		var err error
+		if u.Password != "" {
+			if err = u.HashPassword(); err != nil {
+				return err
+			}
+
+			query := `UPDATE users SET
+					username = $1, email = $2, password_hash = $3, first_name = $4, last_name = $5, role = $6, updated_at = $7
+					WHERE id = $8`
+			log.Printf("Executing UPDATE query with password: %s", query)
+
+			result, err = tx.ExecContext(ctx, query, u.Username, u.Email, u.PasswordHash, u.FirstName, u.LastName, u.Role, u.UpdatedAt, u.ID)
+		} else {
+			query := `UPDATE users SET
+					username = $1, email = $2, first_name = $3, last_name = $4, role = $5, updated_at = $6
+					WHERE id = $7`
+			log.Printf("Executing UPDATE query without password: %s", query)
+
+			result, err = tx.ExecContext(ctx, query, u.Username, u.Email, u.FirstName, u.LastName, u.Role, u.UpdatedAt, u.ID)
+		}
+		if err != nil {
+			log.Printf("Error executing UPDATE: %v", err)
+			return fmt.Errorf("failed to execute update query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("user %w", models.ErrNotFound)
+		}
+
+		// For parents, update student associations
+		if u.Role == models.RoleParent && len(u.StudentIDs) > 0 {
+			// First remove all existing associations
+			_, err = tx.ExecContext(ctx, "DELETE FROM parent_student WHERE parent_id = $1", u.ID)
+			if err != nil {
+				log.Printf("Error removing parent-student associations: %v", err)
+				return fmt.Errorf("failed to remove parent-student associations: %w", err)
+			}
+
+			// Then add new ones
+			for _, studentID := range u.StudentIDs {
+				_, err = tx.ExecContext(ctx, "INSERT INTO parent_student (parent_id, student_id) VALUES ($1, $2)", u.ID, studentID)
+				if err != nil {
+					log.Printf("Error associating parent with student: %v", err)
+					return fmt.Errorf("failed to associate parent with student: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully updated user with ID: %s", u.ID)
+	return nil
+}
+
+// Delete removes a user from the database
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.New("user ID is required")
+	}
+
+	err := audited(ctx, r.db, models.AuditDelete, models.AuditEntityUser, id, func(tx *sql.Tx) error {
+		// Remove parent-student associations if exists
+		_, err := tx.ExecContext(ctx, "DELETE FROM parent_student WHERE parent_id = $1", id)
+		if err != nil {
+			log.Printf("Error removing parent-student associations: %v", err)
+			return fmt.Errorf("failed to remove parent-student associations: %w", err)
+		}
+
+		// Delete user
+		query := "DELETE FROM users WHERE id = $1"
+		log.Printf("Executing DELETE query: %s", query)
+
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			log.Printf("Error executing DELETE: %v", err)
+			return fmt.Errorf("failed to execute delete query: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting affected rows: %v", err)
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("user %w", models.ErrNotFound)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Successfully deleted user with ID: %s", id)
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.getUser(ctx, "id", id)
+}
+
+// GetByUsername retrieves a user by username
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getUser(ctx, "username", username)
+}
+
+// getUser retrieves the user whose column matches the value
+func (r *UserRepository) getUser(ctx context.Context, column, value string) (*models.User, error) {
+	query := `SELECT id, username, email, password_hash, first_name, last_name, role, created_at, updated_at
+			FROM users WHERE ` + column + ` = $1`
+	log.Printf("Executing SELECT query: %s", query)
+
+	var user models.User
+	err := r.db.QueryRowContext(ctx, query, value).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FirstName,
+		&user.LastName,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, notFound(err, "user")
+	}
+
+	// If user is a parent, get associated students
+	if user.Role == models.RoleParent {
+		user.StudentIDs, err = r.GetStudentIDsByParentID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("Successfully retrieved user with ID: %s", user.ID)
+	return &user, nil
+}
+
+// List retrieves all users
+func (r *UserRepository) List(ctx context.Context, opts models.ListOptions) (models.Page[models.User], error) {
+	opts = opts.WithDefaultSort(models.UserListSpec)
+	list := newListQuery("users", userColumns, opts)
+
+	total, err := list.count(ctx, r.db)
+	if err != nil {
+		return models.Page[models.User]{}, err
+	}
+
+	query, args := list.page("id, username, email, password_hash, first_name, last_name, role, created_at, updated_at")
+	users, err := r.queryUsers(ctx, query, args...)
+	if err != nil {
+		return models.Page[models.User]{}, err
+	}
+	return models.NewPage(users, opts, total), nil
+}
+
+// userColumns maps the fields of models.UserListSpec to columns
+var userColumns = map[string]string{
+	"id":         "id",
+	"username":   "username",
+	"last_name":  "last_name",
+	"role":       "role",
+	"created_at": "created_at",
+}
+
+// GetStudentIDsByParentID retrieves the IDs of all students linked to a parent
+func (r *UserRepository) GetStudentIDsByParentID(ctx context.Context, parentID string) ([]string, error) {
+	return queryIDs(ctx, r.db, "SELECT student_id FROM parent_student WHERE parent_id = $1", parentID)
+}
+
+// ListParentsByStudentID retrieves all parents associated with a student
+func (r *UserRepository) ListParentsByStudentID(ctx context.Context, studentID string) ([]models.User, error) {
+	query := `SELECT u.id, u.username, u.email, u.password_hash, u.first_name, u.last_name, u.role, u.created_at, u.updated_at
+			FROM users u
+			JOIN parent_student ps ON u.id = ps.parent_id
+			WHERE ps.student_id = $1 AND u.role = $2`
+	return r.queryUsers(ctx, query, studentID, models.RoleParent)
+}
+
+// queryUsers runs a query returning user rows
+func (r *UserRepository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]models.User, error) {
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.PasswordHash,
+			&user.FirstName,
+			&user.LastName,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, nil
+}
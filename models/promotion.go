@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrPromotionConflict is returned when a student changed after the
+// promotions were planned, or was already promoted on the same day
+var ErrPromotionConflict = errors.New("students changed since the promotions were planned")
+
+// DefaultGradeLevels is the order students move through the grade levels,
+// from the first to the last. Students in the last level graduate.
+var DefaultGradeLevels = []string{"K", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}
+
+// PromotionAction is what a year-end rollover does with a student
+type PromotionAction string
+
+const (
+	// PromotionPromote moves the student to the next grade level
+	PromotionPromote PromotionAction = "promote"
+	// PromotionHold keeps the student in their grade level for another year
+	PromotionHold PromotionAction = "hold"
+	// PromotionGraduate marks the student inactive in their last grade level
+	PromotionGraduate PromotionAction = "graduate"
+	// PromotionSkip leaves a student whose grade level is not in the
+	// sequence unchanged
+	PromotionSkip PromotionAction = "skip"
+)
+
+// GradeChange is a planned or applied change of the grade level of a
+// student. Applied changes make up the grade-level history of the student:
+// ToGrade applies from EffectiveDate and FromGrade before it.
+type GradeChange struct {
+	ID            string          `json:"id,omitempty"`
+	StudentID     string          `json:"student_id"`
+	StudentName   string          `json:"student_name,omitempty"`
+	FromGrade     string          `json:"from_grade"`
+	ToGrade       string          `json:"to_grade"`
+	Action        PromotionAction `json:"action"`
+	EffectiveDate time.Time       `json:"effective_date"`
+	// Reason explains a skipped student; it is not stored
+	Reason     string    `json:"reason,omitempty"`
+	RecordedBy string    `json:"recorded_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+}
+
+// PromotionPlan is the input of a year-end rollover. Every active student is
+// promoted to the next grade level unless held back or graduated; students
+// in the last level graduate.
+type PromotionPlan struct {
+	// EffectiveDate is the first day of the new grade levels, today by
+	// default
+	EffectiveDate time.Time `json:"effective_date"`
+	// HoldStudentIDs stay in their grade level
+	HoldStudentIDs []string `json:"hold_student_ids"`
+	// GraduateStudentIDs graduate from whatever level they are in
+	GraduateStudentIDs []string `json:"graduate_student_ids"`
+}
+
+// Validate checks that no student is both held back and graduated and
+// truncates the effective date to a day
+func (p *PromotionPlan) Validate() error {
+	held := make(map[string]bool, len(p.HoldStudentIDs))
+	for _, id := range p.HoldStudentIDs {
+		held[id] = true
+	}
+	for _, id := range p.GraduateStudentIDs {
+		if held[id] {
+			return errors.New("a student cannot be both held back and graduated")
+		}
+	}
+	if p.EffectiveDate.IsZero() {
+		p.EffectiveDate = time.Now()
+	}
+	p.EffectiveDate = Day(p.EffectiveDate)
+	return nil
+}
+
+// Changes plans the grade change of each active student following levels.
+// Inactive students are left out. The changes are ordered by student name.
+func (p *PromotionPlan) Changes(students []Student, levels []string) []GradeChange {
+	next := make(map[string]string, len(levels))
+	for i, level := range levels {
+		next[level] = ""
+		if i+1 < len(levels) {
+			next[level] = levels[i+1]
+		}
+	}
+	held := make(map[string]bool, len(p.HoldStudentIDs))
+	for _, id := range p.HoldStudentIDs {
+		held[id] = true
+	}
+	graduated := make(map[string]bool, len(p.GraduateStudentIDs))
+	for _, id := range p.GraduateStudentIDs {
+		graduated[id] = true
+	}
+
+	var changes []GradeChange
+	for _, student := range students {
+		if !student.IsActive {
+			continue
+		}
+		change := GradeChange{
+			StudentID:     student.ID,
+			StudentName:   student.Name,
+			FromGrade:     student.Grade,
+			ToGrade:       student.Grade,
+			EffectiveDate: p.EffectiveDate,
+		}
+		level, known := next[student.Grade]
+		switch {
+		case held[student.ID]:
+			change.Action = PromotionHold
+		case graduated[student.ID]:
+			change.Action = PromotionGraduate
+		case !known:
+			change.Action = PromotionSkip
+			change.Reason = "grade level is not in the sequence"
+		case level == "":
+			change.Action = PromotionGraduate
+		default:
+			change.Action = PromotionPromote
+			change.ToGrade = level
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].StudentName != changes[j].StudentName {
+			return changes[i].StudentName < changes[j].StudentName
+		}
+		return changes[i].StudentID < changes[j].StudentID
+	})
+	return changes
+}
+
+// GradeAt returns the grade level a student had on a day, given the
+// student's grade-level history ordered by effective date and current grade
+func GradeAt(history []GradeChange, current string, date time.Time) string {
+	day := Day(date)
+	for _, change := range history {
+		if change.EffectiveDate.After(day) {
+			return change.FromGrade
+		}
+	}
+	return current
+}
@@ -0,0 +1,327 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/reporting"
+)
+
+// ErrNotFound is returned when a record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store groups the repositories the API reads and writes its data through.
+// The postgres package provides the production implementation; the memory
+// package keeps everything in process for tests and local experiments.
+//
+// Listings take ListOptions parsed with the ListSpec of their record type.
+// Passing the zero ListOptions returns every record in the default order.
+// Reports reads aggregates across the other repositories' records.
+type Store struct {
+	Students      StudentRepository
+	Users         UserRepository
+	RefreshTokens RefreshTokenRepository
+	Invitations   InvitationRepository
+	Permissions   PermissionRepository
+	Classes       ClassRepository
+	Grades        GradeRepository
+	Gradebook     GradebookRepository
+	Terms         TermRepository
+	Promotions    PromotionRepository
+	Submissions   SubmissionRepository
+	Jobs          JobRepository
+	Notifications NotificationRepository
+	Attendance    AttendanceRepository
+	Forum         ForumRepository
+	Audit         AuditRepository
+	Import        ImportRepository
+	Reports       reporting.Repository
+}
+
+// StudentRepository stores students. Archived students are not returned by
+// GetByID, List and ListByIDs, and cannot be updated until restored.
+type StudentRepository interface {
+	Create(ctx context.Context, student *Student) error
+	Update(ctx context.Context, student *Student) error
+	GetByID(ctx context.Context, id string) (*Student, error)
+	// List returns a page of students; opts.IDs restricts it to the given students
+	List(ctx context.Context, opts ListOptions) (Page[Student], error)
+	ListByIDs(ctx context.Context, ids []string) ([]Student, error)
+	// Archive deactivates a student and hides it, recording who archived it
+	Archive(ctx context.Context, id, archivedBy string) error
+	// Restore brings an archived student back as an active student
+	Restore(ctx context.Context, id string) error
+	ListArchived(ctx context.Context, opts ListOptions) (Page[Student], error)
+	// Purge permanently deletes a student archived before the given time,
+	// with its attendance, grades, forum posts and enrollments
+	Purge(ctx context.Context, id string, archivedBefore time.Time) error
+}
+
+// UserRepository stores users and the links between parents and students.
+// Create and Update hash the plain text Password when it is set.
+type UserRepository interface {
+	Create(ctx context.Context, user *User) error
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*User, error)
+	GetByUsername(ctx context.Context, username string) (*User, error)
+	List(ctx context.Context, opts ListOptions) (Page[User], error)
+	GetStudentIDsByParentID(ctx context.Context, parentID string) ([]string, error)
+	ListParentsByStudentID(ctx context.Context, studentID string) ([]User, error)
+}
+
+// RefreshTokenRepository stores refresh tokens. Token values are only
+// returned when issued; the repository keeps their hash.
+type RefreshTokenRepository interface {
+	// Issue starts a new refresh token family for a user and returns the token value
+	Issue(ctx context.Context, userID string, ttl time.Duration) (string, *RefreshToken, error)
+	// Rotate exchanges a valid refresh token for a new one in the same
+	// family. Presenting a token that was already rotated revokes the whole
+	// family and returns ErrRefreshTokenReused.
+	Rotate(ctx context.Context, value string, ttl time.Duration) (string, *RefreshToken, error)
+	// Revoke revokes the session the given refresh token belongs to
+	Revoke(ctx context.Context, value string) error
+	// RevokeAll revokes every active refresh token of a user and returns the
+	// number of tokens revoked
+	RevokeAll(ctx context.Context, userID string) (int64, error)
+}
+
+// InvitationRepository stores invitations
+type InvitationRepository interface {
+	// Create persists a new invitation and returns its code. The code is
+	// only available here; the repository stores its hash.
+	Create(ctx context.Context, invitation *Invitation) (string, error)
+	// Revoke marks an unredeemed invitation as revoked
+	Revoke(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*Invitation, error)
+	List(ctx context.Context) ([]Invitation, error)
+	// Redeem creates the user described by the invitation code. The role,
+	// email and student links come from the invitation. The invitation is
+	// consumed atomically with the account creation, so a code can never
+	// create more than one account. Unknown codes fail with ErrNotFound,
+	// codes that can no longer be used with ErrInvitationInvalid.
+	Redeem(ctx context.Context, code string, user *User) error
+}
+
+// PermissionRepository stores permissions and their grants to roles
+type PermissionRepository interface {
+	List(ctx context.Context) ([]Permission, error)
+	GetRolePermissions(ctx context.Context) (map[UserRole][]string, error)
+	// SetRolePermissions replaces the permissions granted to a role. It
+	// returns ErrUnknownPermission if any name does not exist.
+	SetRolePermissions(ctx context.Context, role UserRole, permissions []string) error
+}
+
+// ClassRepository stores classes with their teachers and student rosters
+type ClassRepository interface {
+	Create(ctx context.Context, class *Class) error
+	// Update updates a class and replaces its roster
+	Update(ctx context.Context, class *Class) error
+	// Delete removes a class together with its roster and assignments
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*Class, error)
+	// List and ListByTeacherID return classes without their rosters
+	List(ctx context.Context) ([]Class, error)
+	ListByTeacherID(ctx context.Context, teacherID string) ([]Class, error)
+	GetIDsByTeacherID(ctx context.Context, teacherID string) ([]string, error)
+	// GetStudentIDsByTeacherID returns the students enrolled in any class the
+	// faculty member teaches
+	GetStudentIDsByTeacherID(ctx context.Context, teacherID string) ([]string, error)
+	IsStudentEnrolled(ctx context.Context, classID, studentID string) (bool, error)
+}
+
+// GradeRepository stores assignments and the grades given for them
+type GradeRepository interface {
+	// CreateAssignment fails with ErrTermFinalized when the due date falls
+	// in a finalized term
+	CreateAssignment(ctx context.Context, assignment *Assignment) error
+	// UpdateAssignment and DeleteAssignment fail with ErrTermFinalized for
+	// assignments due in a finalized term, and UpdateAssignment also when
+	// the new due date falls in one
+	UpdateAssignment(ctx context.Context, assignment *Assignment) error
+	DeleteAssignment(ctx context.Context, id string) error
+	GetAssignmentByID(ctx context.Context, id string) (*Assignment, error)
+	ListAssignments(ctx context.Context, opts ListOptions) (Page[Assignment], error)
+	// CreateGrade and UpdateGrade fail with ErrTermFinalized for
+	// assignments due in a finalized term. A grade for an assignment the
+	// student submitted work for takes the status of the latest submission.
+	CreateGrade(ctx context.Context, grade *Grade) error
+	UpdateGrade(ctx context.Context, grade *Grade) error
+	GetGradeByID(ctx context.Context, id string) (*Grade, error)
+	ListGradesByStudentID(ctx context.Context, studentID string) ([]Grade, error)
+	// ListGradesByAssignmentID returns the grades for an assignment;
+	// studentIDs, when not nil, restricts them to these students
+	ListGradesByAssignmentID(ctx context.Context, assignmentID string, studentIDs []string) ([]Grade, error)
+	// MarkMissing gives a missing grade to every active student on the
+	// roster of an assignment due in [dueFrom, dueBefore) who has neither a
+	// grade nor a submission for it, and returns the grades created.
+	// Assignments due in a finalized term are left alone. Running it again
+	// for the same period creates no more grades.
+	MarkMissing(ctx context.Context, dueFrom, dueBefore time.Time) ([]Grade, error)
+}
+
+// GradebookRepository stores grading categories, letter-grade scales and
+// the gradebook settings of classes
+type GradebookRepository interface {
+	CreateCategory(ctx context.Context, category *GradingCategory) error
+	UpdateCategory(ctx context.Context, category *GradingCategory) error
+	// DeleteCategory removes a category and leaves its assignments
+	// uncategorized
+	DeleteCategory(ctx context.Context, id string) error
+	GetCategoryByID(ctx context.Context, id string) (*GradingCategory, error)
+	// ListCategories returns the categories of the class together with the
+	// categories of the subject, ordered by name. Either may be empty.
+	ListCategories(ctx context.Context, classID, subject string) ([]GradingCategory, error)
+	CreateScale(ctx context.Context, scale *GradeScale) error
+	UpdateScale(ctx context.Context, scale *GradeScale) error
+	// DeleteScale removes a scale; classes using it fall back to the default
+	DeleteScale(ctx context.Context, id string) error
+	GetScaleByID(ctx context.Context, id string) (*GradeScale, error)
+	ListScales(ctx context.Context) ([]GradeScale, error)
+	// GetSettings returns the settings of a class, or the defaults when the
+	// class has not saved any
+	GetSettings(ctx context.Context, classID string) (*GradebookSettings, error)
+	// SaveSettings creates or replaces the settings of a class
+	SaveSettings(ctx context.Context, settings *GradebookSettings) error
+}
+
+// TermRepository stores academic years, their terms and the report cards
+// taken when a term is finalized
+type TermRepository interface {
+	CreateYear(ctx context.Context, year *AcademicYear) error
+	// UpdateYear renames or moves a year; its terms must still fall within it
+	UpdateYear(ctx context.Context, year *AcademicYear) error
+	// DeleteYear removes a year and its terms. Years with a finalized term
+	// cannot be deleted.
+	DeleteYear(ctx context.Context, id string) error
+	// GetYearByID and ListYears return years with their terms, ordered by
+	// start date
+	GetYearByID(ctx context.Context, id string) (*AcademicYear, error)
+	ListYears(ctx context.Context) ([]AcademicYear, error)
+	CreateTerm(ctx context.Context, term *Term) error
+	// UpdateTerm renames or moves a term that is not finalized
+	UpdateTerm(ctx context.Context, term *Term) error
+	// DeleteTerm removes a term that is not finalized
+	DeleteTerm(ctx context.Context, id string) error
+	GetTermByID(ctx context.Context, id string) (*Term, error)
+	// GetTermByDate returns the term the day of date falls in
+	GetTermByDate(ctx context.Context, date time.Time) (*Term, error)
+	// Finalize marks a term finalized by the user and stores the report
+	// cards returned by snapshot. Grades of the term are locked before
+	// snapshot is called, so the cards see their final values; if snapshot
+	// fails the term is left open.
+	Finalize(ctx context.Context, id, finalizedBy string, snapshot func(term *Term) ([]ReportCard, error)) (*Term, error)
+	// ListReportCards returns the report cards of a student without their
+	// documents, most recent term first
+	ListReportCards(ctx context.Context, studentID string) ([]ReportCard, error)
+	GetReportCardByID(ctx context.Context, id string) (*ReportCard, error)
+}
+
+// PromotionRepository applies year-end rollovers and keeps the grade-level
+// history of students
+type PromotionRepository interface {
+	// Apply writes the changes in one transaction: promoted students move to
+	// their new grade level, graduated students are marked inactive and
+	// every change except skipped ones is added to the history. Nothing is
+	// written and ErrPromotionConflict is returned when a student is no
+	// longer active in the grade level the change starts from or already
+	// has a change on the same day.
+	Apply(ctx context.Context, changes []GradeChange, recordedBy string) error
+	// ListHistory returns the grade-level history of a student ordered by
+	// effective date
+	ListHistory(ctx context.Context, studentID string) ([]GradeChange, error)
+}
+
+// SubmissionRepository stores the work handed in for assignments. The
+// contents of attached files live in blob storage, not in the repository.
+type SubmissionRepository interface {
+	// Create stores a submission received now, with the status following
+	// from the due date of its assignment, and gives the student's grade for
+	// the assignment the same status. It fails with ErrTermFinalized for
+	// assignments due in a finalized term.
+	Create(ctx context.Context, submission *Submission) error
+	GetByID(ctx context.Context, id string) (*Submission, error)
+	// ListByAssignmentID and ListByStudentID return submissions with their
+	// files, most recent first. studentIDs, when not nil, restricts the
+	// submissions for an assignment to these students.
+	ListByAssignmentID(ctx context.Context, assignmentID string, studentIDs []string) ([]Submission, error)
+	ListByStudentID(ctx context.Context, studentID string) ([]Submission, error)
+}
+
+// AttendanceRepository stores attendance records
+type AttendanceRepository interface {
+	Create(ctx context.Context, attendance *Attendance) error
+	Update(ctx context.Context, attendance *Attendance) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*Attendance, error)
+	ListByStudentID(ctx context.Context, studentID string, opts ListOptions) (Page[Attendance], error)
+	// ListByDateRange returns the records between both dates; studentIDs,
+	// when not nil, restricts them to these students
+	ListByDateRange(ctx context.Context, startDate, endDate time.Time, studentIDs []string) ([]Attendance, error)
+	// Upsert creates or replaces the records in one transaction, matching
+	// existing records on student and date. created reports for each record
+	// whether it is new. Nothing is written if any record fails.
+	Upsert(ctx context.Context, records []*Attendance) (created []bool, err error)
+}
+
+// ForumRepository stores parent-teacher forum posts and comments
+type ForumRepository interface {
+	CreatePost(ctx context.Context, post *ForumPost) error
+	// UpdatePost only updates the post if it was written by post.AuthorID
+	UpdatePost(ctx context.Context, post *ForumPost) error
+	// DeletePost removes a post and its comments. A non-empty authorID
+	// restricts the deletion to posts written by that user.
+	DeletePost(ctx context.Context, id, authorID string) error
+	GetPostByID(ctx context.Context, id string) (*ForumPost, error)
+	ListPostsByStudentID(ctx context.Context, studentID string, opts ListOptions) (Page[ForumPost], error)
+	CreateComment(ctx context.Context, comment *ForumComment) error
+	// UpdateComment only updates the comment if it was written by comment.AuthorID
+	UpdateComment(ctx context.Context, comment *ForumComment) error
+	ListCommentsByPostID(ctx context.Context, postID string) ([]ForumComment, error)
+}
+
+// AuditRepository reads the audit log. Entries are appended by the student,
+// user, grade and attendance repositories in the same transaction as the
+// change they record, attributed with the actor and request ID of the
+// context.
+type AuditRepository interface {
+	List(ctx context.Context, opts ListOptions) (Page[AuditEntry], error)
+}
+
+// ImportRepository writes import files in bulk
+type ImportRepository interface {
+	// Import creates the students, users and parent links of the batch in
+	// one transaction. Rows that cannot be written are returned as
+	// ImportErrors and nothing is written; a dry run checks every row the
+	// same way and never writes anything.
+	Import(ctx context.Context, batch *ImportBatch, dryRun bool) error
+}
+
+// JobRepository stores the last run of each background job
+type JobRepository interface {
+	// SaveRun records a run, replacing the previous run of the same job
+	SaveRun(ctx context.Context, run *JobRun) error
+	// GetRun returns the last run of a job, or ErrNotFound if it never ran
+	GetRun(ctx context.Context, name string) (*JobRun, error)
+}
+
+// NotificationRepository stores the inbox and notification preferences of
+// users
+type NotificationRepository interface {
+	// Create stores a notification unless the user already has one of the
+	// same kind for the same entity, and reports whether it was stored
+	Create(ctx context.Context, notification *Notification) (bool, error)
+	// List returns a page of the notifications of a user
+	List(ctx context.Context, userID string, opts ListOptions) (Page[Notification], error)
+	// MarkRead marks a notification of the user as read. Notifications of
+	// other users are not found.
+	MarkRead(ctx context.Context, userID, id string) error
+	// MarkAllRead marks every unread notification of the user as read and
+	// returns how many there were
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	// GetPreferences returns the preferences of a user, or the defaults if
+	// they never saved any
+	GetPreferences(ctx context.Context, userID string) (*NotificationPreferences, error)
+	SavePreferences(ctx context.Context, preferences *NotificationPreferences) error
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// GenerateSecret returns a new random opaque token value and its hash.
+// Only the hash is ever persisted.
+func GenerateSecret() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(buf)
+	return value, HashSecret(value), nil
+}
+
+// HashSecret returns the hex encoded SHA-256 hash of a token value
+func HashSecret(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
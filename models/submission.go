@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Limits on what a submission may contain
+const (
+	// MaxSubmissionFiles is the number of files a submission may attach
+	MaxSubmissionFiles = 5
+	// MaxSubmissionFileSize is the size of the largest file accepted, in bytes
+	MaxSubmissionFileSize = 10 << 20
+	// MaxSubmissionTextLength is the length of the longest text answer, in bytes
+	MaxSubmissionTextLength = 20000
+)
+
+var (
+	// ErrSubmissionEmpty is returned when a submission has neither text nor files
+	ErrSubmissionEmpty = errors.New("a submission needs text or at least one file")
+	// ErrSubmissionFileTooLarge is returned when a file exceeds MaxSubmissionFileSize
+	ErrSubmissionFileTooLarge = fmt.Errorf("files may not be larger than %d MB", MaxSubmissionFileSize>>20)
+	// ErrSubmissionFileType is returned when a file is not of an accepted type
+	ErrSubmissionFileType = errors.New("files must be PDF, plain text, PNG, JPEG or GIF images, or Word, Excel, PowerPoint or OpenDocument documents")
+)
+
+// submissionFileTypes maps the content types sniffed from accepted files to
+// the type they are served with. Office documents are ZIP archives and are
+// told apart by their extension.
+var submissionFileTypes = map[string]string{
+	"application/pdf":           "application/pdf",
+	"text/plain; charset=utf-8": "text/plain; charset=utf-8",
+	"image/png":                 "image/png",
+	"image/jpeg":                "image/jpeg",
+	"image/gif":                 "image/gif",
+}
+
+var submissionDocumentTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+}
+
+// Submission is work handed in for an assignment on behalf of a student.
+// Its status is decided by when it was received: on or before the due date
+// it is completed, afterwards late. The student's grade for the assignment
+// takes the status of their latest submission.
+type Submission struct {
+	ID           string           `json:"id,omitempty"`
+	AssignmentID string           `json:"assignment_id"`
+	StudentID    string           `json:"student_id"`
+	Text         string           `json:"text,omitempty"`
+	Files        []SubmissionFile `json:"files"`
+	Status       AssignmentStatus `json:"status"`
+	SubmittedBy  string           `json:"submitted_by"` // ID of user who submitted the work
+	SubmittedAt  time.Time        `json:"submitted_at"`
+}
+
+// SubmissionFile is a file attached to a submission. Its content lives in
+// blob storage under Key.
+type SubmissionFile struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Key         string `json:"-"`
+}
+
+// Validate checks that the submission has something to hand in within the
+// limits
+func (s *Submission) Validate() error {
+	if s.AssignmentID == "" || s.StudentID == "" || s.SubmittedBy == "" {
+		return errors.New("invalid submission data")
+	}
+	if strings.TrimSpace(s.Text) == "" && len(s.Files) == 0 {
+		return ErrSubmissionEmpty
+	}
+	if len(s.Text) > MaxSubmissionTextLength {
+		return fmt.Errorf("text may not be longer than %d characters", MaxSubmissionTextLength)
+	}
+	if len(s.Files) > MaxSubmissionFiles {
+		return fmt.Errorf("a submission may not have more than %d files", MaxSubmissionFiles)
+	}
+	return nil
+}
+
+// SubmissionStatus returns the status of work received at submittedAt for
+// an assignment due at due
+func SubmissionStatus(submittedAt, due time.Time) AssignmentStatus {
+	if submittedAt.After(due) {
+		return AssignmentStatusLate
+	}
+	return AssignmentStatusCompleted
+}
+
+// SubmissionFileType checks the size of an uploaded file and its type,
+// sniffed from the first bytes of its content rather than trusted from the
+// client, and returns the content type it is served with
+func SubmissionFileType(name string, size int64, head []byte) (string, error) {
+	if size > MaxSubmissionFileSize {
+		return "", ErrSubmissionFileTooLarge
+	}
+	detected := http.DetectContentType(head)
+	if contentType, ok := submissionFileTypes[detected]; ok {
+		return contentType, nil
+	}
+	if detected == "application/zip" {
+		if contentType, ok := submissionDocumentTypes[strings.ToLower(filepath.Ext(name))]; ok {
+			return contentType, nil
+		}
+	}
+	return "", ErrSubmissionFileType
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrTermFinalized is returned when changing a finalized term or the grades
+// given during it
+var ErrTermFinalized = errors.New("term is finalized")
+
+// ErrTermOverlap is returned when the dates of an academic year or term
+// overlap another one
+var ErrTermOverlap = errors.New("dates overlap another academic year or term")
+
+// ErrTermOutsideYear is returned when a term does not fall within its
+// academic year
+var ErrTermOutsideYear = errors.New("term dates must fall within the academic year")
+
+// AcademicYear is a school year, divided into terms. Years do not overlap.
+type AcademicYear struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name" binding:"required"`
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"`
+	Terms     []Term    `json:"terms"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks the name and dates of the year and truncates the dates to
+// days
+func (y *AcademicYear) Validate() error {
+	if y.Name == "" {
+		return errors.New("year name is required")
+	}
+	return validateDays(&y.StartDate, &y.EndDate)
+}
+
+// Term is a grading period of an academic year. Attendance is resolved to
+// the term its date falls in and grades to the term their assignment is due
+// in. Both dates are inclusive and terms do not overlap.
+//
+// Finalizing a term locks its grades and takes the report-card snapshots of
+// its students.
+type Term struct {
+	ID             string     `json:"id,omitempty"`
+	AcademicYearID string     `json:"academic_year_id"`
+	Name           string     `json:"name" binding:"required"`
+	StartDate      time.Time  `json:"start_date" binding:"required"`
+	EndDate        time.Time  `json:"end_date" binding:"required"`
+	FinalizedAt    *time.Time `json:"finalized_at,omitempty"`
+	FinalizedBy    string     `json:"finalized_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at,omitempty"`
+}
+
+// Validate checks the name and dates of the term and truncates the dates to
+// days
+func (t *Term) Validate() error {
+	if t.Name == "" || t.AcademicYearID == "" {
+		return errors.New("invalid term data")
+	}
+	return validateDays(&t.StartDate, &t.EndDate)
+}
+
+// Finalized reports whether the term has been finalized
+func (t *Term) Finalized() bool {
+	return t.FinalizedAt != nil
+}
+
+// Contains reports whether the day of the given time falls in the term
+func (t *Term) Contains(date time.Time) bool {
+	day := Day(date)
+	return !day.Before(t.StartDate) && !day.After(t.EndDate)
+}
+
+// Period returns the bounds of the term as a half-open range: from is the
+// start date and to the day after the end date
+func (t *Term) Period() (from, to time.Time) {
+	return t.StartDate, t.EndDate.AddDate(0, 0, 1)
+}
+
+// Within reports whether the term falls within the academic year
+func (t *Term) Within(year *AcademicYear) bool {
+	return !t.StartDate.Before(year.StartDate) && !t.EndDate.After(year.EndDate)
+}
+
+// validateDays truncates both dates to days and checks they are set and in
+// order
+func validateDays(start, end *time.Time) error {
+	if start.IsZero() || end.IsZero() {
+		return errors.New("start_date and end_date are required")
+	}
+	*start, *end = Day(*start), Day(*end)
+	if end.Before(*start) {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// ReportCard is the report card of a student for a finalized term, taken
+// when the term was finalized and never changed afterwards. Data holds the
+// report as served in JSON and Document its layout for CSV and PDF.
+type ReportCard struct {
+	ID          string          `json:"id,omitempty"`
+	TermID      string          `json:"term_id"`
+	StudentID   string          `json:"student_id"`
+	StudentName string          `json:"student_name"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	Document    json.RawMessage `json:"-"`
+	CreatedAt   time.Time       `json:"created_at,omitempty"`
+}
@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// Message is an email to send
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// EmailSender delivers notifications to the email address of the user
+type EmailSender struct {
+	mailer Mailer
+}
+
+// NewEmailSender creates a sender mailing notifications through mailer
+func NewEmailSender(mailer Mailer) *EmailSender {
+	return &EmailSender{mailer: mailer}
+}
+
+// Channel returns models.ChannelEmail
+func (s *EmailSender) Channel() models.NotificationChannel {
+	return models.ChannelEmail
+}
+
+// Send mails the notification to the user
+func (s *EmailSender) Send(ctx context.Context, user models.User, preferences models.NotificationPreferences, n models.Notification) error {
+	if user.Email == "" {
+		return errors.New("the user has no email address")
+	}
+	return s.mailer.Send(ctx, Message{
+		To:      user.Email,
+		Subject: n.Title,
+		Body:    fmt.Sprintf("Hello %s,\n\n%s\n", user.FirstName, n.Body),
+	})
+}
+
+// SMTPMailer sends emails through an SMTP server, authenticating with PLAIN
+// when a username is set. The connection is upgraded with STARTTLS when the
+// server offers it.
+type SMTPMailer struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTPMailer creates a mailer sending from the given address through the
+// server at addr (host:port)
+func NewSMTPMailer(addr, from, username, password string) *SMTPMailer {
+	return &SMTPMailer{addr: addr, from: from, username: username, password: password}
+}
+
+// Send sends the message. The deadline of ctx bounds the whole session.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %s: %w", m.addr, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start the SMTP session: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	// PlainAuth refuses to send the password over an unencrypted
+	// connection to anything but localhost
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, host)); err != nil {
+			return fmt.Errorf("failed to authenticate with the SMTP server: %w", err)
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return fmt.Errorf("failed to set the sender: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("failed to set the recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start the message: %w", err)
+	}
+	if _, err := w.Write(m.format(msg)); err != nil {
+		return fmt.Errorf("failed to write the message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send the message: %w", err)
+	}
+	return client.Quit()
+}
+
+// format writes the message with its headers. Line breaks are removed from
+// the header values so they cannot add headers of their own.
+func (m *SMTPMailer) format(msg Message) []byte {
+	header := strings.NewReplacer("\r", " ", "\n", " ")
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", header.Replace(m.from))
+	fmt.Fprintf(&buf, "To: %s\r\n", header.Replace(msg.To))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", header.Replace(msg.Subject)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// StubMailer keeps the messages it is asked to send instead of sending
+// them, and logs them. It stands in for SMTP in tests and local setups.
+type StubMailer struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewStubMailer creates a mailer that sends nothing
+func NewStubMailer() *StubMailer {
+	return &StubMailer{}
+}
+
+// Send records the message
+func (m *StubMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log.Printf("Email to %s not sent (no SMTP server configured): %s", msg.To, msg.Subject)
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent returns the messages recorded so far
+func (m *StubMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.sent...)
+}
+
+// MailerFromEnv returns an SMTP mailer for SMTP_HOST, SMTP_PORT (default
+// 587), SMTP_FROM, SMTP_USERNAME and SMTP_PASSWORD, or a stub when SMTP_HOST
+// is unset
+func MailerFromEnv() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return NewStubMailer()
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@" + host
+	}
+	return NewSMTPMailer(net.JoinHostPort(host, port), from, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+}
@@ -0,0 +1,252 @@
+// Package notify tells parents about events concerning their children.
+// Every notification lands in the in-app inbox of each linked parent; it is
+// then delivered in the background through the channels the parent chose,
+// such as email and webhooks.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/metrics"
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+const (
+	// queueSize is how many deliveries may wait for a worker; further ones
+	// are dropped and only reach the inbox
+	queueSize = 256
+	// workers is the number of deliveries made at the same time
+	workers = 4
+	// deliveryTimeout bounds a single delivery
+	deliveryTimeout = 30 * time.Second
+)
+
+// dateFormat is how dates are written in notifications
+const dateFormat = "Monday, 2 January 2006"
+
+// Sender delivers notifications through one channel
+type Sender interface {
+	Channel() models.NotificationChannel
+	Send(ctx context.Context, user models.User, preferences models.NotificationPreferences, n models.Notification) error
+}
+
+// delivery is a notification waiting to be sent to a user
+type delivery struct {
+	sender       Sender
+	user         models.User
+	preferences  models.NotificationPreferences
+	notification models.Notification
+}
+
+// Notifier turns saved records into notifications. Its methods never fail
+// the save they are called after: problems are logged.
+type Notifier struct {
+	store   *models.Store
+	senders []Sender
+	// threshold is the percentage below which a grade is low
+	threshold float64
+	queue     chan delivery
+}
+
+// New creates a notifier writing inboxes to the store and delivering
+// through the senders
+func New(store *models.Store, senders ...Sender) *Notifier {
+	return &Notifier{store: store, senders: senders, threshold: lowGradeThreshold(), queue: make(chan delivery, queueSize)}
+}
+
+// DefaultSenders returns the email sender, through the mailer configured by
+// MailerFromEnv, and the webhook sender
+func DefaultSenders() []Sender {
+	return []Sender{NewEmailSender(MailerFromEnv()), NewWebhookSender(nil)}
+}
+
+// Start delivers queued notifications until the returned function is
+// called, which waits for deliveries in progress to return
+func (n *Notifier) Start(ctx context.Context) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-n.queue:
+					n.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	return func() {
+		cancel()
+		wg.Wait()
+		if pending := len(n.queue); pending > 0 {
+			log.Printf("Dropping %d undelivered notifications", pending)
+		}
+	}
+}
+
+// AttendanceSaved notifies the parents of a student recorded absent or tardy
+func (n *Notifier) AttendanceSaved(ctx context.Context, a models.Attendance) {
+	if a.Status != models.AttendanceStatusAbsent && a.Status != models.AttendanceStatusTardy {
+		return
+	}
+	student, err := n.store.Students.GetByID(ctx, a.StudentID)
+	if err != nil {
+		log.Printf("Error loading student %s for a notification: %v", a.StudentID, err)
+		return
+	}
+
+	n.notifyParents(ctx, models.Notification{
+		StudentID:  a.StudentID,
+		Kind:       models.NotificationAbsence,
+		Title:      fmt.Sprintf("%s was %s on %s", student.Name, a.Status, a.Date.Format("2 Jan 2006")),
+		Body:       fmt.Sprintf("%s was recorded %s on %s.", student.Name, a.Status, a.Date.Format(dateFormat)),
+		EntityType: "attendance",
+		EntityID:   a.ID,
+	}, "")
+}
+
+// GradeSaved notifies the parents of a student whose grade is marked
+// missing or falls below the low-grade threshold
+func (n *Notifier) GradeSaved(ctx context.Context, g models.Grade) {
+	low := g.MaxScore > 0 && g.Score/g.MaxScore*100 < n.threshold &&
+		(g.Status == models.AssignmentStatusCompleted || g.Status == models.AssignmentStatusLate)
+	if g.Status != models.AssignmentStatusMissing && !low {
+		return
+	}
+	student, err := n.store.Students.GetByID(ctx, g.StudentID)
+	if err != nil {
+		log.Printf("Error loading student %s for a notification: %v", g.StudentID, err)
+		return
+	}
+	assignment, err := n.store.Grades.GetAssignmentByID(ctx, g.AssignmentID)
+	if err != nil {
+		log.Printf("Error loading assignment %s for a notification: %v", g.AssignmentID, err)
+		return
+	}
+
+	notification := models.Notification{
+		StudentID:  g.StudentID,
+		Kind:       models.NotificationMissingAssignment,
+		Title:      fmt.Sprintf("%s is missing %s", student.Name, assignment.Title),
+		Body:       fmt.Sprintf("%s has not handed in %s in %s, which was due on %s.", student.Name, assignment.Title, assignment.Subject, assignment.DueDate.Format(dateFormat)),
+		EntityType: "grade",
+		EntityID:   g.ID,
+	}
+	if low {
+		percent := g.Score / g.MaxScore * 100
+		notification.Kind = models.NotificationLowGrade
+		notification.Title = fmt.Sprintf("%s scored %.0f%% on %s", student.Name, percent, assignment.Title)
+		notification.Body = fmt.Sprintf("%s scored %g out of %g (%.0f%%) on %s in %s.", student.Name, g.Score, g.MaxScore, percent, assignment.Title, assignment.Subject)
+	}
+	n.notifyParents(ctx, notification, "")
+}
+
+// CommentCreated notifies the parents of the student a forum post is about
+// of a new comment on it, except the parent who wrote it
+func (n *Notifier) CommentCreated(ctx context.Context, post models.ForumPost, comment models.ForumComment) {
+	author := "Someone"
+	if user, err := n.store.Users.GetByID(ctx, comment.AuthorID); err == nil {
+		author = user.FirstName + " " + user.LastName
+	}
+
+	n.notifyParents(ctx, models.Notification{
+		StudentID:  post.StudentID,
+		Kind:       models.NotificationForumComment,
+		Title:      fmt.Sprintf("New comment on %q", post.Title),
+		Body:       fmt.Sprintf("%s commented on %q:\n\n%s", author, post.Title, excerpt(comment.Content, 500)),
+		EntityType: "forum_comment",
+		EntityID:   comment.ID,
+	}, comment.AuthorID)
+}
+
+// notifyParents puts a copy of the notification in the inbox of each parent
+// of its student but the excluded user, and queues its deliveries. Parents
+// who already have it are skipped, so saving a record again notifies once.
+func (n *Notifier) notifyParents(ctx context.Context, template models.Notification, exclude string) {
+	parents, err := n.store.Users.ListParentsByStudentID(ctx, template.StudentID)
+	if err != nil {
+		log.Printf("Error loading the parents of student %s: %v", template.StudentID, err)
+		return
+	}
+
+	for _, parent := range parents {
+		if parent.ID == exclude {
+			continue
+		}
+		notification := template
+		notification.UserID = parent.ID
+		created, err := n.store.Notifications.Create(ctx, &notification)
+		if err != nil {
+			log.Printf("Error saving notification for user %s: %v", parent.ID, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		preferences, err := n.store.Notifications.GetPreferences(ctx, parent.ID)
+		if err != nil {
+			log.Printf("Error loading the notification preferences of user %s: %v", parent.ID, err)
+			continue
+		}
+		for _, sender := range n.senders {
+			if preferences.Wants(sender.Channel(), notification.Kind) {
+				n.enqueue(delivery{sender: sender, user: parent, preferences: *preferences, notification: notification})
+			}
+		}
+	}
+}
+
+// enqueue queues a delivery without blocking the caller
+func (n *Notifier) enqueue(d delivery) {
+	select {
+	case n.queue <- d:
+	default:
+		metrics.NotificationsDeliveredTotal.WithLabelValues(string(d.sender.Channel()), "dropped").Inc()
+		log.Printf("Notification queue full, dropping %s delivery of notification %s", d.sender.Channel(), d.notification.ID)
+	}
+}
+
+// deliver sends a queued notification
+func (n *Notifier) deliver(ctx context.Context, d delivery) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	channel := string(d.sender.Channel())
+	if err := d.sender.Send(ctx, d.user, d.preferences, d.notification); err != nil {
+		metrics.NotificationsDeliveredTotal.WithLabelValues(channel, "failed").Inc()
+		log.Printf("Error delivering notification %s by %s: %v", d.notification.ID, channel, err)
+		return
+	}
+	metrics.NotificationsDeliveredTotal.WithLabelValues(channel, "sent").Inc()
+}
+
+// excerpt shortens text to at most limit runes
+func excerpt(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+// lowGradeThreshold reads NOTIFY_LOW_GRADE_THRESHOLD, the percentage below
+// which grades are reported, defaulting to 60
+func lowGradeThreshold() float64 {
+	if value := os.Getenv("NOTIFY_LOW_GRADE_THRESHOLD"); value != "" {
+		if threshold, err := strconv.ParseFloat(value, 64); err == nil && threshold >= 0 {
+			return threshold
+		}
+	}
+	return 60
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/models/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that an absence reaches the inbox of the parent and is delivered by
+// email and webhook as the parent chose, once however often it is saved
+func TestNotifier_Deliver(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	student := &models.Student{Name: "Ann", Email: "ann@example.com", DateOfBirth: time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC), Grade: "5", IsActive: true}
+	require.NoError(t, store.Students.Create(ctx, student))
+	parent := &models.User{Username: "parent", Email: "parent@example.com", Password: "password", FirstName: "Pat", LastName: "Test", Role: models.RoleParent, StudentIDs: []string{student.ID}}
+	require.NoError(t, store.Users.Create(ctx, parent))
+
+	var mu sync.Mutex
+	var hooks []webhookPayload
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		mu.Lock()
+		hooks = append(hooks, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+	require.NoError(t, store.Notifications.SavePreferences(ctx, &models.NotificationPreferences{
+		UserID:     parent.ID,
+		Email:      []models.NotificationKind{models.NotificationAbsence},
+		Webhook:    []models.NotificationKind{models.NotificationAbsence},
+		WebhookURL: server.URL,
+	}))
+
+	mailer := NewStubMailer()
+	n := New(store, NewEmailSender(mailer), NewWebhookSender(server.Client()))
+	stop := n.Start(ctx)
+	defer stop()
+
+	absence := models.Attendance{ID: "a1", StudentID: student.ID, Date: time.Now(), Status: models.AttendanceStatusAbsent}
+	n.AttendanceSaved(ctx, absence)
+	n.AttendanceSaved(ctx, absence)
+	n.AttendanceSaved(ctx, models.Attendance{ID: "a2", StudentID: student.ID, Date: time.Now(), Status: models.AttendanceStatusPresent})
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(mailer.Sent()) > 0 && len(hooks) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	sent := mailer.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "parent@example.com", sent[0].To)
+	assert.Contains(t, sent[0].Subject, "Ann was absent")
+	require.Len(t, hooks, 1)
+	assert.Equal(t, models.NotificationAbsence, hooks[0].Event)
+	assert.Equal(t, "a1", hooks[0].Notification.EntityID)
+
+	page, err := store.Notifications.List(ctx, parent.ID, models.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+}
+
+// Test that webhooks cannot reach private addresses
+func TestIsPublic(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"192.168.0.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"::1":             false,
+		"fd00::1":         false,
+		"0.0.0.0":         false,
+	} {
+		assert.Equal(t, public, isPublic(net.ParseIP(address)), address)
+	}
+}
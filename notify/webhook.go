@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+// WebhookSender posts notifications as JSON to the webhook URL of the user
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a sender posting with client. A nil client uses
+// PublicClient, since webhook URLs are chosen by users.
+func NewWebhookSender(client *http.Client) *WebhookSender {
+	if client == nil {
+		client = PublicClient()
+	}
+	return &WebhookSender{client: client}
+}
+
+// Channel returns models.ChannelWebhook
+func (s *WebhookSender) Channel() models.NotificationChannel {
+	return models.ChannelWebhook
+}
+
+// webhookPayload is the body posted to webhooks
+type webhookPayload struct {
+	Event        models.NotificationKind `json:"event"`
+	Notification models.Notification     `json:"notification"`
+}
+
+// Send posts the notification to the webhook URL of the user and expects a
+// 2xx response
+func (s *WebhookSender) Send(ctx context.Context, user models.User, preferences models.NotificationPreferences, n models.Notification) error {
+	if preferences.WebhookURL == "" {
+		return errors.New("the user has no webhook URL")
+	}
+	body, err := json.Marshal(webhookPayload{Event: n.Kind, Notification: n})
+	if err != nil {
+		return fmt.Errorf("failed to encode the notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, preferences.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "student-api-notifications")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post the webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// PublicClient returns an HTTP client that only connects to public
+// addresses and does not follow redirects, so webhooks cannot be pointed at
+// the service's own network
+func PublicClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublic(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   deliveryTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublic reports whether the address is routable on the internet
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
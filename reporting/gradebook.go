@@ -0,0 +1,113 @@
+package reporting
+
+// Category is a weighted group of assignments counted by WeightedAverage
+type Category struct {
+	ID     string
+	Name   string
+	Weight float64
+}
+
+// CategoryAverage is a student's average within a category
+type CategoryAverage struct {
+	CategoryID string
+	Name       string
+	Weight     float64
+	// Share is the percentage of the term average the category makes up
+	// once the weights of the categories with grades are scaled to 100
+	Share   float64
+	Average float64
+	Graded  int
+}
+
+// TermAverage is a student's weighted average over the graded assignments
+// of a term
+type TermAverage struct {
+	Average float64
+	// Graded counts the grades in the average, Missing the missing
+	// assignments whether counted or not, and Uncategorized the grades left
+	// out because their assignment has no category
+	Graded        int
+	Missing       int
+	Uncategorized int
+	Categories    []CategoryAverage
+}
+
+// WeightedAverage computes a term average from a student's grades. Each
+// category is averaged by points, and the category averages are weighted
+// by the category weights. Categories without grades do not count, so the
+// weights of the others are scaled to add up to 100%.
+//
+// Without categories the average is the points average of every grade.
+// With categories, grades of uncategorized assignments are not counted.
+// Assignments still assigned are not graded yet and are skipped; missing
+// ones count as zero unless excludeMissing is set.
+func WeightedAverage(grades []ClassGrade, categories []Category, excludeMissing bool) TermAverage {
+	var term TermAverage
+
+	// Without categories every grade falls into one implicit category
+	implicit := len(categories) == 0
+	if implicit {
+		categories = []Category{{Weight: 1}}
+	}
+	index := make(map[string]int, len(categories))
+	for i, category := range categories {
+		index[category.ID] = i
+	}
+
+	scores := make([]float64, len(categories))
+	possible := make([]float64, len(categories))
+	graded := make([]int, len(categories))
+	for _, grade := range grades {
+		if grade.Status == "assigned" || grade.MaxScore <= 0 {
+			continue
+		}
+		score := grade.Score
+		if grade.Status == "missing" {
+			term.Missing++
+			if excludeMissing {
+				continue
+			}
+			score = 0
+		}
+
+		categoryID := grade.CategoryID
+		if implicit {
+			categoryID = ""
+		}
+		i, ok := index[categoryID]
+		if !ok {
+			term.Uncategorized++
+			continue
+		}
+		scores[i] += score
+		possible[i] += grade.MaxScore
+		graded[i]++
+		term.Graded++
+	}
+
+	var totalWeight float64
+	for i, category := range categories {
+		if graded[i] > 0 {
+			totalWeight += category.Weight
+		}
+	}
+
+	for i, category := range categories {
+		average := CategoryAverage{
+			CategoryID: category.ID,
+			Name:       category.Name,
+			Weight:     category.Weight,
+			Graded:     graded[i],
+		}
+		if graded[i] > 0 {
+			average.Average = scores[i] / possible[i] * 100
+			average.Share = category.Weight / totalWeight * 100
+			term.Average += average.Average * average.Share / 100
+		}
+		if !implicit {
+			term.Categories = append(term.Categories, average)
+		}
+	}
+
+	return term
+}
@@ -0,0 +1,53 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that categories are weighted, renormalized when some have no grades
+// and that missing assignments follow the policy
+func TestWeightedAverage(t *testing.T) {
+	categories := []Category{
+		{ID: "hw", Name: "Homework", Weight: 40},
+		{ID: "exam", Name: "Exams", Weight: 60},
+		{ID: "quiz", Name: "Quizzes", Weight: 20},
+	}
+	grades := []ClassGrade{
+		{AssignmentID: "a1", CategoryID: "hw", Score: 8, MaxScore: 10, Status: "completed"},
+		{AssignmentID: "a2", CategoryID: "hw", Score: 0, MaxScore: 10, Status: "missing"},
+		{AssignmentID: "a3", CategoryID: "exam", Score: 45, MaxScore: 50, Status: "late"},
+		{AssignmentID: "a4", CategoryID: "exam", Score: 0, MaxScore: 50, Status: "assigned"},
+		{AssignmentID: "a5", Score: 1, MaxScore: 10, Status: "completed"},
+	}
+
+	// Homework 8/20 = 40%, exams 90%; quizzes have no grades so the weights
+	// are 40% and 60%
+	term := WeightedAverage(grades, categories, false)
+	assert.InDelta(t, 0.4*40+0.6*90, term.Average, 1e-9)
+	assert.Equal(t, 3, term.Graded)
+	assert.Equal(t, 1, term.Missing)
+	assert.Equal(t, 1, term.Uncategorized)
+	assert.Equal(t, []CategoryAverage{
+		{CategoryID: "hw", Name: "Homework", Weight: 40, Share: 40, Average: 40, Graded: 2},
+		{CategoryID: "exam", Name: "Exams", Weight: 60, Share: 60, Average: 90, Graded: 1},
+		{CategoryID: "quiz", Name: "Quizzes", Weight: 20},
+	}, term.Categories)
+
+	// Excluding missing work leaves homework at 80%
+	term = WeightedAverage(grades, categories, true)
+	assert.InDelta(t, 0.4*80+0.6*90, term.Average, 1e-9)
+	assert.Equal(t, 2, term.Graded)
+	assert.Equal(t, 1, term.Missing)
+
+	// Without categories every grade counts by points: 54 of 80
+	term = WeightedAverage(grades, nil, false)
+	assert.InDelta(t, 54.0/80*100, term.Average, 1e-9)
+	assert.Equal(t, 4, term.Graded)
+	assert.Empty(t, term.Categories)
+
+	term = WeightedAverage(nil, categories, false)
+	assert.Zero(t, term.Graded)
+	assert.Zero(t, term.Average)
+}
@@ -0,0 +1,286 @@
+package reporting
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// Postgres computes report data with aggregate queries
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres returns a Repository reading from the given database
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// gradeBefore selects the grade level student s had on the last day before
+// the date or timestamp to, from the first grade change taking effect on or
+// after it. Without such a change, or when to is NULL, it is the current
+// grade level.
+func gradeBefore(to string) string {
+	return `COALESCE((SELECT h.from_grade FROM student_grade_history h
+					WHERE h.student_id = s.id AND h.effective_date >= ` + to + `
+					ORDER BY h.effective_date LIMIT 1), s.grade)`
+}
+
+// AttendanceSummaries summarizes attendance per student in a single query.
+// Absence streaks are found by numbering each student's records twice, once
+// overall and once among records of the same kind: the difference is
+// constant within a run of consecutive absences.
+func (r *Postgres) AttendanceSummaries(ctx context.Context, filter AttendanceFilter) ([]AttendanceSummary, error) {
+	query := `WITH roster AS (
+				SELECT s.id, s.name, s.grade, s.is_active
+				FROM (
+					SELECT s.id, s.name, ` + gradeBefore("$2") + ` AS grade, s.is_active
+					FROM students s
+					WHERE s.archived_at IS NULL
+						AND ($4::text = '' OR s.id IN (SELECT cs.student_id FROM class_students cs WHERE cs.class_id = $4))
+						AND ($5::text = '' OR s.id = $5)
+						AND ($6::text[] IS NULL OR s.id = ANY($6))
+				) s
+				WHERE $3::text = '' OR s.grade = $3
+			), records AS (
+				SELECT a.student_id, a.date, a.status,
+					ROW_NUMBER() OVER (PARTITION BY a.student_id ORDER BY a.date)
+						- ROW_NUMBER() OVER (PARTITION BY a.student_id, a.status = 'absent' ORDER BY a.date) AS run
+				FROM attendance a
+				JOIN roster ON roster.id = a.student_id
+				WHERE a.date >= $1 AND a.date < $2
+			), streaks AS (
+				SELECT student_id, COUNT(*) AS length, MAX(date) AS ended
+				FROM records
+				WHERE status = 'absent'
+				GROUP BY student_id, run
+			), streak_stats AS (
+				SELECT streaks.student_id,
+					MAX(streaks.length) AS longest,
+					COALESCE(MAX(streaks.length) FILTER (WHERE streaks.ended = latest.date), 0) AS current
+				FROM streaks
+				JOIN (SELECT student_id, MAX(date) AS date FROM records GROUP BY student_id) latest
+					ON latest.student_id = streaks.student_id
+				GROUP BY streaks.student_id
+			)
+			SELECT roster.id, roster.name, roster.grade,
+				COUNT(records.status) FILTER (WHERE records.status = 'present'),
+				COUNT(records.status) FILTER (WHERE records.status = 'absent'),
+				COUNT(records.status) FILTER (WHERE records.status = 'tardy'),
+				COUNT(records.status) FILTER (WHERE records.status = 'excused'),
+				COUNT(records.status),
+				COALESCE(MAX(streak_stats.longest), 0),
+				COALESCE(MAX(streak_stats.current), 0)
+			FROM roster
+			LEFT JOIN records ON records.student_id = roster.id
+			LEFT JOIN streak_stats ON streak_stats.student_id = roster.id
+			GROUP BY roster.id, roster.name, roster.grade, roster.is_active
+			HAVING roster.is_active OR COUNT(records.status) > 0
+			ORDER BY roster.name, roster.id`
+	log.Printf("Executing SELECT query: %s", query)
+
+	rows, err := r.db.QueryContext(ctx, query, filter.From, filter.To, filter.Grade, filter.ClassID, filter.StudentID, pq.Array(filter.StudentIDs))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []AttendanceSummary{}
+	for rows.Next() {
+		var s AttendanceSummary
+		err := rows.Scan(
+			&s.StudentID,
+			&s.StudentName,
+			&s.Grade,
+			&s.Present,
+			&s.Absent,
+			&s.Tardy,
+			&s.Excused,
+			&s.Total,
+			&s.LongestAbsenceStreak,
+			&s.CurrentAbsenceStreak,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan attendance summary row: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating attendance summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GradeSummaries summarizes the grades of every student in a single grouped query
+func (r *Postgres) GradeSummaries(ctx context.Context, filter GradeFilter) ([]GradeSummary, error) {
+	query := `SELECT s.id, s.name, ` + gradeBefore("$2::timestamptz") + `,
+				COUNT(g.id),
+				COUNT(g.id) FILTER (WHERE g.status = 'missing'),
+				COALESCE(SUM(g.score) FILTER (WHERE g.status <> 'missing'), 0),
+				COALESCE(SUM(g.max_score) FILTER (WHERE g.status <> 'missing'), 0),
+				COALESCE(MAX(g.score) FILTER (WHERE g.status <> 'missing'), 0),
+				COALESCE(MIN(g.score) FILTER (WHERE g.status <> 'missing'), 0)
+			FROM students s
+			LEFT JOIN (grades g JOIN assignments a ON a.id = g.assignment_id
+					AND ($1::timestamptz IS NULL OR a.due_date >= $1)
+					AND ($2::timestamptz IS NULL OR a.due_date < $2))
+				ON g.student_id = s.id
+			WHERE s.archived_at IS NULL AND ($3::text[] IS NULL OR s.id = ANY($3))
+			GROUP BY s.id
+			ORDER BY s.name, s.id`
+	log.Printf("Executing SELECT query: %s", query)
+
+	from, to := filter.bounds()
+	rows, err := r.db.QueryContext(ctx, query, from, to, pq.Array(filter.StudentIDs))
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []GradeSummary{}
+	for rows.Next() {
+		var s GradeSummary
+		err := rows.Scan(
+			&s.StudentID,
+			&s.StudentName,
+			&s.Grade,
+			&s.Assignments,
+			&s.Missing,
+			&s.TotalScore,
+			&s.TotalPossible,
+			&s.Highest,
+			&s.Lowest,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan grade summary row: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating grade summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// StudentGrades returns the grades of a student joined with their assignments
+func (r *Postgres) StudentGrades(ctx context.Context, studentID string, filter GradeFilter) ([]GradeDetail, error) {
+	query := `SELECT g.id, g.assignment_id, a.title, a.subject, a.due_date,
+				g.score, g.max_score, g.status, g.feedback, g.updated_at
+			FROM grades g
+			JOIN assignments a ON a.id = g.assignment_id
+			WHERE g.student_id = $1
+				AND ($2::timestamptz IS NULL OR a.due_date >= $2)
+				AND ($3::timestamptz IS NULL OR a.due_date < $3)
+			ORDER BY a.due_date, a.title`
+	log.Printf("Executing SELECT query: %s", query)
+
+	from, to := filter.bounds()
+	rows, err := r.db.QueryContext(ctx, query, studentID, from, to)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	details := []GradeDetail{}
+	for rows.Next() {
+		var d GradeDetail
+		err := rows.Scan(
+			&d.GradeID,
+			&d.AssignmentID,
+			&d.Assignment,
+			&d.Subject,
+			&d.DueDate,
+			&d.Score,
+			&d.MaxScore,
+			&d.Status,
+			&d.Feedback,
+			&d.GradedAt,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan grade detail row: %w", err)
+		}
+		details = append(details, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating grade detail rows: %w", err)
+	}
+
+	return details, nil
+}
+
+// ClassGradebook reads the roster of a class and its grades in a single
+// query. Students without grades come back as one row without an
+// assignment.
+func (r *Postgres) ClassGradebook(ctx context.Context, classID string, filter GradeFilter) ([]StudentGradebook, error) {
+	query := `SELECT s.id, s.name, ` + gradeBefore("$3::timestamptz") + `,
+				COALESCE(g.assignment_id, ''), COALESCE(a.category_id, ''),
+				COALESCE(g.score, 0), COALESCE(g.max_score, 0), COALESCE(g.status, '')
+			FROM class_students cs
+			JOIN students s ON s.id = cs.student_id
+			LEFT JOIN (grades g JOIN assignments a ON a.id = g.assignment_id AND a.class_id = $1
+					AND ($2::timestamptz IS NULL OR a.due_date >= $2)
+					AND ($3::timestamptz IS NULL OR a.due_date < $3))
+				ON g.student_id = s.id
+			WHERE cs.class_id = $1 AND s.archived_at IS NULL
+			ORDER BY s.name, s.id, a.due_date, a.title`
+	log.Printf("Executing SELECT query: %s", query)
+
+	from, to := filter.bounds()
+	rows, err := r.db.QueryContext(ctx, query, classID, from, to)
+	if err != nil {
+		log.Printf("Error executing SELECT: %v", err)
+		return nil, fmt.Errorf("failed to execute select query: %w", err)
+	}
+	defer rows.Close()
+
+	students := []StudentGradebook{}
+	for rows.Next() {
+		var s StudentGradebook
+		var g ClassGrade
+		err := rows.Scan(
+			&s.StudentID,
+			&s.StudentName,
+			&s.Grade,
+			&g.AssignmentID,
+			&g.CategoryID,
+			&g.Score,
+			&g.MaxScore,
+			&g.Status,
+		)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return nil, fmt.Errorf("failed to scan class grade row: %w", err)
+		}
+		if n := len(students); n == 0 || students[n-1].StudentID != s.StudentID {
+			students = append(students, s)
+		}
+		if g.AssignmentID != "" {
+			last := &students[len(students)-1]
+			last.Grades = append(last.Grades, g)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("Error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating class grade rows: %w", err)
+	}
+
+	return students, nil
+}
@@ -0,0 +1,171 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	attendanceSummaryColumns = []string{"id", "name", "grade", "present", "absent", "tardy", "excused", "total", "longest", "current"}
+	gradeSummaryColumns      = []string{"id", "name", "grade", "assignments", "missing", "total_score", "total_possible", "highest", "lowest"}
+	gradeDetailColumns       = []string{"id", "assignment_id", "title", "subject", "due_date", "score", "max_score", "status", "feedback", "updated_at"}
+	classGradeColumns        = []string{"id", "name", "grade", "assignment_id", "category_id", "score", "max_score", "status"}
+)
+
+// Test that grade summaries are read from one grouped query over the
+// assignments due in the period, for the students in scope only
+func TestPostgres_GradeSummaries(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	filter := GradeFilter{From: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), StudentIDs: []string{"s1", "s2"}}
+	mock.ExpectQuery(`SELECT s.id, s.name, COALESCE\(\(SELECT h.from_grade FROM student_grade_history h WHERE h.student_id = s.id AND h.effective_date >= \$2::timestamptz .*\), s.grade\), .* FROM students s LEFT JOIN \(grades g JOIN assignments a ON a.id = g.assignment_id .* a.due_date >= \$1\) AND .* a.due_date < \$2\)\) ON g.student_id = s.id WHERE s.archived_at IS NULL AND \(\$3::text\[\] IS NULL OR s.id = ANY\(\$3\)\) GROUP BY s.id`).
+		WithArgs(filter.From, nil, pq.Array(filter.StudentIDs)).
+		WillReturnRows(sqlmock.NewRows(gradeSummaryColumns).
+			AddRow("s1", "Ann", "5", 3, 1, 17.0, 20.0, 9.0, 8.0).
+			AddRow("s2", "Bob", "5", 0, 0, 0.0, 0.0, 0.0, 0.0))
+
+	summaries, err := NewPostgres(mockDB).GradeSummaries(context.Background(), filter)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, GradeSummary{StudentID: "s1", StudentName: "Ann", Grade: "5", Assignments: 3, Missing: 1, TotalScore: 17, TotalPossible: 20, Highest: 9, Lowest: 8}, summaries[0])
+	assert.Equal(t, 85.0, summaries[0].Average())
+	assert.Equal(t, 0.0, summaries[1].Average())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test that attendance is summarized for the filtered students over the
+// date range
+func TestPostgres_AttendanceSummaries(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	filter := AttendanceFilter{
+		From:    time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		ClassID: "c1",
+	}
+	mock.ExpectQuery(`WITH roster AS \(.* FROM students s .* FROM class_students cs .* WHERE a.date >= \$1 AND a.date < \$2 .* HAVING roster.is_active OR COUNT\(records.status\) > 0`).
+		WithArgs(filter.From, filter.To, "", "c1", "", nil).
+		WillReturnRows(sqlmock.NewRows(attendanceSummaryColumns).
+			AddRow("s1", "Ann", "5", 7, 3, 1, 1, 12, 2, 1).
+			AddRow("s2", "Ann", "6", 0, 0, 0, 0, 0, 0, 0))
+
+	summaries, err := NewPostgres(mockDB).AttendanceSummaries(context.Background(), filter)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2, "students with the same name are kept apart")
+	assert.Equal(t, AttendanceSummary{StudentID: "s1", StudentName: "Ann", Grade: "5", Present: 7, Absent: 3, Tardy: 1, Excused: 1, Total: 12, LongestAbsenceStreak: 2, CurrentAbsenceStreak: 1}, summaries[0])
+	assert.InDelta(t, 66.67, summaries[0].AttendanceRate(), 0.01)
+	assert.True(t, summaries[0].ChronicallyAbsent(ChronicAbsenceThreshold))
+	assert.False(t, summaries[1].ChronicallyAbsent(ChronicAbsenceThreshold), "students without records are not chronically absent")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Test that the rows of the class gradebook are grouped by student and
+// students without grades are kept
+func TestPostgres_ClassGradebook(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	mock.ExpectQuery(`FROM class_students cs JOIN students s .* LEFT JOIN \(grades g JOIN assignments a ON a.id = g.assignment_id AND a.class_id = \$1 AND`).
+		WithArgs("c1", nil, nil).
+		WillReturnRows(sqlmock.NewRows(classGradeColumns).
+			AddRow("s1", "Ann", "5", "a1", "hw", 8.0, 10.0, "completed").
+			AddRow("s1", "Ann", "5", "a2", "", 0.0, 10.0, "missing").
+			AddRow("s2", "Bob", "5", "", "", 0.0, 0.0, ""))
+
+	students, err := NewPostgres(mockDB).ClassGradebook(context.Background(), "c1", GradeFilter{})
+	require.NoError(t, err)
+	require.Len(t, students, 2)
+	assert.Equal(t, []ClassGrade{
+		{AssignmentID: "a1", CategoryID: "hw", Score: 8, MaxScore: 10, Status: "completed"},
+		{AssignmentID: "a2", MaxScore: 10, Status: "missing"},
+	}, students[0].Grades)
+	assert.Equal(t, StudentGradebook{StudentID: "s2", StudentName: "Bob", Grade: "5"}, students[1])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// BenchmarkReportQueries builds the data of the four reports for a growing
+// number of students and reports the queries issued. The count must not
+// depend on the number of students.
+func BenchmarkReportQueries(b *testing.B) {
+	for _, students := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("students=%d", students), func(b *testing.B) {
+			var queries int
+			countQueries := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+				queries++
+				return sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL)
+			})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(countQueries))
+				if err != nil {
+					b.Fatal(err)
+				}
+				attendance, grades, details, gradebook := reportRows(students)
+				mock.ExpectQuery(`FROM attendance a`).WillReturnRows(attendance)
+				mock.ExpectQuery(`FROM students s`).WillReturnRows(grades)
+				mock.ExpectQuery(`FROM grades g`).WillReturnRows(details)
+				mock.ExpectQuery(`FROM class_students cs`).WillReturnRows(gradebook)
+				repo := NewPostgres(mockDB)
+				ctx := context.Background()
+				b.StartTimer()
+
+				if _, err := repo.AttendanceSummaries(ctx, AttendanceFilter{To: time.Now()}); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.GradeSummaries(ctx, GradeFilter{}); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.StudentGrades(ctx, "s0", GradeFilter{}); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.ClassGradebook(ctx, "c0", GradeFilter{}); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				if err := mock.ExpectationsWereMet(); err != nil {
+					b.Fatal(err)
+				}
+				mockDB.Close()
+				b.StartTimer()
+			}
+
+			if queries != 4*b.N {
+				b.Fatalf("%d queries for %d runs, want 4 per run", queries, b.N)
+			}
+			b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// reportRows returns a row per student for each report query. Rows are
+// consumed when read, so every query needs new ones.
+func reportRows(students int) (attendance, grades, details, gradebook *sqlmock.Rows) {
+	attendance = sqlmock.NewRows(attendanceSummaryColumns)
+	grades = sqlmock.NewRows(gradeSummaryColumns)
+	details = sqlmock.NewRows(gradeDetailColumns)
+	gradebook = sqlmock.NewRows(classGradeColumns)
+	for i := 0; i < students; i++ {
+		id := fmt.Sprintf("s%d", i)
+		attendance.AddRow(id, "Student "+id, "5", 18, 1, 1, 0, 20, 1, 0)
+		grades.AddRow(id, "Student "+id, "5", 10, 1, 81.0, 90.0, 10.0, 7.0)
+		details.AddRow(fmt.Sprintf("g%d", i), fmt.Sprintf("a%d", i), "Essay", "English", time.Now(), 9.0, 10.0, "completed", "", time.Now())
+		gradebook.AddRow(id, "Student "+id, "5", "a0", "hw", 9.0, 10.0, "completed")
+	}
+	return attendance, grades, details, gradebook
+}
@@ -0,0 +1,183 @@
+// Package reporting computes the aggregates behind the reports. Each report
+// is answered by a fixed number of grouped queries however many students
+// there are, instead of one query per student or per grade.
+//
+// The package only depends on the standard library so that models.Store can
+// hold a Repository; statuses are passed as their string values.
+package reporting
+
+import (
+	"context"
+	"time"
+)
+
+// Repository computes report data. Results cover every student; callers
+// restrict them to the students a user may see. Students are reported with
+// the grade level they had at the end of the period, following their
+// grade-level history.
+type Repository interface {
+	// AttendanceSummaries summarizes the attendance of the students matching
+	// the filter, ordered by name. Every active student is listed, with or
+	// without records; inactive students only when they have records.
+	AttendanceSummaries(ctx context.Context, filter AttendanceFilter) ([]AttendanceSummary, error)
+	// GradeSummaries summarizes the grades matching the filter of every
+	// student that is not archived, ordered by name
+	GradeSummaries(ctx context.Context, filter GradeFilter) ([]GradeSummary, error)
+	// StudentGrades returns the grades of a student matching the filter with
+	// their assignments, ordered by due date
+	StudentGrades(ctx context.Context, studentID string, filter GradeFilter) ([]GradeDetail, error)
+	// ClassGradebook returns the students on the roster of a class who are
+	// not archived, ordered by name, each with the grades matching the
+	// filter given for the assignments of the class
+	ClassGradebook(ctx context.Context, classID string, filter GradeFilter) ([]StudentGradebook, error)
+}
+
+// ChronicAbsenceThreshold is the attendance rate, in percent, below which a
+// student is chronically absent
+const ChronicAbsenceThreshold = 90.0
+
+// AttendanceFilter selects the records and students of an attendance
+// report
+type AttendanceFilter struct {
+	// From and To bound the dates of the records; From is inclusive and To
+	// exclusive
+	From time.Time
+	To   time.Time
+	// Grade, ClassID and StudentID restrict the students to a grade level,
+	// the roster of a class or a single student when set
+	Grade     string
+	ClassID   string
+	StudentID string
+	// StudentIDs, when not nil, restricts the students to these
+	StudentIDs []string
+}
+
+// GradeFilter selects grades by the due date of their assignment. From is
+// inclusive and To exclusive; a zero bound leaves that side open.
+type GradeFilter struct {
+	From time.Time
+	To   time.Time
+	// StudentIDs, when not nil, restricts GradeSummaries to these students
+	StudentIDs []string
+}
+
+// Contains reports whether an assignment due at the given time matches the
+// filter
+func (f GradeFilter) Contains(due time.Time) bool {
+	return (f.From.IsZero() || !due.Before(f.From)) && (f.To.IsZero() || due.Before(f.To))
+}
+
+// bounds returns the bounds of the filter as query arguments, nil for the
+// open sides
+func (f GradeFilter) bounds() (from, to interface{}) {
+	if !f.From.IsZero() {
+		from = f.From
+	}
+	if !f.To.IsZero() {
+		to = f.To
+	}
+	return from, to
+}
+
+// AttendanceSummary counts the attendance records of a student by status.
+// Absence streaks count consecutive records marked absent: the longest in
+// the period and the one the student's latest record belongs to.
+type AttendanceSummary struct {
+	StudentID            string
+	StudentName          string
+	Grade                string
+	Present              int
+	Absent               int
+	Tardy                int
+	Excused              int
+	Total                int
+	LongestAbsenceStreak int
+	CurrentAbsenceStreak int
+}
+
+// PercentPresent returns the share of records marked present
+func (s AttendanceSummary) PercentPresent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Present) / float64(s.Total) * 100
+}
+
+// AttendanceRate returns the share of records where the student attended,
+// on time or late
+func (s AttendanceSummary) AttendanceRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Present+s.Tardy) / float64(s.Total) * 100
+}
+
+// ChronicallyAbsent reports whether the student attended less than
+// threshold percent of the recorded days. Students without records are not.
+func (s AttendanceSummary) ChronicallyAbsent(threshold float64) bool {
+	return s.Total > 0 && s.AttendanceRate() < threshold
+}
+
+// GradeSummary summarizes the grades of a student. Missing assignments are
+// counted but left out of the average, highest and lowest scores.
+type GradeSummary struct {
+	StudentID   string
+	StudentName string
+	Grade       string
+	Assignments int
+	Missing     int
+	// TotalScore and TotalPossible add up the graded assignments
+	TotalScore    float64
+	TotalPossible float64
+	Highest       float64
+	Lowest        float64
+}
+
+// Average returns the total score as a percentage of the possible score
+func (s GradeSummary) Average() float64 {
+	if s.TotalPossible == 0 {
+		return 0
+	}
+	return s.TotalScore / s.TotalPossible * 100
+}
+
+// GradeDetail is a grade together with the assignment it was given for
+type GradeDetail struct {
+	GradeID      string
+	AssignmentID string
+	Assignment   string
+	Subject      string
+	DueDate      time.Time
+	Score        float64
+	MaxScore     float64
+	Status       string
+	Feedback     string
+	GradedAt     time.Time
+}
+
+// Percentage returns the score as a percentage of the maximum score
+func (d GradeDetail) Percentage() float64 {
+	if d.MaxScore == 0 {
+		return 0
+	}
+	return d.Score / d.MaxScore * 100
+}
+
+// StudentGradebook is a student on a class roster with their grades in the
+// class
+type StudentGradebook struct {
+	StudentID   string
+	StudentName string
+	Grade       string
+	Grades      []ClassGrade
+}
+
+// ClassGrade is a grade given for an assignment of a class, with the
+// grading category of the assignment
+type ClassGrade struct {
+	AssignmentID string
+	CategoryID   string
+	Score        float64
+	MaxScore     float64
+	Status       string
+}
@@ -5,13 +5,18 @@ import (
 	"net/http"
 	"time"
 
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/metrics"
 	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 )
 
+// invalidAttendanceStatus explains why a status was rejected
+const invalidAttendanceStatus = "status must be present, absent, tardy or excused"
+
 // createAttendanceRecord creates a new attendance record
-func createAttendanceRecord(c *gin.Context) {
+func (h *Handler) createAttendanceRecord(c *gin.Context) {
 	log.Println("Creating a new attendance record...")
 	var attendance models.Attendance
 	if err := c.ShouldBindJSON(&attendance); err != nil {
@@ -23,11 +28,21 @@ func createAttendanceRecord(c *gin.Context) {
 		return
 	}
 	
+	if !attendance.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid attendance status.", "error": invalidAttendanceStatus})
+		return
+	}
+
+	// Faculty may only record attendance for students in their classes
+	if !middleware.CheckStudentAccess(c, attendance.StudentID) {
+		return
+	}
+
 	// Set the user who recorded this attendance
 	user := middleware.GetUserFromContext(c)
 	attendance.RecordedBy = user.ID
 
-	if err := attendance.Save(); err != nil {
+	if err := h.store.Attendance.Create(c.Request.Context(), &attendance); err != nil {
 		log.Println("Error saving attendance:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create attendance record. Try again later.",
@@ -36,6 +51,9 @@ func createAttendanceRecord(c *gin.Context) {
 		return
 	}
 
+	metrics.AttendanceRecordedTotal.WithLabelValues(string(attendance.Status)).Inc()
+	h.notifier.AttendanceSaved(c.Request.Context(), attendance)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":    "Attendance record created successfully!",
 		"attendance": attendance,
@@ -43,32 +61,40 @@ func createAttendanceRecord(c *gin.Context) {
 }
 
 // getAttendanceByID retrieves an attendance record by ID
-func getAttendanceByID(c *gin.Context) {
+func (h *Handler) getAttendanceByID(c *gin.Context) {
 	id := c.Param("id")
 	
-	attendance, err := models.GetAttendanceByID(id)
+	attendance, err := h.store.Attendance.GetByID(c.Request.Context(), id)
 	if err != nil {
 		log.Println("Error fetching attendance:", err)
 		c.JSON(http.StatusNotFound, gin.H{"message": "Attendance record not found."})
 		return
 	}
 
+	if !middleware.CheckStudentAccess(c, attendance.StudentID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"attendance": attendance,
 	})
 }
 
 // updateAttendanceRecord updates an existing attendance record
-func updateAttendanceRecord(c *gin.Context) {
+func (h *Handler) updateAttendanceRecord(c *gin.Context) {
 	id := c.Param("id")
 	
 	// Check if record exists
-	existingRecord, err := models.GetAttendanceByID(id)
+	existingRecord, err := h.store.Attendance.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Attendance record not found."})
 		return
 	}
 
+	if !middleware.CheckStudentAccess(c, existingRecord.StudentID) {
+		return
+	}
+
 	var attendance models.Attendance
 	if err := c.ShouldBindJSON(&attendance); err != nil {
 		log.Println("Error binding JSON:", err)
@@ -79,11 +105,20 @@ func updateAttendanceRecord(c *gin.Context) {
 		return
 	}
 
+	if !attendance.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid attendance status.", "error": invalidAttendanceStatus})
+		return
+	}
+
 	// Preserve the record ID and creator
 	attendance.ID = existingRecord.ID
 	attendance.RecordedBy = existingRecord.RecordedBy
 
-	if err := attendance.Update(); err != nil {
+	if attendance.StudentID != existingRecord.StudentID && !middleware.CheckStudentAccess(c, attendance.StudentID) {
+		return
+	}
+
+	if err := h.store.Attendance.Update(c.Request.Context(), &attendance); err != nil {
 		log.Println("Error updating attendance:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not update attendance record. Try again later.",
@@ -91,6 +126,7 @@ func updateAttendanceRecord(c *gin.Context) {
 		})
 		return
 	}
+	h.notifier.AttendanceSaved(c.Request.Context(), attendance)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":    "Attendance record updated successfully!",
@@ -98,11 +134,24 @@ func updateAttendanceRecord(c *gin.Context) {
 	})
 }
 
-// getAttendanceByStudentID retrieves all attendance records for a student
-func getAttendanceByStudentID(c *gin.Context) {
+// getAttendanceByStudentID retrieves the attendance records of a student,
+// limited with termId to the days of a term
+func (h *Handler) getAttendanceByStudentID(c *gin.Context) {
 	studentID := c.Param("studentId")
 	
-	attendance, err := models.GetAttendanceByStudentID(studentID)
+	opts, ok := listOptions(c, models.AttendanceListSpec)
+	if !ok {
+		return
+	}
+	term, ok := h.queryTerm(c)
+	if !ok {
+		return
+	}
+	if term != nil {
+		opts.Conditions = append(opts.Conditions, termConditions(term)...)
+	}
+
+	page, err := h.store.Attendance.ListByStudentID(c.Request.Context(), studentID, opts)
 	if err != nil {
 		log.Println("Error fetching attendance:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -112,18 +161,20 @@ func getAttendanceByStudentID(c *gin.Context) {
 		return
 	}
 
-	if attendance == nil {
-		attendance = []models.Attendance{} // Return empty array instead of null
+	if page.Items == nil {
+		page.Items = []models.Attendance{} // Return empty array instead of null
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"attendance": attendance,
-		"count":      len(attendance),
+		"attendance":  page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
 	})
 }
 
 // getAttendanceByDateRange retrieves attendance records within a date range
-func getAttendanceByDateRange(c *gin.Context) {
+func (h *Handler) getAttendanceByDateRange(c *gin.Context) {
 	startDateStr := c.Query("startDate")
 	endDateStr := c.Query("endDate")
 	
@@ -147,7 +198,15 @@ func getAttendanceByDateRange(c *gin.Context) {
 	// Add one day to end date to include the end date in the range
 	endDate = endDate.Add(24 * time.Hour)
 	
-	attendance, err := models.GetAttendanceByDateRange(startDate, endDate)
+	scope, err := middleware.GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch attendance records. Try again later."})
+		return
+	}
+
+	// Only the students in scope are read; the filter is a backstop
+	attendance, err := h.store.Attendance.ListByDateRange(c.Request.Context(), startDate, endDate, scope.StudentIDs())
 	if err != nil {
 		log.Println("Error fetching attendance:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -156,6 +215,7 @@ func getAttendanceByDateRange(c *gin.Context) {
 		})
 		return
 	}
+	attendance = authz.Filter(scope, attendance, func(a models.Attendance) string { return a.StudentID })
 
 	if attendance == nil {
 		attendance = []models.Attendance{} // Return empty array instead of null
@@ -170,3 +230,132 @@ func getAttendanceByDateRange(c *gin.Context) {
 		},
 	})
 }
+
+// recordClassAttendance records the attendance of a class for one day in a
+// single transaction and reports the outcome for each student
+func (h *Handler) recordClassAttendance(c *gin.Context) {
+	classID := c.Param("classId")
+
+	if !middleware.CheckClassAccess(c, classID) {
+		return
+	}
+
+	var request models.ClassAttendance
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	date := models.Day(request.Date)
+
+	class, err := h.store.Classes.GetByID(c.Request.Context(), classID)
+	if err != nil {
+		log.Println("Error fetching class:", err)
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+		return
+	}
+
+	// Archived students stay on rosters but can no longer be recorded
+	students, err := h.store.Students.ListByIDs(c.Request.Context(), class.StudentIDs)
+	if err != nil {
+		log.Println("Error fetching class students:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not record attendance. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	roster := make(map[string]models.Student, len(students))
+	for _, student := range students {
+		roster[student.ID] = student
+	}
+
+	user := middleware.GetUserFromContext(c)
+	results := make([]models.ClassAttendanceResult, 0, len(request.Entries))
+	var records []*models.Attendance
+	var recorded []int // index in results of each record
+	listed := make(map[string]bool, len(request.Entries))
+
+	record := func(studentID string, status models.AttendanceStatus, excuse string) {
+		records = append(records, &models.Attendance{
+			StudentID:  studentID,
+			Date:       date,
+			Status:     status,
+			Excuse:     excuse,
+			RecordedBy: user.ID,
+		})
+		recorded = append(recorded, len(results))
+		results = append(results, models.ClassAttendanceResult{StudentID: studentID})
+	}
+
+	for _, entry := range request.Entries {
+		var problem string
+		switch {
+		case listed[entry.StudentID]:
+			problem = "student is listed more than once"
+		case roster[entry.StudentID].ID == "":
+			problem = "student is not enrolled in this class"
+		case !entry.Status.IsValid():
+			problem = invalidAttendanceStatus
+		}
+		listed[entry.StudentID] = true
+
+		if problem != "" {
+			results = append(results, models.ClassAttendanceResult{
+				StudentID: entry.StudentID,
+				Result:    models.AttendanceFailed,
+				Error:     problem,
+			})
+			continue
+		}
+		record(entry.StudentID, entry.Status, entry.Excuse)
+	}
+
+	if request.DefaultPresent {
+		for _, studentID := range class.StudentIDs {
+			if student, ok := roster[studentID]; ok && student.IsActive && !listed[studentID] {
+				record(studentID, models.AttendanceStatusPresent, "")
+			}
+		}
+	}
+
+	if len(records) > 0 {
+		created, err := h.store.Attendance.Upsert(c.Request.Context(), records)
+		if err != nil {
+			log.Println("Error saving class attendance:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not record attendance. Try again later.",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		for i, attendance := range records {
+			result := &results[recorded[i]]
+			result.Attendance = attendance
+			result.Result = models.AttendanceUpdated
+			if created[i] {
+				result.Result = models.AttendanceCreated
+				metrics.AttendanceRecordedTotal.WithLabelValues(string(attendance.Status)).Inc()
+			}
+			h.notifier.AttendanceSaved(c.Request.Context(), *attendance)
+		}
+	}
+
+	counts := map[string]int{models.AttendanceCreated: 0, models.AttendanceUpdated: 0, models.AttendanceFailed: 0}
+	for _, result := range results {
+		counts[result.Result]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Class attendance recorded.",
+		"date":    date.Format("2006-01-02"),
+		"results": results,
+		"created": counts[models.AttendanceCreated],
+		"updated": counts[models.AttendanceUpdated],
+		"failed":  counts[models.AttendanceFailed],
+	})
+}
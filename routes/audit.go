@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getAuditLog retrieves the recorded changes, filtered by entity or actor
+func (h *Handler) getAuditLog(c *gin.Context) {
+	opts, ok := listOptions(c, models.AuditListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.store.Audit.List(c.Request.Context(), opts)
+	if err != nil {
+		log.Println("Error fetching audit log:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch audit log. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if page.Items == nil {
+		page.Items = []models.AuditEntry{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":     page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
+	})
+}
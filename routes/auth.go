@@ -1,18 +1,41 @@
 package routes
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	"example.com/sre-bootcamp-rest-api/auth"
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/metrics"
+	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 )
 
+// registrationRequest is the data a user may provide when registering. Role
+// and student links are deliberately absent: they are either fixed by
+// configuration or granted through an invitation.
+type registrationRequest struct {
+	Username  string `json:"username" binding:"required"`
+	Email     string `json:"email" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+}
+
 // registerUser handles the creation of a new user
-func registerUser(c *gin.Context) {
+func (h *Handler) registerUser(c *gin.Context) {
+	if !auth.Registration.Open {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "Self-service registration is disabled. Ask a staff member for an invitation.",
+		})
+		return
+	}
+
 	log.Println("Registering a new user...")
-	var user models.User
-	err := c.ShouldBindJSON(&user)
+	var request registrationRequest
+	err := c.ShouldBindJSON(&request)
 
 	if err != nil {
 		log.Println("Error binding JSON:", err)
@@ -23,8 +46,21 @@ func registerUser(c *gin.Context) {
 		return
 	}
 
-	if err := user.Save(); err != nil {
+	user := models.User{
+		Username:  request.Username,
+		Email:     request.Email,
+		Password:  request.Password,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Role:      auth.Registration.DefaultRole,
+	}
+
+	if err := h.store.Users.Create(c.Request.Context(), &user); err != nil {
 		log.Println("Error saving user:", err)
+		if errors.Is(err, models.ErrUserExists) {
+			c.JSON(http.StatusConflict, gin.H{"message": "Username or email already exists."})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create user. Try again later.",
 			"error":   err.Error(),
@@ -43,7 +79,7 @@ func registerUser(c *gin.Context) {
 }
 
 // loginUser authenticates a user and returns a token
-func loginUser(c *gin.Context) {
+func (h *Handler) loginUser(c *gin.Context) {
 	var credentials struct {
 		Username string `json:"username" binding:"required"`
 		Password string `json:"password" binding:"required"`
@@ -54,17 +90,37 @@ func loginUser(c *gin.Context) {
 		return
 	}
 
-	user, err := models.Authenticate(credentials.Username, credentials.Password)
+	user, err := models.Authenticate(c.Request.Context(), h.store.Users, credentials.Username, credentials.Password)
 	if err != nil {
+		metrics.LoginFailuresTotal.Inc()
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
 		return
 	}
 
-	// In a real application, you would generate a JWT token here
-	// For now, we'll just return the user ID as a token
+	token, expiresAt, err := auth.Tokens.IssueAccessToken(user)
+	if err != nil {
+		log.Println("Error issuing access token:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not issue access token"})
+		return
+	}
+
+	refreshToken, stored, err := h.store.RefreshTokens.Issue(c.Request.Context(), user.ID, auth.Tokens.RefreshTokenTTL())
+	if err != nil {
+		log.Println("Error issuing refresh token:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not issue refresh token"})
+		return
+	}
+
+	metrics.LoginsTotal.Inc()
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   user.ID,
+		"message":            "Login successful",
+		"token":              token,
+		"token_type":         "Bearer",
+		"expires_at":         expiresAt,
+		"expires_in":         int(auth.Tokens.AccessTokenTTL().Seconds()),
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": stored.ExpiresAt,
 		"user": gin.H{
 			"id":        user.ID,
 			"username":  user.Username,
@@ -76,30 +132,155 @@ func loginUser(c *gin.Context) {
 	})
 }
 
+// refreshAccessToken exchanges a refresh token for a new access token and a
+// rotated refresh token
+func (h *Handler) refreshAccessToken(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	refreshToken, stored, err := h.store.RefreshTokens.Rotate(c.Request.Context(), request.RefreshToken, auth.Tokens.RefreshTokenTTL())
+	if errors.Is(err, models.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used. All sessions for this login have been revoked."})
+		return
+	}
+	if errors.Is(err, models.ErrRefreshTokenInvalid) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if err != nil {
+		log.Println("Error rotating refresh token:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not refresh token"})
+		return
+	}
+
+	// Load the user so that role changes take effect on the next refresh
+	user, err := h.store.Users.GetByID(c.Request.Context(), stored.UserID)
+	if err != nil {
+		log.Println("Error fetching user for refresh:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+
+	token, expiresAt, err := auth.Tokens.IssueAccessToken(user)
+	if err != nil {
+		log.Println("Error issuing access token:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not issue access token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":              token,
+		"token_type":         "Bearer",
+		"expires_at":         expiresAt,
+		"expires_in":         int(auth.Tokens.AccessTokenTTL().Seconds()),
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": stored.ExpiresAt,
+	})
+}
+
+// logoutUser revokes the session that the given refresh token belongs to.
+// Access tokens already issued stay valid until they expire.
+func (h *Handler) logoutUser(c *gin.Context) {
+	var request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	// Logging out an unknown or already revoked session is not an error
+	err := h.store.RefreshTokens.Revoke(c.Request.Context(), request.RefreshToken)
+	if err != nil && !errors.Is(err, models.ErrRefreshTokenInvalid) {
+		log.Println("Error revoking refresh token:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// logoutAllSessions revokes every refresh token of the authenticated user
+func (h *Handler) logoutAllSessions(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+
+	revoked, err := h.store.RefreshTokens.RevokeAll(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Println("Error revoking refresh tokens:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not log out all sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out of all sessions",
+		"revoked": revoked,
+	})
+}
+
+// revokeUserSessions revokes every refresh token of another user (requires users:sessions)
+func (h *Handler) revokeUserSessions(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.store.Users.GetByID(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	revoked, err := h.store.RefreshTokens.RevokeAll(c.Request.Context(), id)
+	if err != nil {
+		log.Println("Error revoking refresh tokens:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User sessions revoked",
+		"revoked": revoked,
+	})
+}
+
 // getUsers returns all users (admin only)
-func getUsers(c *gin.Context) {
-	users, err := models.GetAllUsers()
+func (h *Handler) getUsers(c *gin.Context) {
+	opts, ok := listOptions(c, models.UserListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.store.Users.List(c.Request.Context(), opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
 
+	if page.Items == nil {
+		page.Items = []models.User{} // Return empty array instead of null
+	}
+
 	// Remove sensitive information
-	for i := range users {
-		users[i].Password = ""
-		users[i].PasswordHash = ""
+	for i := range page.Items {
+		page.Items[i].Password = ""
+		page.Items[i].PasswordHash = ""
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"count": len(users),
+		"users":       page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
 	})
 }
 
 // getUserByID returns a specific user by ID
-func getUserByID(c *gin.Context) {
+func (h *Handler) getUserByID(c *gin.Context) {
 	id := c.Param("id")
-	user, err := models.GetUserByID(id)
+	user, err := h.store.Users.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -113,11 +294,11 @@ func getUserByID(c *gin.Context) {
 }
 
 // updateUser updates a user's information
-func updateUser(c *gin.Context) {
+func (h *Handler) updateUser(c *gin.Context) {
 	id := c.Param("id")
 	
 	// First check if user exists
-	_, err := models.GetUserByID(id)
+	existingUser, err := h.store.Users.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -129,12 +310,28 @@ func updateUser(c *gin.Context) {
 		return
 	}
 
+	// Only callers allowed to manage roles may change roles or parent-student links
+	if !middleware.HasPermission(c, authz.PermUsersRoles) {
+		user.Role = existingUser.Role
+		user.StudentIDs = nil
+	} else if !user.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user role"})
+		return
+	}
+
 	user.ID = id
-	if err := user.Update(); err != nil {
+	if err := h.store.Users.Update(c.Request.Context(), &user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
+	// A password change ends every existing session of the user
+	if user.Password != "" {
+		if _, err := h.store.RefreshTokens.RevokeAll(c.Request.Context(), id); err != nil {
+			log.Println("Error revoking refresh tokens after password change:", err)
+		}
+	}
+
 	// Remove sensitive information
 	user.Password = ""
 	user.PasswordHash = ""
@@ -146,16 +343,16 @@ func updateUser(c *gin.Context) {
 }
 
 // deleteUser removes a user from the system
-func deleteUser(c *gin.Context) {
+func (h *Handler) deleteUser(c *gin.Context) {
 	id := c.Param("id")
 	
-	user, err := models.GetUserByID(id)
+	user, err := h.store.Users.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	if err := user.Delete(); err != nil {
+	if err := h.store.Users.Delete(c.Request.Context(), user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
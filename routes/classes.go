@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// createClass creates a class with its teachers and student roster
+func (h *Handler) createClass(c *gin.Context) {
+	log.Println("Creating a new class...")
+	var class models.Class
+	if err := c.ShouldBindJSON(&class); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Classes.Create(c.Request.Context(), &class); err != nil {
+		if errors.Is(err, models.ErrInvalidTeacher) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Class teachers must be faculty users."})
+			return
+		}
+		log.Println("Error saving class:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not create class. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Class created successfully!",
+		"class":   class,
+	})
+}
+
+// getClasses retrieves the classes visible to the caller. Faculty only see
+// the classes they teach.
+func (h *Handler) getClasses(c *gin.Context) {
+	scope, err := middleware.GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not verify access."})
+		return
+	}
+
+	var classes []models.Class
+	if scope.Unrestricted() {
+		classes, err = h.store.Classes.List(c.Request.Context())
+	} else {
+		classes, err = h.store.Classes.ListByTeacherID(c.Request.Context(), middleware.GetUserFromContext(c).ID)
+	}
+	if err != nil {
+		log.Println("Error fetching classes:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch classes. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if classes == nil {
+		classes = []models.Class{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classes": classes,
+		"count":   len(classes),
+	})
+}
+
+// getClassByID retrieves a class and its roster
+func (h *Handler) getClassByID(c *gin.Context) {
+	id := c.Param("id")
+
+	if !middleware.CheckClassAccess(c, id) {
+		return
+	}
+
+	class, err := h.store.Classes.GetByID(c.Request.Context(), id)
+	if err != nil {
+		log.Println("Error fetching class:", err)
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"class": class,
+	})
+}
+
+// updateClass updates a class and replaces its teachers and roster
+func (h *Handler) updateClass(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.store.Classes.GetByID(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+		return
+	}
+
+	var class models.Class
+	if err := c.ShouldBindJSON(&class); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	class.ID = id
+	if err := h.store.Classes.Update(c.Request.Context(), &class); err != nil {
+		if errors.Is(err, models.ErrInvalidTeacher) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Class teachers must be faculty users."})
+			return
+		}
+		log.Println("Error updating class:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not update class. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Class updated successfully!",
+		"class":   class,
+	})
+}
+
+// deleteClass deletes a class together with its roster and assignments
+func (h *Handler) deleteClass(c *gin.Context) {
+	id := c.Param("id")
+
+	class, err := h.store.Classes.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+		return
+	}
+
+	if err := h.store.Classes.Delete(c.Request.Context(), class.ID); err != nil {
+		log.Println("Error deleting class:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not delete class. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Class deleted successfully!",
+	})
+}
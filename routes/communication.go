@@ -4,13 +4,14 @@ import (
 	"log"
 	"net/http"
 
+	"example.com/sre-bootcamp-rest-api/authz"
 	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 )
 
 // createForumPost creates a new forum post
-func createForumPost(c *gin.Context) {
+func (h *Handler) createForumPost(c *gin.Context) {
 	log.Println("Creating a new forum post...")
 	var post models.ForumPost
 	if err := c.ShouldBindJSON(&post); err != nil {
@@ -22,11 +23,15 @@ func createForumPost(c *gin.Context) {
 		return
 	}
 	
+	if !middleware.CheckStudentAccess(c, post.StudentID) {
+		return
+	}
+
 	// Set the user who created this post
 	user := middleware.GetUserFromContext(c)
 	post.AuthorID = user.ID
 
-	if err := post.Save(); err != nil {
+	if err := h.store.Forum.CreatePost(c.Request.Context(), &post); err != nil {
 		log.Println("Error saving forum post:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create forum post. Try again later.",
@@ -42,18 +47,22 @@ func createForumPost(c *gin.Context) {
 }
 
 // getForumPostByID retrieves a forum post by ID
-func getForumPostByID(c *gin.Context) {
+func (h *Handler) getForumPostByID(c *gin.Context) {
 	id := c.Param("id")
 	
-	post, err := models.GetForumPostByID(id)
+	post, err := h.store.Forum.GetPostByID(c.Request.Context(), id)
 	if err != nil {
 		log.Println("Error fetching forum post:", err)
 		c.JSON(http.StatusNotFound, gin.H{"message": "Forum post not found."})
 		return
 	}
 
+	if !middleware.CheckStudentAccess(c, post.StudentID) {
+		return
+	}
+
 	// Get the comments for this post
-	comments, err := models.GetCommentsByPostID(id)
+	comments, err := h.store.Forum.ListCommentsByPostID(c.Request.Context(), id)
 	if err != nil {
 		log.Println("Error fetching comments:", err)
 		comments = []models.ForumComment{} // Return empty array instead of error
@@ -66,16 +75,20 @@ func getForumPostByID(c *gin.Context) {
 }
 
 // updateForumPost updates an existing forum post
-func updateForumPost(c *gin.Context) {
+func (h *Handler) updateForumPost(c *gin.Context) {
 	id := c.Param("id")
 	
 	// Check if post exists
-	existingPost, err := models.GetForumPostByID(id)
+	existingPost, err := h.store.Forum.GetPostByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Forum post not found."})
 		return
 	}
 
+	if !middleware.CheckStudentAccess(c, existingPost.StudentID) {
+		return
+	}
+
 	var post models.ForumPost
 	if err := c.ShouldBindJSON(&post); err != nil {
 		log.Println("Error binding JSON:", err)
@@ -100,7 +113,7 @@ func updateForumPost(c *gin.Context) {
 	post.AuthorID = existingPost.AuthorID
 	post.StudentID = existingPost.StudentID
 
-	if err := post.Update(); err != nil {
+	if err := h.store.Forum.UpdatePost(c.Request.Context(), &post); err != nil {
 		log.Println("Error updating forum post:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not update forum post. Try again later.",
@@ -116,34 +129,37 @@ func updateForumPost(c *gin.Context) {
 }
 
 // deleteForumPost deletes a forum post
-func deleteForumPost(c *gin.Context) {
+func (h *Handler) deleteForumPost(c *gin.Context) {
 	id := c.Param("id")
 	
 	// Check if post exists
-	post, err := models.GetForumPostByID(id)
+	post, err := h.store.Forum.GetPostByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Forum post not found."})
 		return
 	}
 
+	if !middleware.CheckStudentAccess(c, post.StudentID) {
+		return
+	}
+
 	// Get current user
 	user := middleware.GetUserFromContext(c)
 
-	// Check if the user is the author of the post or a staff member
-	if post.AuthorID != user.ID && user.Role != models.RoleStaff && user.Role != models.RoleFaculty {
+	// Check if the user is the author of the post or a moderator
+	if post.AuthorID != user.ID && !middleware.HasPermission(c, authz.PermForumModerate) {
 		c.JSON(http.StatusForbidden, gin.H{"message": "You can only delete your own posts."})
 		return
 	}
 
-	// Set author ID for permission check if user is the author
-	if post.AuthorID == user.ID {
-		post.AuthorID = user.ID
-	} else {
-		// For staff/faculty, don't set author ID to allow deletion of any post
-		post.AuthorID = ""
+	// Restrict the deletion to the author's own post; moderators may
+	// delete any post
+	authorID := user.ID
+	if post.AuthorID != user.ID {
+		authorID = ""
 	}
 
-	if err := post.Delete(); err != nil {
+	if err := h.store.Forum.DeletePost(c.Request.Context(), post.ID, authorID); err != nil {
 		log.Println("Error deleting forum post:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not delete forum post. Try again later.",
@@ -158,10 +174,15 @@ func deleteForumPost(c *gin.Context) {
 }
 
 // getForumPostsByStudentID retrieves all forum posts for a student
-func getForumPostsByStudentID(c *gin.Context) {
+func (h *Handler) getForumPostsByStudentID(c *gin.Context) {
 	studentID := c.Param("studentId")
 	
-	posts, err := models.GetForumPostsByStudentID(studentID)
+	opts, ok := listOptions(c, models.ForumPostListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.store.Forum.ListPostsByStudentID(c.Request.Context(), studentID, opts)
 	if err != nil {
 		log.Println("Error fetching forum posts:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -171,18 +192,20 @@ func getForumPostsByStudentID(c *gin.Context) {
 		return
 	}
 
-	if posts == nil {
-		posts = []models.ForumPost{} // Return empty array instead of null
+	if page.Items == nil {
+		page.Items = []models.ForumPost{} // Return empty array instead of null
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
-		"count": len(posts),
+		"posts":       page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
 	})
 }
 
 // createForumComment creates a new comment on a forum post
-func createForumComment(c *gin.Context) {
+func (h *Handler) createForumComment(c *gin.Context) {
 	log.Println("Creating a new forum comment...")
 	var comment models.ForumComment
 	if err := c.ShouldBindJSON(&comment); err != nil {
@@ -195,17 +218,21 @@ func createForumComment(c *gin.Context) {
 	}
 	
 	// Check if the post exists
-	_, err := models.GetForumPostByID(comment.PostID)
+	post, err := h.store.Forum.GetPostByID(c.Request.Context(), comment.PostID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Forum post not found."})
 		return
 	}
+
+	if !middleware.CheckStudentAccess(c, post.StudentID) {
+		return
+	}
 	
 	// Set the user who created this comment
 	user := middleware.GetUserFromContext(c)
 	comment.AuthorID = user.ID
 
-	if err := comment.Save(); err != nil {
+	if err := h.store.Forum.CreateComment(c.Request.Context(), &comment); err != nil {
 		log.Println("Error saving forum comment:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create forum comment. Try again later.",
@@ -213,6 +240,7 @@ func createForumComment(c *gin.Context) {
 		})
 		return
 	}
+	h.notifier.CommentCreated(c.Request.Context(), *post, comment)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Comment added successfully!",
@@ -221,7 +249,7 @@ func createForumComment(c *gin.Context) {
 }
 
 // updateForumComment updates an existing comment
-func updateForumComment(c *gin.Context) {
+func (h *Handler) updateForumComment(c *gin.Context) {
 	id := c.Param("id")
 	
 	var comment models.ForumComment
@@ -240,7 +268,7 @@ func updateForumComment(c *gin.Context) {
 	user := middleware.GetUserFromContext(c)
 	comment.AuthorID = user.ID
 
-	if err := comment.Update(); err != nil {
+	if err := h.store.Forum.UpdateComment(c.Request.Context(), &comment); err != nil {
 		log.Println("Error updating forum comment:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not update forum comment. Try again later.",
@@ -256,10 +284,20 @@ func updateForumComment(c *gin.Context) {
 }
 
 // getCommentsByPostID retrieves all comments for a forum post
-func getCommentsByPostID(c *gin.Context) {
+func (h *Handler) getCommentsByPostID(c *gin.Context) {
 	postID := c.Param("postId")
-	
-	comments, err := models.GetCommentsByPostID(postID)
+
+	post, err := h.store.Forum.GetPostByID(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Forum post not found."})
+		return
+	}
+
+	if !middleware.CheckStudentAccess(c, post.StudentID) {
+		return
+	}
+
+	comments, err := h.store.Forum.ListCommentsByPostID(c.Request.Context(), postID)
 	if err != nil {
 		log.Println("Error fetching comments:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -0,0 +1,426 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getGradeScales lists the letter-grade scales classes can choose from,
+// together with the default scale
+func (h *Handler) getGradeScales(c *gin.Context) {
+	scales, err := h.store.Gradebook.ListScales(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching grade scales:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch grade scales. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if scales == nil {
+		scales = []models.GradeScale{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scales":  scales,
+		"default": models.DefaultGradeScale,
+		"count":   len(scales),
+	})
+}
+
+// createGradeScale creates a letter-grade scale
+func (h *Handler) createGradeScale(c *gin.Context) {
+	var scale models.GradeScale
+	if err := c.ShouldBindJSON(&scale); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := scale.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid grade scale.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Gradebook.CreateScale(c.Request.Context(), &scale); err != nil {
+		log.Println("Error saving grade scale:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not create grade scale. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Grade scale created successfully!",
+		"scale":   scale,
+	})
+}
+
+// updateGradeScale replaces the name and steps of a letter-grade scale
+func (h *Handler) updateGradeScale(c *gin.Context) {
+	id := c.Param("id")
+
+	if _, err := h.store.Gradebook.GetScaleByID(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Grade scale not found."})
+		return
+	}
+
+	var scale models.GradeScale
+	if err := c.ShouldBindJSON(&scale); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := scale.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid grade scale.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	scale.ID = id
+	if err := h.store.Gradebook.UpdateScale(c.Request.Context(), &scale); err != nil {
+		log.Println("Error updating grade scale:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not update grade scale. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Grade scale updated successfully!",
+		"scale":   scale,
+	})
+}
+
+// deleteGradeScale deletes a letter-grade scale. Classes using it fall back
+// to the default scale.
+func (h *Handler) deleteGradeScale(c *gin.Context) {
+	if err := h.store.Gradebook.DeleteScale(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Grade scale not found."})
+			return
+		}
+		log.Println("Error deleting grade scale:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not delete grade scale. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grade scale deleted successfully!"})
+}
+
+// getGradingCategories lists the categories of a class, including those of
+// its subject, or the subject-wide categories of a subject
+func (h *Handler) getGradingCategories(c *gin.Context) {
+	classID, subject := c.Query("class_id"), c.Query("subject")
+	if (classID == "") == (subject == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Either class_id or subject is required."})
+		return
+	}
+
+	if classID != "" {
+		class, err := h.store.Classes.GetByID(c.Request.Context(), classID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+			return
+		}
+		subject = class.Subject
+	}
+
+	categories, err := h.store.Gradebook.ListCategories(c.Request.Context(), classID, subject)
+	if err != nil {
+		log.Println("Error fetching grading categories:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch grading categories. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if categories == nil {
+		categories = []models.GradingCategory{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+		"count":      len(categories),
+	})
+}
+
+// createGradingCategory creates a category for a class the caller teaches,
+// or for every class of a subject
+func (h *Handler) createGradingCategory(c *gin.Context) {
+	var category models.GradingCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := category.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid grading category.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if !checkCategoryAccess(c, &category) {
+		return
+	}
+	if category.ClassID != "" {
+		if _, err := h.store.Classes.GetByID(c.Request.Context(), category.ClassID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Class not found."})
+			return
+		}
+	}
+
+	if err := h.store.Gradebook.CreateCategory(c.Request.Context(), &category); err != nil {
+		log.Println("Error saving grading category:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not create grading category. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Grading category created successfully!",
+		"category": category,
+	})
+}
+
+// updateGradingCategory renames or reweighs a category. The class or
+// subject it belongs to cannot change.
+func (h *Handler) updateGradingCategory(c *gin.Context) {
+	existing, err := h.store.Gradebook.GetCategoryByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Grading category not found."})
+		return
+	}
+	if !checkCategoryAccess(c, existing) {
+		return
+	}
+
+	var category models.GradingCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	category.ID = existing.ID
+	category.ClassID = existing.ClassID
+	category.Subject = existing.Subject
+	if err := category.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid grading category.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Gradebook.UpdateCategory(c.Request.Context(), &category); err != nil {
+		log.Println("Error updating grading category:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not update grading category. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Grading category updated successfully!",
+		"category": category,
+	})
+}
+
+// deleteGradingCategory deletes a category. Its assignments are kept and
+// no longer count towards weighted averages until recategorized.
+func (h *Handler) deleteGradingCategory(c *gin.Context) {
+	category, err := h.store.Gradebook.GetCategoryByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Grading category not found."})
+		return
+	}
+	if !checkCategoryAccess(c, category) {
+		return
+	}
+
+	if err := h.store.Gradebook.DeleteCategory(c.Request.Context(), category.ID); err != nil {
+		log.Println("Error deleting grading category:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not delete grading category. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grading category deleted successfully!"})
+}
+
+// getGradebookSettings returns the grading rules of a class and the scale
+// they resolve to
+func (h *Handler) getGradebookSettings(c *gin.Context) {
+	classID := c.Param("id")
+	if !middleware.CheckClassAccess(c, classID) {
+		return
+	}
+	if _, err := h.store.Classes.GetByID(c.Request.Context(), classID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+		return
+	}
+
+	settings, scale, err := h.gradebookSettings(c, classID)
+	if err != nil {
+		log.Println("Error fetching gradebook settings:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch gradebook settings. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+		"scale":    scale,
+	})
+}
+
+// updateGradebookSettings chooses the letter-grade scale and missing
+// assignment policy of a class
+func (h *Handler) updateGradebookSettings(c *gin.Context) {
+	classID := c.Param("id")
+	if !middleware.CheckClassAccess(c, classID) {
+		return
+	}
+	if _, err := h.store.Classes.GetByID(c.Request.Context(), classID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+		return
+	}
+
+	var settings models.GradebookSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	settings.ClassID = classID
+	if settings.MissingPolicy == "" {
+		settings.MissingPolicy = models.MissingAsZero
+	}
+	if err := settings.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid gradebook settings.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	scale := &models.DefaultGradeScale
+	if settings.GradeScaleID != "" {
+		var err error
+		if scale, err = h.store.Gradebook.GetScaleByID(c.Request.Context(), settings.GradeScaleID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Grade scale not found."})
+			return
+		}
+	}
+
+	if err := h.store.Gradebook.SaveSettings(c.Request.Context(), &settings); err != nil {
+		log.Println("Error saving gradebook settings:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not save gradebook settings. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Gradebook settings saved successfully!",
+		"settings": settings,
+		"scale":    scale,
+	})
+}
+
+// gradebookSettings loads the settings of a class and the scale they
+// resolve to
+func (h *Handler) gradebookSettings(c *gin.Context, classID string) (*models.GradebookSettings, *models.GradeScale, error) {
+	settings, err := h.store.Gradebook.GetSettings(c.Request.Context(), classID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if settings.GradeScaleID == "" {
+		return settings, &models.DefaultGradeScale, nil
+	}
+
+	scale, err := h.store.Gradebook.GetScaleByID(c.Request.Context(), settings.GradeScaleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, scale, nil
+}
+
+// checkCategoryAccess verifies the caller may manage the category and
+// writes an error response if not. Class categories need the caller to
+// teach the class; subject-wide categories affect every class of the
+// subject and need gradebook:configure.
+func checkCategoryAccess(c *gin.Context, category *models.GradingCategory) bool {
+	if category.ClassID != "" {
+		return middleware.CheckClassAccess(c, category.ClassID)
+	}
+	if !middleware.HasPermission(c, authz.PermGradebookConfigure) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have permission to manage subject-wide categories."})
+		return false
+	}
+	return true
+}
+
+// checkAssignmentCategory verifies the category of an assignment exists and
+// applies to the assignment's class or subject, and writes an error
+// response if not
+func (h *Handler) checkAssignmentCategory(c *gin.Context, assignment *models.Assignment) bool {
+	if assignment.CategoryID == "" {
+		return true
+	}
+
+	category, err := h.store.Gradebook.GetCategoryByID(c.Request.Context(), assignment.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Grading category not found."})
+		return false
+	}
+	if !category.AppliesTo(assignment) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": models.ErrCategoryMismatch.Error()})
+		return false
+	}
+	return true
+}
@@ -1,16 +1,19 @@
 package routes
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/metrics"
 	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 )
 
 // createAssignment creates a new assignment
-func createAssignment(c *gin.Context) {
+func (h *Handler) createAssignment(c *gin.Context) {
 	log.Println("Creating a new assignment...")
 	var assignment models.Assignment
 	if err := c.ShouldBindJSON(&assignment); err != nil {
@@ -22,11 +25,31 @@ func createAssignment(c *gin.Context) {
 		return
 	}
 	
+	// Assignments are created for a class the caller teaches
+	if assignment.ClassID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "class_id is required."})
+		return
+	}
+	if !middleware.CheckClassAccess(c, assignment.ClassID) {
+		return
+	}
+	if _, err := h.store.Classes.GetByID(c.Request.Context(), assignment.ClassID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Class not found."})
+		return
+	}
+	if !h.checkAssignmentCategory(c, &assignment) {
+		return
+	}
+
 	// Set the user who created this assignment
 	user := middleware.GetUserFromContext(c)
 	assignment.CreatedBy = user.ID
 
-	if err := assignment.Save(); err != nil {
+	if err := h.store.Grades.CreateAssignment(c.Request.Context(), &assignment); err != nil {
+		if errors.Is(err, models.ErrTermFinalized) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The due date falls in a finalized term."})
+			return
+		}
 		log.Println("Error saving assignment:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create assignment. Try again later.",
@@ -42,8 +65,13 @@ func createAssignment(c *gin.Context) {
 }
 
 // getAssignments retrieves all assignments
-func getAssignments(c *gin.Context) {
-	assignments, err := models.GetAllAssignments()
+func (h *Handler) getAssignments(c *gin.Context) {
+	opts, ok := listOptions(c, models.AssignmentListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.store.Grades.ListAssignments(c.Request.Context(), opts)
 	if err != nil {
 		log.Println("Error fetching assignments:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -53,21 +81,23 @@ func getAssignments(c *gin.Context) {
 		return
 	}
 
-	if assignments == nil {
-		assignments = []models.Assignment{} // Return empty array instead of null
+	if page.Items == nil {
+		page.Items = []models.Assignment{} // Return empty array instead of null
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"assignments": assignments,
-		"count":       len(assignments),
+		"assignments": page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
 	})
 }
 
 // getAssignmentByID retrieves an assignment by ID
-func getAssignmentByID(c *gin.Context) {
+func (h *Handler) getAssignmentByID(c *gin.Context) {
 	id := c.Param("id")
 	
-	assignment, err := models.GetAssignmentByID(id)
+	assignment, err := h.store.Grades.GetAssignmentByID(c.Request.Context(), id)
 	if err != nil {
 		log.Println("Error fetching assignment:", err)
 		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found."})
@@ -80,16 +110,20 @@ func getAssignmentByID(c *gin.Context) {
 }
 
 // updateAssignment updates an existing assignment
-func updateAssignment(c *gin.Context) {
+func (h *Handler) updateAssignment(c *gin.Context) {
 	id := c.Param("id")
 	
 	// Check if assignment exists
-	existingAssignment, err := models.GetAssignmentByID(id)
+	existingAssignment, err := h.store.Grades.GetAssignmentByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found."})
 		return
 	}
 
+	if !h.checkAssignmentAccess(c, existingAssignment) {
+		return
+	}
+
 	var assignment models.Assignment
 	if err := c.ShouldBindJSON(&assignment); err != nil {
 		log.Println("Error binding JSON:", err)
@@ -104,7 +138,27 @@ func updateAssignment(c *gin.Context) {
 	assignment.ID = existingAssignment.ID
 	assignment.CreatedBy = existingAssignment.CreatedBy
 
-	if err := assignment.Update(); err != nil {
+	// Moving the assignment requires teaching the target class as well
+	if assignment.ClassID == "" {
+		assignment.ClassID = existingAssignment.ClassID
+	} else if assignment.ClassID != existingAssignment.ClassID {
+		if !middleware.CheckClassAccess(c, assignment.ClassID) {
+			return
+		}
+		if _, err := h.store.Classes.GetByID(c.Request.Context(), assignment.ClassID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Class not found."})
+			return
+		}
+	}
+	if !h.checkAssignmentCategory(c, &assignment) {
+		return
+	}
+
+	if err := h.store.Grades.UpdateAssignment(c.Request.Context(), &assignment); err != nil {
+		if errors.Is(err, models.ErrTermFinalized) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The assignment is due in a finalized term; it can no longer change."})
+			return
+		}
 		log.Println("Error updating assignment:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not update assignment. Try again later.",
@@ -120,16 +174,35 @@ func updateAssignment(c *gin.Context) {
 }
 
 // deleteAssignment deletes an assignment
-func deleteAssignment(c *gin.Context) {
+func (h *Handler) deleteAssignment(c *gin.Context) {
 	id := c.Param("id")
 	
-	assignment, err := models.GetAssignmentByID(id)
+	assignment, err := h.store.Grades.GetAssignmentByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found."})
 		return
 	}
 
-	if err := assignment.Delete(); err != nil {
+	if !h.checkAssignmentAccess(c, assignment) {
+		return
+	}
+
+	// Submissions go with the assignment; their files are removed after it
+	submissions, err := h.store.Submissions.ListByAssignmentID(c.Request.Context(), assignment.ID, nil)
+	if err != nil {
+		log.Println("Error fetching submissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not delete assignment. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Grades.DeleteAssignment(c.Request.Context(), assignment.ID); err != nil {
+		if errors.Is(err, models.ErrTermFinalized) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The assignment is due in a finalized term; it can no longer be deleted."})
+			return
+		}
 		log.Println("Error deleting assignment:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not delete assignment. Try again later.",
@@ -137,6 +210,7 @@ func deleteAssignment(c *gin.Context) {
 		})
 		return
 	}
+	h.deleteSubmissionFiles(c.Request.Context(), submissions...)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Assignment deleted successfully!",
@@ -144,7 +218,7 @@ func deleteAssignment(c *gin.Context) {
 }
 
 // createGrade creates a new grade for a student's assignment
-func createGrade(c *gin.Context) {
+func (h *Handler) createGrade(c *gin.Context) {
 	log.Println("Creating a new grade...")
 	var grade models.Grade
 	if err := c.ShouldBindJSON(&grade); err != nil {
@@ -156,11 +230,19 @@ func createGrade(c *gin.Context) {
 		return
 	}
 	
+	if !h.checkGradeAccess(c, &grade) {
+		return
+	}
+
 	// Set the user who graded this assignment
 	user := middleware.GetUserFromContext(c)
 	grade.GradedBy = user.ID
 
-	if err := grade.Save(); err != nil {
+	if err := h.store.Grades.CreateGrade(c.Request.Context(), &grade); err != nil {
+		if errors.Is(err, models.ErrTermFinalized) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The assignment is due in a finalized term; its grades can no longer change."})
+			return
+		}
 		log.Println("Error saving grade:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create grade. Try again later.",
@@ -169,6 +251,9 @@ func createGrade(c *gin.Context) {
 		return
 	}
 
+	metrics.GradesCreatedTotal.Inc()
+	h.notifier.GradeSaved(c.Request.Context(), grade)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Grade created successfully!",
 		"grade":   grade,
@@ -176,32 +261,40 @@ func createGrade(c *gin.Context) {
 }
 
 // getGradeByID retrieves a grade by ID
-func getGradeByID(c *gin.Context) {
+func (h *Handler) getGradeByID(c *gin.Context) {
 	id := c.Param("id")
 	
-	grade, err := models.GetGradeByID(id)
+	grade, err := h.store.Grades.GetGradeByID(c.Request.Context(), id)
 	if err != nil {
 		log.Println("Error fetching grade:", err)
 		c.JSON(http.StatusNotFound, gin.H{"message": "Grade not found."})
 		return
 	}
 
+	if !middleware.CheckStudentAccess(c, grade.StudentID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"grade": grade,
 	})
 }
 
 // updateGrade updates an existing grade
-func updateGrade(c *gin.Context) {
+func (h *Handler) updateGrade(c *gin.Context) {
 	id := c.Param("id")
 	
 	// Check if grade exists
-	existingGrade, err := models.GetGradeByID(id)
+	existingGrade, err := h.store.Grades.GetGradeByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Grade not found."})
 		return
 	}
 
+	if !h.checkGradeAccess(c, existingGrade) {
+		return
+	}
+
 	var grade models.Grade
 	if err := c.ShouldBindJSON(&grade); err != nil {
 		log.Println("Error binding JSON:", err)
@@ -212,11 +305,17 @@ func updateGrade(c *gin.Context) {
 		return
 	}
 
-	// Preserve the grade ID and grader
+	// Preserve the grade ID, student, assignment and grader
 	grade.ID = existingGrade.ID
+	grade.StudentID = existingGrade.StudentID
+	grade.AssignmentID = existingGrade.AssignmentID
 	grade.GradedBy = existingGrade.GradedBy
 
-	if err := grade.Update(); err != nil {
+	if err := h.store.Grades.UpdateGrade(c.Request.Context(), &grade); err != nil {
+		if errors.Is(err, models.ErrTermFinalized) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The assignment is due in a finalized term; its grades can no longer change."})
+			return
+		}
 		log.Println("Error updating grade:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not update grade. Try again later.",
@@ -224,6 +323,7 @@ func updateGrade(c *gin.Context) {
 		})
 		return
 	}
+	h.notifier.GradeSaved(c.Request.Context(), grade)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Grade updated successfully!",
@@ -231,11 +331,17 @@ func updateGrade(c *gin.Context) {
 	})
 }
 
-// getGradesByStudentID retrieves all grades for a student
-func getGradesByStudentID(c *gin.Context) {
+// getGradesByStudentID retrieves all grades for a student, or with termId
+// those for assignments due in the term
+func (h *Handler) getGradesByStudentID(c *gin.Context) {
 	studentID := c.Param("studentId")
+
+	term, ok := h.queryTerm(c)
+	if !ok {
+		return
+	}
 	
-	grades, err := models.GetGradesByStudentID(studentID)
+	grades, err := h.store.Grades.ListGradesByStudentID(c.Request.Context(), studentID)
 	if err != nil {
 		log.Println("Error fetching grades:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -245,6 +351,29 @@ func getGradesByStudentID(c *gin.Context) {
 		return
 	}
 
+	if term != nil {
+		details, err := h.store.Reports.StudentGrades(c.Request.Context(), studentID, termGradeFilter(term))
+		if err != nil {
+			log.Println("Error fetching grades:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not fetch grades. Try again later.",
+				"error":   err.Error(),
+			})
+			return
+		}
+		inTerm := make(map[string]bool, len(details))
+		for _, detail := range details {
+			inTerm[detail.GradeID] = true
+		}
+		var filtered []models.Grade
+		for _, grade := range grades {
+			if inTerm[grade.ID] {
+				filtered = append(filtered, grade)
+			}
+		}
+		grades = filtered
+	}
+
 	if grades == nil {
 		grades = []models.Grade{} // Return empty array instead of null
 	}
@@ -256,10 +385,18 @@ func getGradesByStudentID(c *gin.Context) {
 }
 
 // getGradesByAssignmentID retrieves all grades for an assignment
-func getGradesByAssignmentID(c *gin.Context) {
+func (h *Handler) getGradesByAssignmentID(c *gin.Context) {
 	assignmentID := c.Param("assignmentId")
 	
-	grades, err := models.GetGradesByAssignmentID(assignmentID)
+	scope, err := middleware.GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch grades. Try again later."})
+		return
+	}
+
+	// Only the students in scope are read; the filter is a backstop
+	grades, err := h.store.Grades.ListGradesByAssignmentID(c.Request.Context(), assignmentID, scope.StudentIDs())
 	if err != nil {
 		log.Println("Error fetching grades:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -268,6 +405,7 @@ func getGradesByAssignmentID(c *gin.Context) {
 		})
 		return
 	}
+	grades = authz.Filter(scope, grades, func(g models.Grade) string { return g.StudentID })
 
 	if grades == nil {
 		grades = []models.Grade{} // Return empty array instead of null
@@ -278,3 +416,53 @@ func getGradesByAssignmentID(c *gin.Context) {
 		"count":  len(grades),
 	})
 }
+
+// checkAssignmentAccess verifies the caller may change the assignment and
+// writes an error response if not. Assignments created before classes were
+// introduced have no class; only their creator or unrestricted users may
+// change or grade those.
+func (h *Handler) checkAssignmentAccess(c *gin.Context, assignment *models.Assignment) bool {
+	if assignment.ClassID == "" && assignment.CreatedBy == middleware.GetUserFromContext(c).ID {
+		return true
+	}
+	return middleware.CheckClassAccess(c, assignment.ClassID)
+}
+
+// checkGradeAccess verifies the caller may grade the student on the
+// assignment and writes an error response if not. For class assignments the
+// caller must teach the class and the student must be on its roster;
+// assignments without a class follow checkAssignmentAccess.
+func (h *Handler) checkGradeAccess(c *gin.Context, grade *models.Grade) bool {
+	assignment, err := h.store.Grades.GetAssignmentByID(c.Request.Context(), grade.AssignmentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Assignment not found."})
+		return false
+	}
+
+	if !middleware.CheckStudentAccess(c, grade.StudentID) {
+		return false
+	}
+
+	if !h.checkAssignmentAccess(c, assignment) {
+		return false
+	}
+	if assignment.ClassID == "" {
+		return true
+	}
+
+	enrolled, err := h.store.Classes.IsStudentEnrolled(c.Request.Context(), assignment.ClassID, grade.StudentID)
+	if err != nil {
+		log.Println("Error checking class enrollment:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not verify class enrollment. Try again later.",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	if !enrolled {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Student is not enrolled in the assignment's class."})
+		return false
+	}
+
+	return true
+}
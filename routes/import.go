@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/importer"
+	"github.com/gin-gonic/gin"
+)
+
+// importData imports the CSV files uploaded as the students, users and
+// parent_links form fields. With dry_run=true every row is checked but
+// nothing is written.
+func (h *Handler) importData(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	var files importer.Files
+	for field, target := range map[string]*io.Reader{
+		"students":     &files.Students,
+		"users":        &files.Users,
+		"parent_links": &files.ParentLinks,
+	} {
+		header, err := c.FormFile(field)
+		if err == http.ErrMissingFile {
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Could not read the uploaded files.",
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			log.Println("Error opening uploaded file:", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Could not read the uploaded files.",
+				"error":   err.Error(),
+			})
+			return
+		}
+		defer func(file multipart.File) { _ = file.Close() }(file)
+		*target = file
+	}
+
+	report, err := importer.Run(c.Request.Context(), h.store.Import, files, dryRun)
+	if err != nil {
+		log.Println("Error importing data:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not import the files. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": "The files have errors; nothing was imported.",
+			"report":  report,
+		})
+	case dryRun:
+		c.JSON(http.StatusOK, gin.H{
+			"message": "The files can be imported; nothing was written.",
+			"report":  report,
+		})
+	default:
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Import completed successfully!",
+			"report":  report,
+		})
+	}
+}
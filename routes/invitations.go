@@ -0,0 +1,179 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/auth"
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// createInvitation creates a single-use invitation code (requires invitations:manage)
+func (h *Handler) createInvitation(c *gin.Context) {
+	log.Println("Creating a new invitation...")
+	var request struct {
+		Email      string          `json:"email" binding:"required"`
+		Role       models.UserRole `json:"role" binding:"required"`
+		StudentIDs []string        `json:"student_ids"`
+		ExpiresAt  *time.Time      `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if !request.Role.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid role."})
+		return
+	}
+	if request.Role != models.RoleParent && len(request.StudentIDs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Student links are only allowed for parent invitations."})
+		return
+	}
+
+	expiresAt := time.Now().Add(auth.Registration.InvitationTTL)
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Expiry must be in the future."})
+			return
+		}
+		expiresAt = *request.ExpiresAt
+	}
+
+	user := middleware.GetUserFromContext(c)
+	invitation := models.Invitation{
+		Email:      request.Email,
+		Role:       request.Role,
+		StudentIDs: request.StudentIDs,
+		ExpiresAt:  expiresAt,
+		CreatedBy:  user.ID,
+	}
+
+	code, err := h.store.Invitations.Create(c.Request.Context(), &invitation)
+	if err != nil {
+		log.Println("Error saving invitation:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not create invitation. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// The code is only shown once; it cannot be recovered later
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Invitation created successfully!",
+		"invitation": invitation,
+		"code":       code,
+	})
+}
+
+// getInvitations retrieves all invitations (requires invitations:manage)
+func (h *Handler) getInvitations(c *gin.Context) {
+	invitations, err := h.store.Invitations.List(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching invitations:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch invitations. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if invitations == nil {
+		invitations = []models.Invitation{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitations": invitations,
+		"count":       len(invitations),
+	})
+}
+
+// revokeInvitation revokes an unredeemed invitation (requires invitations:manage)
+func (h *Handler) revokeInvitation(c *gin.Context) {
+	id := c.Param("id")
+
+	invitation, err := h.store.Invitations.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Invitation not found."})
+		return
+	}
+
+	if err := h.store.Invitations.Revoke(c.Request.Context(), invitation.ID); err != nil {
+		log.Println("Error revoking invitation:", err)
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "Could not revoke invitation.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully!",
+	})
+}
+
+// redeemInvitation creates an account from an invitation code
+func (h *Handler) redeemInvitation(c *gin.Context) {
+	var request struct {
+		Code      string `json:"code" binding:"required"`
+		Username  string `json:"username" binding:"required"`
+		Password  string `json:"password" binding:"required"`
+		FirstName string `json:"first_name" binding:"required"`
+		LastName  string `json:"last_name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	user := models.User{
+		Username:  request.Username,
+		Password:  request.Password,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+	}
+
+	err := h.store.Invitations.Redeem(c.Request.Context(), request.Code, &user)
+	if errors.Is(err, models.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Invitation not found."})
+		return
+	}
+	if errors.Is(err, models.ErrInvitationInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid or expired invitation code."})
+		return
+	}
+	if errors.Is(err, models.ErrUserExists) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Username or email already exists."})
+		return
+	}
+	if err != nil {
+		log.Println("Error redeeming invitation:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not redeem invitation. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Don't return the password in the response
+	user.Password = ""
+	user.PasswordHash = ""
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Account created successfully!",
+		"user":    user,
+	})
+}
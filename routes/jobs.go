@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/scheduler"
+	"github.com/gin-gonic/gin"
+)
+
+// getJobs lists the background jobs with their last run
+func (h *Handler) getJobs(c *gin.Context) {
+	jobs, err := h.jobs.Status(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching job status:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch jobs. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// runJob runs a background job now rather than waiting for it to be due
+func (h *Handler) runJob(c *gin.Context) {
+	run, err := h.jobs.Run(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Job not found."})
+		return
+	case errors.Is(err, scheduler.ErrJobRunning):
+		c.JSON(http.StatusConflict, gin.H{"message": "The job is already running."})
+		return
+	case err != nil:
+		log.Println("Error running job:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not run job. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Job ran.",
+		"run":     run,
+	})
+}
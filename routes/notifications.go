@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getNotifications lists the notifications of the current user, newest
+// first
+func (h *Handler) getNotifications(c *gin.Context) {
+	opts, ok := listOptions(c, models.NotificationListSpec)
+	if !ok {
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	page, err := h.store.Notifications.List(c.Request.Context(), user.ID, opts)
+	if err != nil {
+		log.Println("Error fetching notifications:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch notifications. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if page.Items == nil {
+		page.Items = []models.Notification{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": page.Items,
+		"count":         len(page.Items),
+		"next_cursor":   page.NextCursor,
+		"total":         page.Total,
+	})
+}
+
+// markNotificationRead marks a notification of the current user as read
+func (h *Handler) markNotificationRead(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	if err := h.store.Notifications.MarkRead(c.Request.Context(), user.ID, c.Param("id")); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Notification not found."})
+			return
+		}
+		log.Println("Error marking notification read:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not update notification. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification marked as read.",
+	})
+}
+
+// markAllNotificationsRead marks every notification of the current user as
+// read
+func (h *Handler) markAllNotificationsRead(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	count, err := h.store.Notifications.MarkAllRead(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Println("Error marking notifications read:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not update notifications. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read.",
+		"count":   count,
+	})
+}
+
+// getNotificationPreferences returns how the current user wants to be
+// notified
+func (h *Handler) getNotificationPreferences(c *gin.Context) {
+	user := middleware.GetUserFromContext(c)
+	preferences, err := h.store.Notifications.GetPreferences(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Println("Error fetching notification preferences:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch notification preferences. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preferences": preferences,
+	})
+}
+
+// updateNotificationPreferences replaces the kinds of notification the
+// current user gets by email and webhook
+func (h *Handler) updateNotificationPreferences(c *gin.Context) {
+	var preferences models.NotificationPreferences
+	if err := c.ShouldBindJSON(&preferences); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	preferences.UserID = user.ID
+	if err := preferences.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid notification preferences.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Notifications.SavePreferences(c.Request.Context(), &preferences); err != nil {
+		log.Println("Error saving notification preferences:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not save notification preferences. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Notification preferences updated successfully!",
+		"preferences": preferences,
+	})
+}
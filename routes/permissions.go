@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getPermissions retrieves every permission that can be granted
+func (h *Handler) getPermissions(c *gin.Context) {
+	permissions, err := h.store.Permissions.List(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching permissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch permissions. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Return empty array instead of null
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+// getRolePermissions retrieves the permissions granted to each role
+func (h *Handler) getRolePermissions(c *gin.Context) {
+	grants, err := h.store.Permissions.GetRolePermissions(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching role permissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch role permissions. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// List every role, including roles without any grants
+	for _, role := range []models.UserRole{models.RoleFaculty, models.RoleStaff, models.RoleParent} {
+		if grants[role] == nil {
+			grants[role] = []string{}
+		}
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// updateRolePermissions replaces the permissions granted to a role
+func (h *Handler) updateRolePermissions(c *gin.Context) {
+	role := models.UserRole(c.Param("role"))
+	if !role.IsValid() {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Role not found."})
+		return
+	}
+
+	var request struct {
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Staff must keep the ability to edit permissions, otherwise nobody could
+	// undo a mistake through the API
+	if role == models.RoleStaff && !slices.Contains(request.Permissions, authz.PermPermissionsManage) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The staff role cannot lose the permissions:manage permission."})
+		return
+	}
+
+	if err := h.store.Permissions.SetRolePermissions(c.Request.Context(), role, request.Permissions); err != nil {
+		if errors.Is(err, models.ErrUnknownPermission) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Unknown permission."})
+			return
+		}
+		log.Println("Error updating role permissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not update role permissions. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Apply the change on this replica immediately; others pick it up
+	// when their cache expires
+	h.authorizer.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Role permissions updated successfully!",
+		"role":        role,
+		"permissions": request.Permissions,
+	})
+}
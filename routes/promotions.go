@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"github.com/gin-gonic/gin"
+)
+
+// previewPromotions plans the year-end rollover without changing anything
+func (h *Handler) previewPromotions(c *gin.Context) {
+	changes, ok := h.planPromotions(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, promotionSummary(changes))
+}
+
+// applyPromotions plans the year-end rollover and applies it in one
+// transaction. Students that changed since the plan was previewed make the
+// whole rollover fail with 409.
+func (h *Handler) applyPromotions(c *gin.Context) {
+	changes, ok := h.planPromotions(c)
+	if !ok {
+		return
+	}
+
+	user := middleware.GetUserFromContext(c)
+	if err := h.store.Promotions.Apply(c.Request.Context(), changes, user.ID); err != nil {
+		if errors.Is(err, models.ErrPromotionConflict) {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "Students changed or were already promoted on that date. Preview the promotions again.",
+				"error":   err.Error(),
+			})
+			return
+		}
+		log.Println("Error applying promotions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not apply promotions. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	body := promotionSummary(changes)
+	body["message"] = "Promotions applied successfully!"
+	c.JSON(http.StatusOK, body)
+}
+
+// planPromotions binds and validates a promotion plan and returns the grade
+// change of every active student, responding with an error otherwise
+func (h *Handler) planPromotions(c *gin.Context) ([]models.GradeChange, bool) {
+	var plan models.PromotionPlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+	if err := plan.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid promotion plan.",
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	page, err := h.store.Students.List(c.Request.Context(), models.ListOptions{})
+	if err != nil {
+		log.Println("Error fetching students:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch students. Try again later.",
+			"error":   err.Error(),
+		})
+		return nil, false
+	}
+
+	// Held back and graduated students must be active students
+	active := make(map[string]bool, len(page.Items))
+	for _, student := range page.Items {
+		active[student.ID] = student.IsActive
+	}
+	var unknown []string
+	for _, id := range append(append([]string{}, plan.HoldStudentIDs...), plan.GraduateStudentIDs...) {
+		if !active[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message":     "Held back and graduated students must be active students.",
+			"student_ids": unknown,
+		})
+		return nil, false
+	}
+
+	changes := plan.Changes(page.Items, h.gradeLevels)
+	if changes == nil {
+		changes = []models.GradeChange{} // Return empty array instead of null
+	}
+	return changes, true
+}
+
+// promotionSummary is the response body listing the grade changes with the
+// number of students per action
+func promotionSummary(changes []models.GradeChange) gin.H {
+	counts := map[models.PromotionAction]int{
+		models.PromotionPromote:  0,
+		models.PromotionHold:     0,
+		models.PromotionGraduate: 0,
+		models.PromotionSkip:     0,
+	}
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	return gin.H{
+		"promotions": changes,
+		"counts":     counts,
+		"count":      len(changes),
+	}
+}
+
+// getGradeHistory lists the grade-level changes of a student, oldest first
+func (h *Handler) getGradeHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	student, err := h.store.Students.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		log.Println("Error fetching student:", err)
+		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
+		return
+	}
+
+	history, err := h.store.Promotions.ListHistory(ctx, student.ID)
+	if err != nil {
+		log.Println("Error fetching grade history:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch grade history. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if history == nil {
+		history = []models.GradeChange{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"grade":         student.Grade,
+		"grade_history": history,
+		"count":         len(history),
+	})
+}
+
+// gradeLevels reads GRADE_LEVELS, a comma-separated list of the grade levels
+// from the first to the last, defaulting to K through 12
+func gradeLevels() []string {
+	var levels []string
+	for _, level := range strings.Split(os.Getenv("GRADE_LEVELS"), ",") {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) == 0 {
+		return models.DefaultGradeLevels
+	}
+	return levels
+}
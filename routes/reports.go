@@ -1,256 +1,530 @@
 package routes
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/export"
+	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/reporting"
 	"github.com/gin-gonic/gin"
 )
 
-// generateAttendanceReport generates a report on student attendance
-func generateAttendanceReport(c *gin.Context) {
+// generateAttendanceReport generates a report on student attendance. It
+// lists every active student matching the grade, class and student filters,
+// with their attendance over the date range, absence streaks and whether
+// they are chronically absent.
+func (h *Handler) generateAttendanceReport(c *gin.Context) {
 	log.Println("Generating attendance report...")
-	
-	// Get query parameters for date range
-	startDateStr := c.DefaultQuery("startDate", "")
-	endDateStr := c.DefaultQuery("endDate", "")
-	
-	var startDate, endDate time.Time
+
+	format, ok := reportFormat(c)
+	if !ok {
+		return
+	}
+
+	term, ok := h.queryTerm(c)
+	if !ok {
+		return
+	}
+	if term != nil && (c.Query("startDate") != "" || c.Query("endDate") != "") {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Use either termId or startDate and endDate."})
+		return
+	}
+
+	// The range defaults to the current month up to today, or to the days of
+	// the term; both ends are inclusive
+	today := models.Day(time.Now())
+	startDate := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := today
+	if term != nil {
+		startDate, endDate = term.StartDate, term.EndDate
+	}
 	var err error
-	
-	// If start date is not provided, use the beginning of the current month
-	if startDateStr == "" {
-		now := time.Now()
-		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	} else {
-		startDate, err = time.Parse("2006-01-02", startDateStr)
-		if err != nil {
+	if value := c.Query("startDate"); value != "" {
+		if startDate, err = time.Parse("2006-01-02", value); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid start date format. Use YYYY-MM-DD."})
 			return
 		}
 	}
-	
-	// If end date is not provided, use the current date
-	if endDateStr == "" {
-		endDate = time.Now()
-	} else {
-		endDate, err = time.Parse("2006-01-02", endDateStr)
-		if err != nil {
+	if value := c.Query("endDate"); value != "" {
+		if endDate, err = time.Parse("2006-01-02", value); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid end date format. Use YYYY-MM-DD."})
 			return
 		}
-		// Add one day to end date to include the end date in the range
-		endDate = endDate.Add(24 * time.Hour)
 	}
-	
-	// Get all attendance records for the date range
-	records, err := models.GetAttendanceByDateRange(startDate, endDate)
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "End date must not be before start date."})
+		return
+	}
+
+	threshold := reporting.ChronicAbsenceThreshold
+	if value := c.Query("chronicThreshold"); value != "" {
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold <= 0 || threshold > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid chronic threshold. Use a percentage between 0 and 100."})
+			return
+		}
+	}
+
+	filter := reporting.AttendanceFilter{
+		From:      startDate,
+		To:        endDate.AddDate(0, 0, 1),
+		Grade:     c.Query("grade"),
+		ClassID:   c.Query("classId"),
+		StudentID: c.Query("studentId"),
+	}
+
+	scope, err := middleware.GetScopeFromContext(c)
 	if err != nil {
-		log.Println("Error fetching attendance records:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Could not generate attendance report. Try again later.",
-			"error":   err.Error(),
-		})
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not generate attendance report. Try again later."})
 		return
 	}
-	
-	// Get all students
-	students, err := models.GetAllStudents()
+	if filter.StudentID != "" && !scope.CanAccessStudent(filter.StudentID) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "You do not have access to this student."})
+		return
+	}
+	if filter.ClassID != "" {
+		if !scope.CanAccessClass(filter.ClassID) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "You do not have access to this class."})
+			return
+		}
+		if _, err := h.store.Classes.GetByID(c.Request.Context(), filter.ClassID); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+				return
+			}
+			log.Println("Error fetching class:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not generate attendance report. Try again later.",
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	// Summarize the attendance of each student in scope over the date range;
+	// the filter is a backstop
+	filter.StudentIDs = scope.StudentIDs()
+	summaries, err := h.store.Reports.AttendanceSummaries(c.Request.Context(), filter)
 	if err != nil {
-		log.Println("Error fetching students:", err)
+		log.Println("Error summarizing attendance records:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not generate attendance report. Try again later.",
 			"error":   err.Error(),
 		})
 		return
 	}
-	
-	// Create a map of student IDs to student names
-	studentMap := make(map[string]string)
-	for _, student := range students {
-		studentMap[student.ID] = student.Name
+	summaries = authz.Filter(scope, summaries, func(s reporting.AttendanceSummary) string { return s.StudentID })
+
+	// Return empty array instead of null
+	reportData := []gin.H{}
+	table := export.Table{
+		Title:   "Attendance",
+		Columns: []string{"Student", "Grade", "Present", "Absent", "Tardy", "Excused", "Total", "% Present", "% Attended", "Longest Streak", "Current Streak", "Chronic"},
+		Widths:  []float64{3, 1, 1.1, 1.1, 1, 1.1, 1, 1.3, 1.3, 1.4, 1.4, 1.2},
 	}
-	
-	// Process records into a summary
-	summary := make(map[string]map[string]int)
-	for _, record := range records {
-		studentName := studentMap[record.StudentID]
-		
-		// Initialize summary for this student if it doesn't exist
-		if _, exists := summary[studentName]; !exists {
-			summary[studentName] = map[string]int{
-				"present": 0,
-				"absent":  0,
-				"tardy":   0,
-				"excused": 0,
-				"total":   0,
-			}
+	var chronic int
+	for _, summary := range summaries {
+		chronicallyAbsent := summary.ChronicallyAbsent(threshold)
+		if chronicallyAbsent {
+			chronic++
 		}
-		
-		// Increment the appropriate counter
-		summary[studentName][string(record.Status)]++
-		summary[studentName]["total"]++
-	}
-	
-	// Convert summary to a slice for easier rendering
-	var reportData []gin.H
-	for student, counts := range summary {
 		reportData = append(reportData, gin.H{
-			"student":  student,
-			"present":  counts["present"],
-			"absent":   counts["absent"],
-			"tardy":    counts["tardy"],
-			"excused":  counts["excused"],
-			"total":    counts["total"],
-			"percent_present": float64(counts["present"]) / float64(counts["total"]) * 100,
+			"student_id":             summary.StudentID,
+			"student":                summary.StudentName,
+			"grade":                  summary.Grade,
+			"present":                summary.Present,
+			"absent":                 summary.Absent,
+			"tardy":                  summary.Tardy,
+			"excused":                summary.Excused,
+			"total":                  summary.Total,
+			"percent_present":        summary.PercentPresent(),
+			"attendance_rate":        summary.AttendanceRate(),
+			"longest_absence_streak": summary.LongestAbsenceStreak,
+			"current_absence_streak": summary.CurrentAbsenceStreak,
+			"chronically_absent":     chronicallyAbsent,
+		})
+
+		var chronicFlag string
+		if chronicallyAbsent {
+			chronicFlag = "yes"
+		}
+		table.Rows = append(table.Rows, []string{
+			summary.StudentName,
+			summary.Grade,
+			strconv.Itoa(summary.Present),
+			strconv.Itoa(summary.Absent),
+			strconv.Itoa(summary.Tardy),
+			strconv.Itoa(summary.Excused),
+			strconv.Itoa(summary.Total),
+			formatPercent(summary.PercentPresent()),
+			formatPercent(summary.AttendanceRate()),
+			strconv.Itoa(summary.LongestAbsenceStreak),
+			strconv.Itoa(summary.CurrentAbsenceStreak),
+			chronicFlag,
 		})
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"report":     reportData,
-		"date_range": gin.H{"start_date": startDate.Format("2006-01-02"), "end_date": endDate.Format("2006-01-02")},
-		"count":      len(reportData),
+
+	dateRange := fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if term != nil {
+		dateRange = term.Name + ", " + dateRange
+	}
+	renderReport(c, format, "attendance-report", gin.H{
+		"report":             reportData,
+		"date_range":         gin.H{"start_date": startDate.Format("2006-01-02"), "end_date": endDate.Format("2006-01-02")},
+		"term":               term,
+		"filters":            gin.H{"grade": filter.Grade, "class_id": filter.ClassID, "student_id": filter.StudentID},
+		"chronic_threshold":  threshold,
+		"chronically_absent": chronic,
+		"count":              len(reportData),
+	}, export.Document{
+		Title:    "Attendance Report",
+		Subtitle: dateRange,
+		Tables:   []export.Table{table},
 	})
 }
 
-// generateGradesReport generates a report on student grades
-func generateGradesReport(c *gin.Context) {
+// generateGradesReport generates a report on student grades. Without a
+// class it averages the raw scores of every student; with classId it
+// returns the weighted gradebook of the class. termId limits either to the
+// assignments due in the term.
+func (h *Handler) generateGradesReport(c *gin.Context) {
 	log.Println("Generating grades report...")
+
+	format, ok := reportFormat(c)
+	if !ok {
+		return
+	}
+
+	term, ok := h.queryTerm(c)
+	if !ok {
+		return
+	}
+
+	if classID := c.Query("classId"); classID != "" {
+		h.generateClassGradebook(c, format, classID, term)
+		return
+	}
 	
-	// Get all students
-	students, err := models.GetAllStudents()
+	scope, err := middleware.GetScopeFromContext(c)
 	if err != nil {
-		log.Println("Error fetching students:", err)
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not generate grades report. Try again later."})
+		return
+	}
+
+	// Summarize the grades of every student in scope; the filter is a backstop
+	gradeFilter := termGradeFilter(term)
+	gradeFilter.StudentIDs = scope.StudentIDs()
+	summaries, err := h.store.Reports.GradeSummaries(c.Request.Context(), gradeFilter)
+	if err != nil {
+		log.Println("Error summarizing grades:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not generate grades report. Try again later.",
 			"error":   err.Error(),
 		})
 		return
 	}
-	
+	summaries = authz.Filter(scope, summaries, func(s reporting.GradeSummary) string { return s.StudentID })
+
 	var reportData []gin.H
-	
-	// For each student, get their grades
-	for _, student := range students {
-		grades, err := models.GetGradesByStudentID(student.ID)
-		if err != nil {
-			log.Printf("Error fetching grades for student %s: %v", student.ID, err)
-			continue
+	table := export.Table{
+		Title:   "Grades",
+		Columns: []string{"Student", "Grade", "Assignments", "Average", "Highest", "Lowest", "Missing"},
+		Widths:  []float64{3, 1, 1.3, 1, 1, 1, 1},
+	}
+	for _, summary := range summaries {
+		reportData = append(reportData, gin.H{
+			"student":     summary.StudentName,
+			"grade":       summary.Grade,
+			"assignments": summary.Assignments,
+			"average":     summary.Average(),
+			"highest":     summary.Highest,
+			"lowest":      summary.Lowest,
+			"missing":     summary.Missing,
+		})
+		table.Rows = append(table.Rows, []string{
+			summary.StudentName,
+			summary.Grade,
+			strconv.Itoa(summary.Assignments),
+			formatPercent(summary.Average()),
+			formatScore(summary.Highest),
+			formatScore(summary.Lowest),
+			strconv.Itoa(summary.Missing),
+		})
+	}
+
+	var subtitle string
+	if term != nil {
+		subtitle = term.Name
+	}
+	renderReport(c, format, "grades-report", gin.H{
+		"report": reportData,
+		"term":   term,
+		"count":  len(reportData),
+	}, export.Document{
+		Title:    "Grades Report",
+		Subtitle: subtitle,
+		Tables:   []export.Table{table},
+	})
+}
+
+// generateClassGradebook reports the weighted term average and letter grade
+// of every student on the roster of a class, following the categories,
+// scale and missing assignment policy of the class
+func (h *Handler) generateClassGradebook(c *gin.Context, format, classID string, term *models.Term) {
+	// Access is checked first so unknown classes look like any other class
+	// the caller does not teach
+	if !middleware.CheckClassAccess(c, classID) {
+		return
+	}
+	class, err := h.store.Classes.GetByID(c.Request.Context(), classID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Class not found."})
+			return
 		}
-		
-		if len(grades) == 0 {
-			// No grades for this student
-			reportData = append(reportData, gin.H{
-				"student":     student.Name,
-				"grade":       student.Grade,
-				"assignments": 0,
-				"average":     0,
-				"highest":     0,
-				"lowest":      0,
-				"missing":     0,
-			})
-			continue
+		log.Println("Error fetching class:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not generate grades report. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	settings, scale, err := h.gradebookSettings(c, class.ID)
+	if err != nil {
+		log.Println("Error fetching gradebook settings:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not generate grades report. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	categories, err := h.store.Gradebook.ListCategories(c.Request.Context(), class.ID, class.Subject)
+	if err != nil {
+		log.Println("Error fetching grading categories:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not generate grades report. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	students, err := h.store.Reports.ClassGradebook(c.Request.Context(), class.ID, termGradeFilter(term))
+	if err != nil {
+		log.Println("Error reading class grades:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not generate grades report. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	weights := make([]reporting.Category, len(categories))
+	categoryData := []gin.H{}
+	table := export.Table{
+		Title:   class.Name,
+		Columns: []string{"Student", "Grade"},
+		Widths:  []float64{3, 1},
+	}
+	for i, category := range categories {
+		weights[i] = reporting.Category{ID: category.ID, Name: category.Name, Weight: category.Weight}
+		categoryData = append(categoryData, gin.H{
+			"id":     category.ID,
+			"name":   category.Name,
+			"weight": category.Weight,
+		})
+		table.Columns = append(table.Columns, category.Name)
+		table.Widths = append(table.Widths, 1.3)
+	}
+	table.Columns = append(table.Columns, "Average", "Letter", "Missing")
+	table.Widths = append(table.Widths, 1, 1, 1)
+
+	reportData := []gin.H{}
+	excludeMissing := settings.MissingPolicy == models.MissingExcluded
+	for _, student := range students {
+		average := reporting.WeightedAverage(student.Grades, weights, excludeMissing)
+
+		// Students without counted grades have no average yet
+		var averageValue, letter interface{}
+		averageCell, letterCell := "", ""
+		if average.Graded > 0 {
+			averageCell, letterCell = formatPercent(average.Average), scale.Letter(average.Average)
+			averageValue, letter = average.Average, letterCell
 		}
-		
-		// Calculate statistics
-		var totalScore, totalPossible float64
-		var highest, lowest float64
-		var missing int
-		
-		for i, grade := range grades {
-			if grade.Status == models.AssignmentStatusMissing {
-				missing++
-				continue
-			}
-			
-			if i == 0 || grade.Score > highest {
-				highest = grade.Score
+
+		row := []string{student.StudentName, student.Grade}
+		categoryAverages := []gin.H{}
+		for _, category := range average.Categories {
+			entry := gin.H{
+				"category_id": category.CategoryID,
+				"name":        category.Name,
+				"share":       category.Share,
+				"graded":      category.Graded,
+				"average":     nil,
 			}
-			
-			if i == 0 || grade.Score < lowest {
-				lowest = grade.Score
+			cell := ""
+			if category.Graded > 0 {
+				entry["average"] = category.Average
+				cell = formatPercent(category.Average)
 			}
-			
-			totalScore += grade.Score
-			totalPossible += grade.MaxScore
+			categoryAverages = append(categoryAverages, entry)
+			row = append(row, cell)
 		}
-		
-		// Calculate average as a percentage
-		var average float64
-		if totalPossible > 0 {
-			average = (totalScore / totalPossible) * 100
-		}
-		
+		row = append(row, averageCell, letterCell, strconv.Itoa(average.Missing))
+
 		reportData = append(reportData, gin.H{
-			"student":     student.Name,
-			"grade":       student.Grade,
-			"assignments": len(grades),
-			"average":     average,
-			"highest":     highest,
-			"lowest":      lowest,
-			"missing":     missing,
+			"student_id":    student.StudentID,
+			"student":       student.StudentName,
+			"grade":         student.Grade,
+			"average":       averageValue,
+			"letter":        letter,
+			"graded":        average.Graded,
+			"missing":       average.Missing,
+			"uncategorized": average.Uncategorized,
+			"categories":    categoryAverages,
 		})
+		table.Rows = append(table.Rows, row)
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"report": reportData,
-		"count":  len(reportData),
+
+	subtitle := class.Name + " - " + class.Subject + ", " + class.Term
+	if term != nil {
+		subtitle += " (" + term.Name + ")"
+	}
+	renderReport(c, format, "gradebook-"+class.ID, gin.H{
+		"class": gin.H{
+			"id":      class.ID,
+			"name":    class.Name,
+			"subject": class.Subject,
+			"term":    class.Term,
+		},
+		"term":           term,
+		"missing_policy": settings.MissingPolicy,
+		"scale":          scale,
+		"categories":     categoryData,
+		"report":         reportData,
+		"count":          len(reportData),
+	}, export.Document{
+		Title:    "Gradebook",
+		Subtitle: subtitle,
+		Fields: []export.Field{
+			{Label: "Letter scale", Value: scale.Name},
+			{Label: "Missing assignments", Value: missingPolicyLabel(settings.MissingPolicy)},
+		},
+		Tables: []export.Table{table},
 	})
 }
 
-// generateStudentActivityReport generates a comprehensive report for a specific student
-func generateStudentActivityReport(c *gin.Context) {
+// missingPolicyLabel describes a missing assignment policy in reports
+func missingPolicyLabel(policy models.MissingPolicy) string {
+	if policy == models.MissingExcluded {
+		return "Excluded from averages"
+	}
+	return "Counted as zero"
+}
+
+// generateStudentActivityReport generates a comprehensive report for a
+// specific student, limited with termId to the term
+func (h *Handler) generateStudentActivityReport(c *gin.Context) {
 	studentID := c.Param("studentId")
-	
+
+	format, ok := reportFormat(c)
+	if !ok {
+		return
+	}
+	term, ok := h.queryTerm(c)
+	if !ok {
+		return
+	}
+
 	// Get the student
-	student, err := models.GetStudentByID(studentID)
+	student, err := h.store.Students.GetByID(c.Request.Context(), studentID)
 	if err != nil {
 		log.Println("Error fetching student:", err)
 		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
 		return
 	}
-	
-	// Get attendance records
-	attendance, err := models.GetAttendanceByStudentID(studentID)
+
+	body, doc, err := h.studentReport(c.Request.Context(), student, term)
 	if err != nil {
-		log.Println("Error fetching attendance:", err)
-		attendance = []models.Attendance{} // Continue with empty attendance
+		log.Println("Error generating student report:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not generate student report. Try again later.",
+			"error":   err.Error(),
+		})
+		return
 	}
-	
-	// Get grades
-	grades, err := models.GetGradesByStudentID(studentID)
-	if err != nil {
-		log.Println("Error fetching grades:", err)
-		grades = []models.Grade{} // Continue with empty grades
+
+	filename := "report-card-" + student.ID
+	if term != nil {
+		filename += "-" + term.ID
 	}
-	
-	// Get assignments for the grades
-	var assignmentMap = make(map[string]models.Assignment)
-	for _, grade := range grades {
-		assignment, err := models.GetAssignmentByID(grade.AssignmentID)
+	renderReport(c, format, filename, body, doc)
+}
+
+// studentReport gathers the attendance, grades, forum posts and parents of a
+// student into the body and document of the student report. With a term,
+// only the attendance, assignments due and posts of the term are included.
+// Finalizing a term stores its result as the student's report card.
+func (h *Handler) studentReport(ctx context.Context, student *models.Student, term *models.Term) (gin.H, export.Document, error) {
+	// A report on a term shows the grade level the student had at its end
+	if term != nil {
+		history, err := h.store.Promotions.ListHistory(ctx, student.ID)
 		if err != nil {
-			log.Printf("Error fetching assignment %s: %v", grade.AssignmentID, err)
-			continue
+			return nil, export.Document{}, fmt.Errorf("failed to fetch grade history: %w", err)
 		}
-		assignmentMap[grade.AssignmentID] = *assignment
+		past := *student
+		past.Grade = models.GradeAt(history, student.Grade, term.EndDate)
+		student = &past
 	}
-	
+
+	// Get attendance records
+	var attendanceOpts models.ListOptions
+	if term != nil {
+		attendanceOpts.Conditions = termConditions(term)
+	}
+	attendancePage, err := h.store.Attendance.ListByStudentID(ctx, student.ID, attendanceOpts)
+	if err != nil {
+		return nil, export.Document{}, fmt.Errorf("failed to fetch attendance: %w", err)
+	}
+	attendance := attendancePage.Items
+	if attendance == nil {
+		attendance = []models.Attendance{}
+	}
+
+	// Get grades together with their assignments
+	grades, err := h.store.Reports.StudentGrades(ctx, student.ID, termGradeFilter(term))
+	if err != nil {
+		return nil, export.Document{}, fmt.Errorf("failed to fetch grades: %w", err)
+	}
+
 	// Get forum posts
-	posts, err := models.GetForumPostsByStudentID(studentID)
+	postPage, err := h.store.Forum.ListPostsByStudentID(ctx, student.ID, models.ListOptions{})
 	if err != nil {
-		log.Println("Error fetching forum posts:", err)
-		posts = []models.ForumPost{} // Continue with empty posts
+		return nil, export.Document{}, fmt.Errorf("failed to fetch forum posts: %w", err)
 	}
-	
+	posts := []models.ForumPost{}
+	for _, post := range postPage.Items {
+		if term == nil || term.Contains(post.CreatedAt) {
+			posts = append(posts, post)
+		}
+	}
+
 	// Get parents
-	parents, err := models.GetParentsByStudentID(studentID)
+	parents, err := h.store.Users.ListParentsByStudentID(ctx, student.ID)
 	if err != nil {
-		log.Println("Error fetching parents:", err)
-		parents = []models.User{} // Continue with empty parents
+		return nil, export.Document{}, fmt.Errorf("failed to fetch parents: %w", err)
 	}
-	
+
 	// Remove sensitive information from parents
 	var parentData []gin.H
 	for _, parent := range parents {
@@ -261,7 +535,7 @@ func generateStudentActivityReport(c *gin.Context) {
 			"email":      parent.Email,
 		})
 	}
-	
+
 	// Calculate attendance statistics
 	attendanceStats := gin.H{
 		"total":   len(attendance),
@@ -270,30 +544,31 @@ func generateStudentActivityReport(c *gin.Context) {
 		"tardy":   0,
 		"excused": 0,
 	}
-	
+
 	for _, record := range attendance {
 		attendanceStats[string(record.Status)] = attendanceStats[string(record.Status)].(int) + 1
 	}
-	
+
 	// Calculate grade statistics
 	var totalScore, totalPossible float64
 	var completedAssignments, missingAssignments int
-	
+
 	for _, grade := range grades {
-		if grade.Status == models.AssignmentStatusCompleted || grade.Status == models.AssignmentStatusLate {
+		status := models.AssignmentStatus(grade.Status)
+		if status == models.AssignmentStatusCompleted || status == models.AssignmentStatusLate {
 			totalScore += grade.Score
 			totalPossible += grade.MaxScore
 			completedAssignments++
-		} else if grade.Status == models.AssignmentStatusMissing {
+		} else if status == models.AssignmentStatusMissing {
 			missingAssignments++
 		}
 	}
-	
+
 	var averageGrade float64
 	if totalPossible > 0 {
 		averageGrade = (totalScore / totalPossible) * 100
 	}
-	
+
 	gradeStats := gin.H{
 		"total_assignments":    len(grades),
 		"completed":            completedAssignments,
@@ -302,41 +577,216 @@ func generateStudentActivityReport(c *gin.Context) {
 		"total_score":          totalScore,
 		"total_possible_score": totalPossible,
 	}
-	
+
 	// Prepare grade details with assignment info
 	var gradeDetails []gin.H
 	for _, grade := range grades {
-		assignment, exists := assignmentMap[grade.AssignmentID]
-		if !exists {
-			continue
-		}
-		
 		gradeDetails = append(gradeDetails, gin.H{
-			"assignment":  assignment.Title,
-			"subject":     assignment.Subject,
-			"due_date":    assignment.DueDate,
+			"assignment":  grade.Assignment,
+			"subject":     grade.Subject,
+			"due_date":    grade.DueDate,
 			"score":       grade.Score,
 			"max_score":   grade.MaxScore,
-			"percentage":  (grade.Score / grade.MaxScore) * 100,
+			"percentage":  grade.Percentage(),
 			"status":      grade.Status,
 			"feedback":    grade.Feedback,
-			"graded_date": grade.UpdatedAt,
+			"graded_date": grade.GradedAt,
 		})
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
+
+	generatedAt := time.Now()
+	body := gin.H{
 		"student": gin.H{
-			"id":    student.ID,
-			"name":  student.Name,
-			"age":   student.Age,
-			"grade": student.Grade,
+			"id":              student.ID,
+			"name":            student.Name,
+			"email":           student.Email,
+			"age":             student.Age,
+			"grade":           student.Grade,
+			"enrollment_date": student.EnrollmentDate,
+			"is_active":       student.IsActive,
 		},
-		"parents":          parentData,
-		"attendance_stats": attendanceStats,
-		"grade_stats":      gradeStats,
-		"grades":           gradeDetails,
+		"term":              term,
+		"parents":           parentData,
+		"attendance_stats":  attendanceStats,
+		"grade_stats":       gradeStats,
+		"grades":            gradeDetails,
 		"recent_attendance": attendance,
-		"forum_posts":      posts,
-		"generated_at":     time.Now(),
-	})
+		"forum_posts":       posts,
+		"generated_at":      generatedAt,
+	}
+	doc := studentReportCard(student, term, parents, attendanceStats, gradeStats, grades, attendance, posts)
+	doc.GeneratedAt = generatedAt
+	return body, doc, nil
+}
+
+// studentReportCard lays out the data of the student activity report as a
+// printable report card
+func studentReportCard(student *models.Student, term *models.Term, parents []models.User, attendanceStats, gradeStats gin.H, grades []reporting.GradeDetail, attendance []models.Attendance, posts []models.ForumPost) export.Document {
+	status := "Active"
+	if !student.IsActive {
+		status = "Inactive"
+	}
+	var parentNames string
+	for i, parent := range parents {
+		if i > 0 {
+			parentNames += ", "
+		}
+		parentNames += fmt.Sprintf("%s %s <%s>", parent.FirstName, parent.LastName, parent.Email)
+	}
+
+	attendanceTable := export.Table{
+		Title:   "Attendance Summary",
+		Columns: []string{"Status", "Days"},
+	}
+	for _, key := range []string{"present", "absent", "tardy", "excused", "total"} {
+		attendanceTable.Rows = append(attendanceTable.Rows, []string{key, strconv.Itoa(attendanceStats[key].(int))})
+	}
+
+	gradeTable := export.Table{
+		Title:   "Grades",
+		Columns: []string{"Assignment", "Subject", "Due", "Score", "Max", "%", "Status", "Feedback"},
+		Widths:  []float64{3, 2, 1.8, 1, 1, 1, 1.6, 3},
+	}
+	for _, grade := range grades {
+		gradeTable.Rows = append(gradeTable.Rows, []string{
+			grade.Assignment,
+			grade.Subject,
+			grade.DueDate.Format("2006-01-02"),
+			formatScore(grade.Score),
+			formatScore(grade.MaxScore),
+			formatPercent(grade.Percentage()),
+			grade.Status,
+			grade.Feedback,
+		})
+	}
+
+	recentTable := export.Table{
+		Title:   "Recent Attendance",
+		Columns: []string{"Date", "Status", "Excuse"},
+		Widths:  []float64{1, 1, 3},
+	}
+	for _, record := range attendance {
+		recentTable.Rows = append(recentTable.Rows, []string{record.Date.Format("2006-01-02"), string(record.Status), record.Excuse})
+	}
+
+	postTable := export.Table{
+		Title:   "Forum Posts",
+		Columns: []string{"Date", "Title"},
+		Widths:  []float64{1, 4},
+	}
+	for _, post := range posts {
+		postTable.Rows = append(postTable.Rows, []string{post.CreatedAt.Format("2006-01-02"), post.Title})
+	}
+
+	subtitle := student.Name
+	var termFields []export.Field
+	if term != nil {
+		subtitle += " - " + term.Name
+		termFields = append(termFields, export.Field{
+			Label: "Term",
+			Value: fmt.Sprintf("%s (%s to %s)", term.Name, term.StartDate.Format("2006-01-02"), term.EndDate.Format("2006-01-02")),
+		})
+	}
+
+	return export.Document{
+		Title:    "Report Card",
+		Subtitle: subtitle,
+		Fields: append([]export.Field{
+			{Label: "Student", Value: student.Name},
+			{Label: "Email", Value: student.Email},
+			{Label: "Grade", Value: student.Grade},
+			{Label: "Age", Value: strconv.Itoa(student.Age)},
+			{Label: "Enrolled", Value: student.EnrollmentDate.Format("2006-01-02")},
+			{Label: "Status", Value: status},
+			{Label: "Parents", Value: parentNames},
+			{Label: "Average grade", Value: formatPercent(gradeStats["average_grade"].(float64))},
+			{Label: "Completed", Value: fmt.Sprintf("%d of %d assignments", gradeStats["completed"], gradeStats["total_assignments"])},
+			{Label: "Missing", Value: strconv.Itoa(gradeStats["missing"].(int))},
+		}, termFields...),
+		Tables: []export.Table{attendanceTable, gradeTable, recentTable, postTable},
+	}
+}
+
+// Report formats, as accepted by the format query parameter
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+	reportFormatPDF  = "pdf"
+)
+
+// Content types of the report formats
+const (
+	mimeCSV = "text/csv"
+	mimePDF = "application/pdf"
+)
+
+// reportFormat picks the format of a report from the format query parameter
+// or, without one, from the Accept header. It responds with an error and
+// returns false when the format is not supported.
+func reportFormat(c *gin.Context) (string, bool) {
+	if format := c.Query("format"); format != "" {
+		switch format {
+		case reportFormatJSON, reportFormatCSV, reportFormatPDF:
+			return format, true
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid format. Use json, csv or pdf."})
+		return "", false
+	}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, mimeCSV, mimePDF) {
+	case gin.MIMEJSON:
+		return reportFormatJSON, true
+	case mimeCSV:
+		return reportFormatCSV, true
+	case mimePDF:
+		return reportFormatPDF, true
+	}
+	c.JSON(http.StatusNotAcceptable, gin.H{"message": "Reports are available as application/json, text/csv or application/pdf."})
+	return "", false
+}
+
+// renderReport responds with body as JSON, or with doc as a CSV or PDF
+// attachment named after filename
+func renderReport(c *gin.Context, format, filename string, body gin.H, doc export.Document) {
+	if format == reportFormatJSON {
+		c.JSON(http.StatusOK, body)
+		return
+	}
+
+	if doc.GeneratedAt.IsZero() {
+		doc.GeneratedAt = time.Now()
+	}
+
+	var buf bytes.Buffer
+	var contentType string
+	var err error
+	switch format {
+	case reportFormatCSV:
+		contentType = mimeCSV + "; charset=utf-8"
+		err = export.WriteCSV(&buf, doc)
+	case reportFormatPDF:
+		contentType = mimePDF
+		err = export.WritePDF(&buf, doc)
+	}
+	if err != nil {
+		log.Printf("Error rendering %s report: %v", format, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not render report. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// formatPercent formats a percentage for CSV and PDF reports
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+// formatScore formats a score without trailing zeros
+func formatScore(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
 }
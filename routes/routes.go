@@ -2,9 +2,15 @@ package routes
 
 import (
 	"net/http"
+	"os"
+	"time"
 
+	"example.com/sre-bootcamp-rest-api/authz"
 	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/notify"
+	"example.com/sre-bootcamp-rest-api/scheduler"
+	"example.com/sre-bootcamp-rest-api/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,8 +19,33 @@ func HealthCheckHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-func RegisterRoutes(router *gin.RouterGroup) {
+// Handler serves the API routes. Its data comes from the store it was
+// created with, so the same handlers run against Postgres in production and
+// against the in-memory store in tests.
+type Handler struct {
+	store      *models.Store
+	authorizer *authz.Authorizer
+	// retention is how long a student stays archived before it can be purged
+	retention time.Duration
+	// gradeLevels is the order students are promoted through
+	gradeLevels []string
+	// blobs keeps the files attached to submissions
+	blobs storage.Blobs
+	// jobs runs the background jobs
+	jobs *scheduler.Scheduler
+	// notifier tells parents about absences, grades and forum activity
+	notifier *notify.Notifier
+}
+
+// RegisterRoutes registers the API routes, backed by the given store,
+// keeping uploaded files in blobs, reporting on the background jobs and
+// notifying parents through notifier
+func RegisterRoutes(router *gin.RouterGroup, store *models.Store, blobs storage.Blobs, jobs *scheduler.Scheduler, notifier *notify.Notifier) {
 	// Use router directly since it's already grouped with '/api/v1' in main.go
+	h := &Handler{store: store, authorizer: authz.NewAuthorizer(store), retention: studentRetention(), gradeLevels: gradeLevels(), blobs: blobs, jobs: jobs, notifier: notifier}
+
+	// Uploads, imports, reports and job runs outlast the query deadline
+	long := middleware.ExtendQueryTimeout(longRequestTimeout())
 
 	// Public routes (no authentication required)
 	{
@@ -22,121 +53,266 @@ func RegisterRoutes(router *gin.RouterGroup) {
 		router.GET("/healthcheck", HealthCheckHandler)
 
 		// Authentication routes
-		router.POST("/auth/register", registerUser)
-		router.POST("/auth/login", loginUser)
+		router.POST("/auth/register", h.registerUser)
+		router.POST("/auth/login", h.loginUser)
+		router.POST("/auth/refresh", h.refreshAccessToken)
+		router.POST("/auth/logout", h.logoutUser)
+		router.POST("/auth/invitations/redeem", h.redeemInvitation)
 	}
 
-	// Student routes (authentication required)
-	studentRoutes := router.Group("/students")
-	studentRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff, models.RoleParent))
+	// Every other route requires a valid access token. Each route then
+	// declares the permission it needs; grants per role live in the database.
+	api := router.Group("")
+	api.Use(middleware.Authenticate(h.authorizer))
+	can := middleware.RequirePermission
+
+	// Session routes for any authenticated user
+	sessionRoutes := api.Group("/auth")
 	{
-		studentRoutes.GET("", getStudents)
-		studentRoutes.GET("/:id", getStudent)
+		sessionRoutes.POST("/logout-all", h.logoutAllSessions)
 	}
 
-	// Additional student routes with restricted access
-	facultyStaffStudentRoutes := router.Group("/students")
-	facultyStaffStudentRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff))
+	// Inbox and delivery preferences of the current user
+	notificationRoutes := api.Group("/notifications")
+	notificationRoutes.Use(can(authz.PermNotificationsRead))
 	{
-		facultyStaffStudentRoutes.POST("", createStudent)
-		facultyStaffStudentRoutes.PUT("/:id", updateStudent)
-		facultyStaffStudentRoutes.DELETE("/:id", deleteStudent)
+		notificationRoutes.GET("", h.getNotifications)
+		notificationRoutes.POST("/:id/read", h.markNotificationRead)
+		notificationRoutes.POST("/read-all", h.markAllNotificationsRead)
+		notificationRoutes.GET("/preferences", h.getNotificationPreferences)
+		notificationRoutes.PUT("/preferences", h.updateNotificationPreferences)
 	}
 
-	// User routes (staff and faculty only)
-	userRoutes := router.Group("/users")
-	userRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff))
+	// Student routes
+	studentRoutes := api.Group("/students")
 	{
-		userRoutes.GET("", getUsers)
-		userRoutes.GET("/:id", getUserByID)
-		userRoutes.PUT("/:id", updateUser)
+		studentRoutes.GET("", can(authz.PermStudentsRead), h.getStudents)
+		studentRoutes.GET("/:id", can(authz.PermStudentsRead), middleware.RequireStudentAccess("id"), h.getStudent)
+		studentRoutes.POST("", can(authz.PermStudentsWrite), h.createStudent)
+		studentRoutes.PUT("/:id", can(authz.PermStudentsWrite), middleware.RequireStudentAccess("id"), h.updateStudent)
+		studentRoutes.DELETE("/:id", can(authz.PermStudentsDelete), middleware.RequireStudentAccess("id"), h.deleteStudent)
+
+		// Archived students are only visible to staff, who can restore
+		// them or purge them once the retention period has passed
+		studentRoutes.GET("/archived", can(authz.PermStudentsRestore), h.getArchivedStudents)
+		studentRoutes.POST("/:id/restore", can(authz.PermStudentsRestore), h.restoreStudent)
+		studentRoutes.DELETE("/:id/purge", can(authz.PermStudentsPurge), h.purgeStudent)
+
+		// Report cards taken when terms were finalized
+		studentRoutes.GET("/:id/report-cards", can(authz.PermReportCardsRead), middleware.RequireStudentAccess("id"), h.getReportCards)
+		studentRoutes.GET("/:id/report-cards/:cardId", long, can(authz.PermReportCardsRead), middleware.RequireStudentAccess("id"), h.getReportCard)
+
+		// Work handed in for the student
+		studentRoutes.GET("/:id/submissions", can(authz.PermSubmissionsRead), middleware.RequireStudentAccess("id"), h.getSubmissionsByStudentID)
+
+		// Grade levels the student had in past years
+		studentRoutes.GET("/:id/grade-history", can(authz.PermStudentsRead), middleware.RequireStudentAccess("id"), h.getGradeHistory)
 	}
 
-	// User deletion route (staff only)
-	staffUserRoutes := router.Group("/users")
-	staffUserRoutes.Use(middleware.AuthMiddleware(models.RoleStaff))
+	// Year-end rollover of grade levels; preview before applying
+	promotionRoutes := api.Group("/promotions")
+	promotionRoutes.Use(can(authz.PermPromotionsManage))
 	{
-		staffUserRoutes.DELETE("/:id", deleteUser)
+		promotionRoutes.POST("/preview", h.previewPromotions)
+		promotionRoutes.POST("", h.applyPromotions)
 	}
 
-	// Attendance routes
-	attendanceRoutes := router.Group("/attendance")
-	attendanceRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff))
+	// User routes
+	userRoutes := api.Group("/users")
 	{
-		attendanceRoutes.POST("", createAttendanceRecord)
-		attendanceRoutes.GET("/:id", getAttendanceByID)
-		attendanceRoutes.PUT("/:id", updateAttendanceRecord)
-		attendanceRoutes.GET("/student/:studentId", getAttendanceByStudentID)
-		attendanceRoutes.GET("/date-range", getAttendanceByDateRange)
+		userRoutes.GET("", can(authz.PermUsersRead), h.getUsers)
+		userRoutes.GET("/:id", can(authz.PermUsersRead), h.getUserByID)
+		userRoutes.PUT("/:id", can(authz.PermUsersWrite), h.updateUser)
+		userRoutes.DELETE("/:id", can(authz.PermUsersDelete), h.deleteUser)
+		userRoutes.POST("/:id/revoke-sessions", can(authz.PermUsersSessions), h.revokeUserSessions)
 	}
 
-	// Assignment routes with read access for all roles
-	assignmentRoutes := router.Group("/assignments")
-	assignmentRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff, models.RoleParent))
+	// Invitation routes
+	invitationRoutes := api.Group("/invitations")
+	invitationRoutes.Use(can(authz.PermInvitationsManage))
 	{
-		assignmentRoutes.GET("", getAssignments)
-		assignmentRoutes.GET("/:id", getAssignmentByID)
+		invitationRoutes.POST("", h.createInvitation)
+		invitationRoutes.GET("", h.getInvitations)
+		invitationRoutes.DELETE("/:id", h.revokeInvitation)
 	}
 
-	// Assignment routes with write access for faculty only
-	facultyAssignmentRoutes := router.Group("/assignments")
-	facultyAssignmentRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty))
+	// Permission management routes
+	permissionRoutes := api.Group("")
+	permissionRoutes.Use(can(authz.PermPermissionsManage))
 	{
-		facultyAssignmentRoutes.POST("", createAssignment)
-		facultyAssignmentRoutes.PUT("/:id", updateAssignment)
-		facultyAssignmentRoutes.DELETE("/:id", deleteAssignment)
+		permissionRoutes.GET("/permissions", h.getPermissions)
+		permissionRoutes.GET("/roles/permissions", h.getRolePermissions)
+		permissionRoutes.PUT("/roles/:role/permissions", h.updateRolePermissions)
 	}
 
-	// Grade routes with read access for all roles
-	gradeRoutes := router.Group("/grades")
-	gradeRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff, models.RoleParent))
+	// Audit log of changes to students, users, grades and attendance
+	api.GET("/audit", can(authz.PermAuditRead), h.getAuditLog)
+
+	// Bulk import of students, users and parent links from CSV files
+	api.POST("/import", long, can(authz.PermDataImport), h.importData)
+
+	// Status of the background jobs, which can also be run on demand
+	jobRoutes := api.Group("/admin/jobs")
+	jobRoutes.Use(can(authz.PermJobsManage))
 	{
-		gradeRoutes.GET("/:id", getGradeByID)
-		gradeRoutes.GET("/student/:studentId", getGradesByStudentID)
+		jobRoutes.GET("", h.getJobs)
+		jobRoutes.POST("/:name/run", long, h.runJob)
 	}
 
-	// Grade routes with write access for faculty only
-	facultyGradeRoutes := router.Group("/grades")
-	facultyGradeRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty))
+	// Class routes; faculty only see the classes they teach
+	classRoutes := api.Group("/classes")
 	{
-		facultyGradeRoutes.POST("", createGrade)
-		facultyGradeRoutes.PUT("/:id", updateGrade)
-		facultyGradeRoutes.GET("/assignment/:assignmentId", getGradesByAssignmentID)
+		classRoutes.GET("", can(authz.PermClassesRead), h.getClasses)
+		classRoutes.GET("/:id", can(authz.PermClassesRead), h.getClassByID)
+		classRoutes.POST("", can(authz.PermClassesManage), h.createClass)
+		classRoutes.PUT("/:id", can(authz.PermClassesManage), h.updateClass)
+		classRoutes.DELETE("/:id", can(authz.PermClassesManage), h.deleteClass)
+		classRoutes.GET("/:id/gradebook/settings", can(authz.PermClassesRead), h.getGradebookSettings)
+		classRoutes.PUT("/:id/gradebook/settings", can(authz.PermGradebookManage), h.updateGradebookSettings)
+	}
+
+	// Letter-grade scales are shared by every class
+	scaleRoutes := api.Group("/grade-scales")
+	{
+		scaleRoutes.GET("", can(authz.PermGradesRead), h.getGradeScales)
+		scaleRoutes.POST("", can(authz.PermGradebookConfigure), h.createGradeScale)
+		scaleRoutes.PUT("/:id", can(authz.PermGradebookConfigure), h.updateGradeScale)
+		scaleRoutes.DELETE("/:id", can(authz.PermGradebookConfigure), h.deleteGradeScale)
+	}
+
+	// Grading categories of a class or subject
+	categoryRoutes := api.Group("/grading-categories")
+	{
+		categoryRoutes.GET("", can(authz.PermGradesRead), h.getGradingCategories)
+		categoryRoutes.POST("", can(authz.PermGradebookManage), h.createGradingCategory) // Class or gradebook:configure check is done in the handler
+		categoryRoutes.PUT("/:id", can(authz.PermGradebookManage), h.updateGradingCategory)
+		categoryRoutes.DELETE("/:id", can(authz.PermGradebookManage), h.deleteGradingCategory)
+	}
+
+	// Attendance routes
+	attendanceRoutes := api.Group("/attendance")
+	{
+		attendanceRoutes.POST("", can(authz.PermAttendanceWrite), h.createAttendanceRecord)
+		attendanceRoutes.GET("/:id", can(authz.PermAttendanceRead), h.getAttendanceByID)
+		attendanceRoutes.PUT("/:id", can(authz.PermAttendanceWrite), h.updateAttendanceRecord)
+		attendanceRoutes.GET("/student/:studentId", can(authz.PermAttendanceRead), middleware.RequireStudentAccess("studentId"), h.getAttendanceByStudentID)
+		attendanceRoutes.GET("/date-range", can(authz.PermAttendanceRead), h.getAttendanceByDateRange)
+		attendanceRoutes.POST("/class/:classId", can(authz.PermAttendanceWrite), h.recordClassAttendance) // Teacher check is done in the handler
+	}
+
+	// Assignment routes
+	assignmentRoutes := api.Group("/assignments")
+	{
+		assignmentRoutes.GET("", can(authz.PermAssignmentsRead), h.getAssignments)
+		assignmentRoutes.GET("/:id", can(authz.PermAssignmentsRead), h.getAssignmentByID)
+		assignmentRoutes.POST("", can(authz.PermAssignmentsWrite), h.createAssignment)
+		assignmentRoutes.PUT("/:id", can(authz.PermAssignmentsWrite), h.updateAssignment)
+		assignmentRoutes.DELETE("/:id", can(authz.PermAssignmentsWrite), h.deleteAssignment)
+		assignmentRoutes.GET("/:id/submissions", can(authz.PermSubmissionsRead), h.getSubmissionsByAssignmentID)
+		assignmentRoutes.POST("/:id/submissions", long, can(authz.PermSubmissionsWrite), h.createSubmission) // Student check is done in the handler
+	}
+
+	// Submission routes
+	submissionRoutes := api.Group("/submissions")
+	submissionRoutes.Use(can(authz.PermSubmissionsRead))
+	{
+		submissionRoutes.GET("/:id", h.getSubmissionByID)
+		submissionRoutes.GET("/:id/files/:fileId", h.downloadSubmissionFile)
+	}
+
+	// Grade routes
+	gradeRoutes := api.Group("/grades")
+	{
+		gradeRoutes.GET("/:id", can(authz.PermGradesRead), h.getGradeByID)
+		gradeRoutes.GET("/student/:studentId", can(authz.PermGradesRead), middleware.RequireStudentAccess("studentId"), h.getGradesByStudentID)
+		gradeRoutes.POST("", can(authz.PermGradesWrite), h.createGrade)
+		gradeRoutes.PUT("/:id", can(authz.PermGradesWrite), h.updateGrade)
+		gradeRoutes.GET("/assignment/:assignmentId", can(authz.PermGradesWrite), h.getGradesByAssignmentID)
 	}
 
 	// Forum routes for parent-teacher communication
-	forumRoutes := router.Group("/forum")
-	forumRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff, models.RoleParent))
+	forumRoutes := api.Group("/forum")
 	{
-		forumRoutes.POST("/posts", createForumPost)
-		
+		forumRoutes.POST("/posts", can(authz.PermForumWrite), h.createForumPost)
+
 		// This specific route must come before the general /:id routes
-		forumRoutes.GET("/posts/student/:studentId", getForumPostsByStudentID)
-		forumRoutes.GET("/posts/comments/:postId", getCommentsByPostID) // Changed path to avoid conflict
-		
+		forumRoutes.GET("/posts/student/:studentId", can(authz.PermForumRead), middleware.RequireStudentAccess("studentId"), h.getForumPostsByStudentID)
+		forumRoutes.GET("/posts/comments/:postId", can(authz.PermForumRead), h.getCommentsByPostID) // Changed path to avoid conflict
+
 		// General post routes with :id parameter
-		forumRoutes.GET("/posts/:id", getForumPostByID)
-		forumRoutes.PUT("/posts/:id", updateForumPost) // Auth check is done in the handler
-		forumRoutes.DELETE("/posts/:id", deleteForumPost) // Auth check is done in the handler
+		forumRoutes.GET("/posts/:id", can(authz.PermForumRead), h.getForumPostByID)
+		forumRoutes.PUT("/posts/:id", can(authz.PermForumWrite), h.updateForumPost)    // Author check is done in the handler
+		forumRoutes.DELETE("/posts/:id", can(authz.PermForumWrite), h.deleteForumPost) // Author or forum:moderate check is done in the handler
 
 		// Comment routes
-		forumRoutes.POST("/comments", createForumComment)
-		forumRoutes.PUT("/comments/:id", updateForumComment) // Auth check is done in the handler
+		forumRoutes.POST("/comments", can(authz.PermForumWrite), h.createForumComment)
+		forumRoutes.PUT("/comments/:id", can(authz.PermForumWrite), h.updateForumComment) // Author check is done in the handler
+	}
+
+	// Academic year and term routes
+	yearRoutes := api.Group("/academic-years")
+	{
+		yearRoutes.GET("", can(authz.PermTermsRead), h.getAcademicYears)
+		yearRoutes.GET("/:id", can(authz.PermTermsRead), h.getAcademicYearByID)
+		yearRoutes.POST("", can(authz.PermTermsManage), h.createAcademicYear)
+		yearRoutes.PUT("/:id", can(authz.PermTermsManage), h.updateAcademicYear)
+		yearRoutes.DELETE("/:id", can(authz.PermTermsManage), h.deleteAcademicYear)
 	}
 
-	// Report routes for faculty and staff
-	reportRoutes := router.Group("/reports")
-	reportRoutes.Use(middleware.AuthMiddleware(models.RoleFaculty, models.RoleStaff))
+	termRoutes := api.Group("/terms")
 	{
-		reportRoutes.GET("/attendance", generateAttendanceReport)
-		reportRoutes.GET("/grades", generateGradesReport)
-		reportRoutes.GET("/student/:studentId", generateStudentActivityReport)
+		termRoutes.GET("/current", can(authz.PermTermsRead), h.getCurrentTerm)
+		termRoutes.GET("/:id", can(authz.PermTermsRead), h.getTermByID)
+		termRoutes.POST("", can(authz.PermTermsManage), h.createTerm)
+		termRoutes.PUT("/:id", can(authz.PermTermsManage), h.updateTerm)
+		termRoutes.DELETE("/:id", can(authz.PermTermsManage), h.deleteTerm)
+		termRoutes.POST("/:id/finalize", can(authz.PermTermsFinalize), h.finalizeTerm)
 	}
 
-	// Special route for parents to view their children's reports
-	parentReportRoutes := router.Group("/reports")
-	parentReportRoutes.Use(middleware.AuthMiddleware(models.RoleParent))
+	// Report routes
+	reportRoutes := api.Group("/reports")
+	reportRoutes.Use(long)
 	{
-		parentReportRoutes.GET("/student/:studentId/parent", generateStudentActivityReport)
+		reportRoutes.GET("/attendance", can(authz.PermReportsRead), h.generateAttendanceReport)
+		reportRoutes.GET("/grades", can(authz.PermReportsRead), h.generateGradesReport)
+		reportRoutes.GET("/student/:studentId", can(authz.PermReportsRead), middleware.RequireStudentAccess("studentId"), h.generateStudentActivityReport)
+
+		// Reports for parents on their linked children
+		reportRoutes.GET("/student/:studentId/parent", can(authz.PermReportsChildren), middleware.RequireStudentAccess("studentId"), h.generateStudentActivityReport)
+	}
+}
+
+// listOptions parses the pagination, sort and filter parameters of a list
+// request, responding with 400 when they are invalid
+func listOptions(c *gin.Context, spec models.ListSpec) (models.ListOptions, bool) {
+	opts, err := models.ParseListOptions(c.Request.URL.Query(), spec)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid list parameters.",
+			"error":   err.Error(),
+		})
+		return models.ListOptions{}, false
+	}
+	return opts, true
+}
+
+// longRequestTimeout reads LONG_REQUEST_TIMEOUT, the deadline of uploads,
+// imports, reports and job runs, defaulting to five minutes
+func longRequestTimeout() time.Duration {
+	if value := os.Getenv("LONG_REQUEST_TIMEOUT"); value != "" {
+		if timeout, err := time.ParseDuration(value); err == nil {
+			return timeout
+		}
+	}
+	return 5 * time.Minute
+}
+
+// studentRetention reads STUDENT_RETENTION_PERIOD, defaulting to one year
+func studentRetention() time.Duration {
+	if value := os.Getenv("STUDENT_RETENTION_PERIOD"); value != "" {
+		if retention, err := time.ParseDuration(value); err == nil {
+			return retention
+		}
 	}
+	return 365 * 24 * time.Hour
 }
@@ -0,0 +1,1384 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/sre-bootcamp-rest-api/auth"
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/models/memory"
+	"example.com/sre-bootcamp-rest-api/notify"
+	"example.com/sre-bootcamp-rest-api/scheduler"
+	"example.com/sre-bootcamp-rest-api/storage"
+)
+
+// newTestServer returns the API backed by an empty in-memory store
+func newTestServer(t *testing.T) (*gin.Engine, *models.Store) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	require.NoError(t, auth.InitAuth())
+
+	store := memory.NewStore()
+	blobs, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	router := gin.New()
+	notifier := notify.New(store)
+	jobs := scheduler.New(scheduler.NewLocalLocker(), store.Jobs, scheduler.DefaultJobs(store, notifier)...)
+	RegisterRoutes(router.Group("/api/v1"), store, blobs, jobs, notifier)
+	return router, store
+}
+
+// createUser stores a user with the password "password"
+func createUser(t *testing.T, store *models.Store, username string, role models.UserRole, studentIDs ...string) *models.User {
+	t.Helper()
+	user := &models.User{
+		Username:   username,
+		Email:      username + "@example.com",
+		Password:   "password",
+		FirstName:  username,
+		LastName:   "Test",
+		Role:       role,
+		StudentIDs: studentIDs,
+	}
+	require.NoError(t, store.Users.Create(context.Background(), user))
+	return user
+}
+
+// newStudent returns an active student born in 2016
+func newStudent(name, grade string) *models.Student {
+	return &models.Student{
+		Name:        name,
+		Email:       strings.ToLower(name) + "@example.com",
+		DateOfBirth: time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC),
+		Grade:       grade,
+		IsActive:    true,
+	}
+}
+
+// login returns an access token for the user
+func login(t *testing.T, router *gin.Engine, username string) string {
+	t.Helper()
+	w := request(router, http.MethodPost, "/api/v1/auth/login", "", gin.H{"username": username, "password": "password"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Token
+}
+
+// request sends a JSON request, authenticated when a token is given
+func request(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// Test the student CRUD routes end to end
+func TestStudentRoutes(t *testing.T) {
+	router, store := newTestServer(t)
+	createUser(t, store, "admin", models.RoleStaff)
+	token := login(t, router, "admin")
+
+	w := request(router, http.MethodPost, "/api/v1/students", token, gin.H{"name": "Ann", "email": "ann@example.com", "grade": "5"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "date of birth is required")
+
+	dateOfBirth := time.Now().AddDate(-10, 0, -1)
+	w = request(router, http.MethodPost, "/api/v1/students", token, gin.H{"name": "Ann", "email": "ann@example.com", "date_of_birth": dateOfBirth, "grade": "5"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Student models.Student `json:"student"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	id := created.Student.ID
+	assert.Equal(t, 10, created.Student.Age)
+	assert.True(t, created.Student.IsActive)
+	assert.False(t, created.Student.EnrollmentDate.IsZero())
+
+	w = request(router, http.MethodPut, "/api/v1/students/"+id, token, gin.H{"email": "not-an-email"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = request(router, http.MethodPut, "/api/v1/students/"+id, token, gin.H{"name": "Ann", "email": "ann@example.com", "date_of_birth": dateOfBirth, "grade": "6"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/students/"+id, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"grade":"6"`)
+	assert.Contains(t, w.Body.String(), `"is_active":true`)
+
+	// Inactive students are only listed on request
+	w = request(router, http.MethodPut, "/api/v1/students/"+id, token, gin.H{"is_active": false})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodGet, "/api/v1/students", token, nil)
+	assert.Contains(t, w.Body.String(), `"total":0`)
+	w = request(router, http.MethodGet, "/api/v1/students?include_inactive=true", token, nil)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	w = request(router, http.MethodGet, "/api/v1/students?is_active=false", token, nil)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	w = request(router, http.MethodDelete, "/api/v1/students/"+id, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/students/"+id, token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// Test that routes require a token and the matching permission
+func TestRoutesRequirePermission(t *testing.T) {
+	router, store := newTestServer(t)
+	createUser(t, store, "parent", models.RoleParent)
+	token := login(t, router, "parent")
+
+	w := request(router, http.MethodGet, "/api/v1/students", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request(router, http.MethodPost, "/api/v1/students", token, newStudent("Ann", "5"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// Test that staff invite users with a role and that each code creates at
+// most one account before it expires
+func TestInvitations(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	student := newStudent("Ann", "5")
+	require.NoError(t, store.Students.Create(ctx, student))
+	staff := createUser(t, store, "staff", models.RoleStaff)
+	createUser(t, store, "teacher", models.RoleFaculty)
+	staffToken, teacherToken := login(t, router, "staff"), login(t, router, "teacher")
+
+	invite := gin.H{"email": "pat@example.com", "role": models.RoleParent, "student_ids": []string{student.ID}}
+	w := request(router, http.MethodPost, "/api/v1/invitations", teacherToken, invite)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/invitations", staffToken, invite)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Code)
+
+	// The role, email and students come from the invitation, not the body
+	redeem := gin.H{"code": created.Code, "username": "pat", "password": "password", "first_name": "Pat", "last_name": "Test", "role": models.RoleStaff}
+	w = request(router, http.MethodPost, "/api/v1/auth/invitations/redeem", "", redeem)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	user, err := store.Users.GetByUsername(ctx, "pat")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleParent, user.Role)
+	assert.Equal(t, "pat@example.com", user.Email)
+	assert.Equal(t, []string{student.ID}, user.StudentIDs)
+	login(t, router, "pat")
+
+	redeem["username"] = "pat2"
+	w = request(router, http.MethodPost, "/api/v1/auth/invitations/redeem", "", redeem)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "codes are single-use")
+
+	expired := &models.Invitation{Email: "late@example.com", Role: models.RoleFaculty, ExpiresAt: time.Now().Add(-time.Minute), CreatedBy: staff.ID}
+	code, err := store.Invitations.Create(ctx, expired)
+	require.NoError(t, err)
+	w = request(router, http.MethodPost, "/api/v1/auth/invitations/redeem", "", gin.H{"code": code, "username": "late", "password": "password", "first_name": "Late", "last_name": "Test"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = request(router, http.MethodPost, "/api/v1/auth/invitations/redeem", "", gin.H{"code": "unknown", "username": "nobody", "password": "password", "first_name": "No", "last_name": "Body"})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	_, err = store.Users.GetByUsername(ctx, "late")
+	assert.ErrorIs(t, err, models.ErrNotFound)
+}
+
+// Test that parents only see the students linked to them
+func TestParentScope(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+
+	own := newStudent("Ann", "5")
+	other := newStudent("Bob", "6")
+	require.NoError(t, store.Students.Create(ctx, own))
+	require.NoError(t, store.Students.Create(ctx, other))
+	createUser(t, store, "parent", models.RoleParent, own.ID)
+	token := login(t, router, "parent")
+
+	w := request(router, http.MethodGet, "/api/v1/students", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Students []models.Student `json:"students"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Students, 1)
+	assert.Equal(t, own.ID, list.Students[0].ID)
+
+	w = request(router, http.MethodGet, "/api/v1/students/"+other.ID, token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// Test that permission changes apply to the next request
+func TestUpdateRolePermissions(t *testing.T) {
+	router, store := newTestServer(t)
+	createUser(t, store, "admin", models.RoleStaff)
+	createUser(t, store, "parent", models.RoleParent)
+	adminToken := login(t, router, "admin")
+	parentToken := login(t, router, "parent")
+
+	w := request(router, http.MethodGet, "/api/v1/users", parentToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = request(router, http.MethodPut, "/api/v1/roles/parent/permissions", adminToken, gin.H{"permissions": []string{"users:read"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/users", parentToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// Test that list routes page through results with a cursor
+func TestListPagination(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	for _, name := range []string{"Cy", "Ann", "Bob"} {
+		require.NoError(t, store.Students.Create(ctx, newStudent(name, "5")))
+	}
+	createUser(t, store, "admin", models.RoleStaff)
+	token := login(t, router, "admin")
+
+	type page struct {
+		Students   []models.Student `json:"students"`
+		NextCursor string           `json:"next_cursor"`
+		Total      int              `json:"total"`
+	}
+
+	var names []string
+	path := "/api/v1/students?limit=2"
+	for {
+		w := request(router, http.MethodGet, path, token, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var p page
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, 3, p.Total)
+		for _, s := range p.Students {
+			names = append(names, s.Name)
+		}
+		if p.NextCursor == "" {
+			break
+		}
+		path = "/api/v1/students?limit=2&cursor=" + p.NextCursor
+	}
+	assert.Equal(t, []string{"Ann", "Bob", "Cy"}, names)
+
+	w := request(router, http.MethodGet, "/api/v1/students?sort=-name&name=b", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var p page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	require.Len(t, p.Students, 1)
+	assert.Equal(t, "Bob", p.Students[0].Name)
+
+	w = request(router, http.MethodGet, "/api/v1/students?sort=password", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/students?limit=1000", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// Test that deleting a student archives it until it is restored or purged
+func TestArchiveStudent(t *testing.T) {
+	t.Setenv("STUDENT_RETENTION_PERIOD", "0s")
+	router, store := newTestServer(t)
+	ctx := context.Background()
+
+	student := newStudent("Ann", "5")
+	require.NoError(t, store.Students.Create(ctx, student))
+	require.NoError(t, store.Attendance.Create(ctx, &models.Attendance{StudentID: student.ID, Date: time.Now(), Status: models.AttendanceStatusPresent, RecordedBy: "teacher"}))
+	createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "admin", models.RoleStaff)
+	teacherToken := login(t, router, "teacher")
+	adminToken := login(t, router, "admin")
+	path := "/api/v1/students/" + student.ID
+
+	w := request(router, http.MethodDelete, path+"/purge", adminToken, gin.H{"confirm": student.ID})
+	assert.Equal(t, http.StatusConflict, w.Code, "only archived students can be purged")
+
+	w = request(router, http.MethodDelete, path, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodGet, path, adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = request(router, http.MethodGet, "/api/v1/students/archived", teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/students/archived", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"archived_by":"`)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	w = request(router, http.MethodPost, path+"/restore", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"is_active":true`)
+	page, err := store.Attendance.ListByStudentID(ctx, student.ID, models.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1, "archiving keeps the student's records")
+
+	w = request(router, http.MethodDelete, path, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = request(router, http.MethodDelete, path+"/purge", adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "purging needs confirmation")
+	w = request(router, http.MethodDelete, path+"/purge", adminToken, gin.H{"confirm": student.ID})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodPost, path+"/restore", adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	page, err = store.Attendance.ListByStudentID(ctx, student.ID, models.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+// Test that changes show up in the audit log with their author
+func TestAuditLog(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+
+	student := newStudent("Ann", "5")
+	require.NoError(t, store.Students.Create(ctx, student))
+	record := &models.Attendance{StudentID: student.ID, Date: time.Now(), Status: models.AttendanceStatusPresent, RecordedBy: "teacher"}
+	require.NoError(t, store.Attendance.Create(ctx, record))
+	createUser(t, store, "teacher", models.RoleFaculty)
+	admin := createUser(t, store, "admin", models.RoleStaff)
+	teacherToken := login(t, router, "teacher")
+	adminToken := login(t, router, "admin")
+
+	w := request(router, http.MethodPut, "/api/v1/attendance/"+record.ID, adminToken, gin.H{
+		"student_id":  student.ID,
+		"date":        record.Date,
+		"status":      models.AttendanceStatusAbsent,
+		"recorded_by": "teacher",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/audit?entity_type=attendance&entity_id="+record.ID, teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = request(router, http.MethodGet, "/api/v1/audit?entity_type=attendance&entity_id="+record.ID, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Entries []struct {
+			ActorID string `json:"actor_id"`
+			Action  string `json:"action"`
+			Before  *models.Attendance
+			After   *models.Attendance
+		} `json:"entries"`
+		Total int `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 2, response.Total)
+	update := response.Entries[0]
+	assert.Equal(t, "update", update.Action)
+	assert.Equal(t, admin.ID, update.ActorID)
+	require.NotNil(t, update.Before)
+	require.NotNil(t, update.After)
+	assert.Equal(t, models.AttendanceStatusPresent, update.Before.Status)
+	assert.Equal(t, models.AttendanceStatusAbsent, update.After.Status)
+	assert.Equal(t, "create", response.Entries[1].Action)
+	assert.Nil(t, response.Entries[1].Before)
+
+	w = request(router, http.MethodGet, "/api/v1/audit?actor_id="+admin.ID, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+}
+
+// Test taking the attendance of a whole class at once
+func TestRecordClassAttendance(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+
+	ann, bob, cat := newStudent("Ann", "5"), newStudent("Bob", "5"), newStudent("Cat", "5")
+	for _, student := range []*models.Student{ann, bob, cat} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "other", models.RoleFaculty)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID, bob.ID, cat.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	existing := &models.Attendance{StudentID: ann.ID, Date: date, Status: models.AttendanceStatusAbsent, RecordedBy: teacher.ID}
+	require.NoError(t, store.Attendance.Create(ctx, existing))
+
+	body := gin.H{
+		"date": date.Add(9 * time.Hour),
+		"entries": []gin.H{
+			{"student_id": ann.ID, "status": "present"},
+			{"student_id": bob.ID, "status": "excused", "excuse": "Dentist"},
+			{"student_id": "stranger", "status": "present"},
+		},
+		"default_present": true,
+	}
+	path := "/api/v1/attendance/class/" + class.ID
+
+	w := request(router, http.MethodPost, path, login(t, router, "other"), body)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = request(router, http.MethodPost, path, login(t, router, "teacher"), body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Results []models.ClassAttendanceResult `json:"results"`
+		Created int                            `json:"created"`
+		Updated int                            `json:"updated"`
+		Failed  int                            `json:"failed"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 1, response.Updated)
+	assert.Equal(t, 1, response.Failed)
+	require.Len(t, response.Results, 4)
+	assert.Equal(t, models.AttendanceUpdated, response.Results[0].Result)
+	assert.Equal(t, existing.ID, response.Results[0].Attendance.ID)
+	assert.Equal(t, models.AttendanceFailed, response.Results[2].Result)
+	assert.Equal(t, cat.ID, response.Results[3].StudentID, "unlisted students default to present")
+
+	page, err := store.Attendance.ListByStudentID(ctx, ann.ID, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1, "existing records are replaced")
+	assert.Equal(t, models.AttendanceStatusPresent, page.Items[0].Status)
+	page, err = store.Attendance.ListByStudentID(ctx, cat.ID, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, models.AttendanceStatusPresent, page.Items[0].Status)
+}
+
+// Test that teachers only change grades of classes they teach and cannot
+// move a grade to another student or assignment, that assignments without
+// a class are graded by their creator only and that assignments are only
+// moved to existing classes
+func TestUpdateGradeClassAccess(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob := newStudent("Ann", "5"), newStudent("Bob", "5")
+	for _, student := range []*models.Student{ann, bob} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	mine, theirs := createUser(t, store, "mine", models.RoleFaculty), createUser(t, store, "theirs", models.RoleFaculty)
+	math := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{mine.ID}, StudentIDs: []string{ann.ID, bob.ID}}
+	art := &models.Class{Name: "5B", Subject: "Art", Term: "Fall", TeacherIDs: []string{theirs.ID}, StudentIDs: []string{ann.ID}}
+	require.NoError(t, store.Classes.Create(ctx, math))
+	require.NoError(t, store.Classes.Create(ctx, art))
+	quiz := &models.Assignment{Title: "Quiz", Subject: "Math", ClassID: math.ID, CreatedBy: mine.ID, DueDate: time.Now()}
+	drawing := &models.Assignment{Title: "Drawing", Subject: "Art", ClassID: art.ID, CreatedBy: theirs.ID, DueDate: time.Now()}
+	require.NoError(t, store.Grades.CreateAssignment(ctx, quiz))
+	require.NoError(t, store.Grades.CreateAssignment(ctx, drawing))
+	quizGrade := &models.Grade{StudentID: ann.ID, AssignmentID: quiz.ID, Score: 50, MaxScore: 100, Status: models.AssignmentStatusCompleted, GradedBy: mine.ID}
+	drawingGrade := &models.Grade{StudentID: ann.ID, AssignmentID: drawing.ID, Score: 80, MaxScore: 100, Status: models.AssignmentStatusCompleted, GradedBy: theirs.ID}
+	require.NoError(t, store.Grades.CreateGrade(ctx, quizGrade))
+	require.NoError(t, store.Grades.CreateGrade(ctx, drawingGrade))
+	token := login(t, router, "mine")
+
+	// Ann is in both classes, but the art grade belongs to the art teacher,
+	// whatever assignment the body names
+	for _, assignmentID := range []string{drawing.ID, quiz.ID} {
+		w := request(router, http.MethodPut, "/api/v1/grades/"+drawingGrade.ID, token, gin.H{
+			"student_id": ann.ID, "assignment_id": assignmentID, "score": 0, "max_score": 100, "status": "completed", "graded_by": mine.ID,
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	}
+	grade, err := store.Grades.GetGradeByID(ctx, drawingGrade.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, grade.Score)
+	assert.Equal(t, drawing.ID, grade.AssignmentID)
+
+	// The student and assignment of a grade never change
+	w := request(router, http.MethodPut, "/api/v1/grades/"+quizGrade.ID, token, gin.H{
+		"student_id": bob.ID, "assignment_id": drawing.ID, "score": 70, "max_score": 100, "status": "completed", "graded_by": mine.ID,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	grade, err = store.Grades.GetGradeByID(ctx, quizGrade.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 70.0, grade.Score)
+	assert.Equal(t, ann.ID, grade.StudentID)
+	assert.Equal(t, quiz.ID, grade.AssignmentID)
+
+	// Assignments are only moved to classes that exist
+	createUser(t, store, "admin", models.RoleStaff)
+	adminToken := login(t, router, "admin")
+	w = request(router, http.MethodPut, "/api/v1/roles/staff/permissions", adminToken, gin.H{"permissions": []string{"permissions:manage", "assignments:write"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodPut, "/api/v1/assignments/"+quiz.ID, adminToken, gin.H{
+		"title": "Quiz", "subject": "Math", "due_date": quiz.DueDate, "class_id": "unknown", "max_score": 100, "created_by": mine.ID,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// Assignments from before classes are graded by their creator only
+	legacy := &models.Assignment{Title: "Essay", Subject: "Math", ClassID: math.ID, CreatedBy: mine.ID, DueDate: time.Now()}
+	require.NoError(t, store.Grades.CreateAssignment(ctx, legacy))
+	legacy.ClassID = ""
+	require.NoError(t, store.Grades.UpdateAssignment(ctx, legacy))
+	for username, want := range map[string]int{"theirs": http.StatusForbidden, "mine": http.StatusCreated} {
+		w = request(router, http.MethodPost, "/api/v1/grades", login(t, router, username), gin.H{
+			"student_id": ann.ID, "assignment_id": legacy.ID, "score": 9, "max_score": 10, "status": "completed", "graded_by": mine.ID,
+		})
+		assert.Equal(t, want, w.Code, username+": "+w.Body.String())
+	}
+}
+
+// Test importing CSV files, first as a dry run
+func TestImportData(t *testing.T) {
+	router, store := newTestServer(t)
+	createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "admin", models.RoleStaff)
+	adminToken := login(t, router, "admin")
+
+	upload := func(path, token string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		file, err := form.CreateFormFile("students", "students.csv")
+		require.NoError(t, err)
+		_, _ = file.Write([]byte("name,email,date_of_birth,grade\nAnn Lee,ann@example.com,2016-04-01,5\n"))
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, path, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := upload("/api/v1/import", login(t, router, "teacher"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = upload("/api/v1/import?dry_run=true", adminToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodGet, "/api/v1/students", adminToken, nil)
+	assert.Contains(t, w.Body.String(), `"total":0`)
+
+	w = upload("/api/v1/import", adminToken)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = request(router, http.MethodGet, "/api/v1/students", adminToken, nil)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	w = upload("/api/v1/import", adminToken)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `{"file":"students","line":2,"message":"student email already exists"}`)
+}
+
+// Test that reports are rendered in the format asked for by the format
+// parameter or the Accept header
+func TestReportFormats(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob := newStudent("Ann", "5"), newStudent("Bob", "6")
+	require.NoError(t, store.Students.Create(ctx, bob))
+	require.NoError(t, store.Students.Create(ctx, ann))
+	createUser(t, store, "admin", models.RoleStaff)
+	token := login(t, router, "admin")
+
+	w := request(router, http.MethodGet, "/api/v1/reports/grades?format=csv", token, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="grades-report.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "Student,Grade,Assignments,Average,Highest,Lowest,Missing\nAnn,5,0,0.0,0,0,0\nBob,6,0,0.0,0,0,0\n", w.Body.String())
+
+	accept := func(path, mime string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", mime)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = accept("/api/v1/reports/student/"+ann.ID, "application/pdf")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF-"))
+
+	w = accept("/api/v1/reports/grades", "application/json")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":2`)
+
+	w = accept("/api/v1/reports/grades", "image/png")
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+
+	w = request(router, http.MethodGet, "/api/v1/reports/attendance?format=xml", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// Test that the grades report summarizes graded assignments and leaves
+// missing ones out of the average
+func TestGradesReport(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann := newStudent("Ann", "5")
+	require.NoError(t, store.Students.Create(ctx, ann))
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "admin", models.RoleStaff)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+
+	for i, grade := range []models.Grade{
+		{Score: 9, MaxScore: 10, Status: models.AssignmentStatusCompleted},
+		{Score: 6, MaxScore: 10, Status: models.AssignmentStatusLate},
+		{Score: 0, MaxScore: 10, Status: models.AssignmentStatusMissing},
+	} {
+		assignment := &models.Assignment{Title: fmt.Sprintf("Quiz %d", i+1), Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Date(2026, 10, i+1, 0, 0, 0, 0, time.UTC)}
+		require.NoError(t, store.Grades.CreateAssignment(ctx, assignment))
+		grade.StudentID, grade.AssignmentID, grade.GradedBy = ann.ID, assignment.ID, teacher.ID
+		require.NoError(t, store.Grades.CreateGrade(ctx, &grade))
+	}
+
+	w := request(router, http.MethodGet, "/api/v1/reports/grades?format=csv", login(t, router, "admin"), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "Student,Grade,Assignments,Average,Highest,Lowest,Missing\nAnn,5,3,75.0,9,6,1\n", w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/reports/student/"+ann.ID, login(t, router, "admin"), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report struct {
+		Grades []struct {
+			Assignment string `json:"assignment"`
+		} `json:"grades"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Grades, 3)
+	assert.Equal(t, "Quiz 1", report.Grades[0].Assignment, "grades are ordered by due date")
+}
+
+// Test that the attendance report keeps students apart by ID, lists
+// students without records and reports streaks and chronic absence
+func TestAttendanceReport(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	annA, annB, bob := newStudent("Ann", "5"), newStudent("Ann", "6"), newStudent("Bob", "5")
+	annB.Email = "ann.b@example.com"
+	for _, student := range []*models.Student{annA, annB, bob} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "admin", models.RoleStaff)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{annA.ID, bob.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+
+	// Ann A: present, absent, absent, present, absent; Ann B: present
+	for day, status := range []models.AttendanceStatus{"present", "absent", "absent", "present", "absent"} {
+		record := &models.Attendance{StudentID: annA.ID, Date: time.Date(2026, 10, day+1, 0, 0, 0, 0, time.UTC), Status: status, RecordedBy: teacher.ID}
+		require.NoError(t, store.Attendance.Create(ctx, record))
+	}
+	require.NoError(t, store.Attendance.Create(ctx, &models.Attendance{StudentID: annB.ID, Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Status: "present", RecordedBy: teacher.ID}))
+	// Outside the range, on the day after its end
+	require.NoError(t, store.Attendance.Create(ctx, &models.Attendance{StudentID: annB.ID, Date: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), Status: "absent", RecordedBy: teacher.ID}))
+
+	type row struct {
+		StudentID            string `json:"student_id"`
+		Total                int    `json:"total"`
+		LongestAbsenceStreak int    `json:"longest_absence_streak"`
+		CurrentAbsenceStreak int    `json:"current_absence_streak"`
+		ChronicallyAbsent    bool   `json:"chronically_absent"`
+	}
+	var response struct {
+		Report            []row `json:"report"`
+		ChronicallyAbsent int   `json:"chronically_absent"`
+	}
+	report := func(query string) {
+		t.Helper()
+		w := request(router, http.MethodGet, "/api/v1/reports/attendance?startDate=2026-10-01&endDate=2026-10-31"+query, login(t, router, "admin"), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		response.Report = nil
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+
+	report("")
+	require.Len(t, response.Report, 3)
+	// Students with the same name are ordered by ID
+	assert.ElementsMatch(t, []row{
+		{StudentID: annA.ID, Total: 5, LongestAbsenceStreak: 2, CurrentAbsenceStreak: 1, ChronicallyAbsent: true},
+		{StudentID: annB.ID, Total: 1},
+	}, response.Report[:2])
+	assert.Equal(t, row{StudentID: bob.ID}, response.Report[2], "students without records are listed")
+	assert.Equal(t, 1, response.ChronicallyAbsent)
+
+	report("&classId=" + class.ID)
+	require.Len(t, response.Report, 2)
+	assert.Equal(t, bob.ID, response.Report[1].StudentID)
+
+	report("&grade=6")
+	require.Len(t, response.Report, 1)
+	assert.Equal(t, annB.ID, response.Report[0].StudentID)
+
+	report("&studentId=" + bob.ID)
+	require.Len(t, response.Report, 1)
+
+	report("&chronicThreshold=30")
+	assert.Equal(t, 0, response.ChronicallyAbsent)
+
+	w := request(router, http.MethodGet, "/api/v1/reports/attendance?classId=unknown", login(t, router, "admin"), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/reports/attendance?startDate=2026-10-31&endDate=2026-10-01", login(t, router, "admin"), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// Test that the class gradebook weights categories, applies the letter
+// scale and missing assignment policy of the class, and that categories
+// are checked against the class of their assignments
+func TestClassGradebook(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob := newStudent("Ann", "5"), newStudent("Bob", "5")
+	bob.Email = "bob@example.com"
+	for _, student := range []*models.Student{ann, bob} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "admin", models.RoleStaff)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID, bob.ID}}
+	other := &models.Class{Name: "5B", Subject: "Science", Term: "Fall"}
+	require.NoError(t, store.Classes.Create(ctx, class))
+	require.NoError(t, store.Classes.Create(ctx, other))
+	teacherToken, adminToken := login(t, router, "teacher"), login(t, router, "admin")
+
+	createCategory := func(token string, category gin.H) (string, int) {
+		t.Helper()
+		w := request(router, http.MethodPost, "/api/v1/grading-categories", token, category)
+		var response struct {
+			Category models.GradingCategory `json:"category"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Category.ID, w.Code
+	}
+	homework, code := createCategory(teacherToken, gin.H{"class_id": class.ID, "name": "Homework", "weight": 40})
+	require.Equal(t, http.StatusCreated, code)
+	exams, code := createCategory(teacherToken, gin.H{"class_id": class.ID, "name": "Exams", "weight": 60})
+	require.Equal(t, http.StatusCreated, code)
+	_, code = createCategory(teacherToken, gin.H{"subject": "Math", "name": "Projects", "weight": 10})
+	assert.Equal(t, http.StatusForbidden, code, "subject-wide categories need gradebook:configure")
+	_, code = createCategory(teacherToken, gin.H{"class_id": other.ID, "name": "Labs", "weight": 10})
+	assert.Equal(t, http.StatusForbidden, code, "teachers only manage the categories of their classes")
+	labs, code := createCategory(adminToken, gin.H{"class_id": other.ID, "name": "Labs", "weight": 10})
+	require.Equal(t, http.StatusCreated, code)
+
+	w := request(router, http.MethodPost, "/api/v1/assignments", teacherToken, gin.H{
+		"title": "Essay", "subject": "Math", "class_id": class.ID, "category_id": labs, "due_date": "2026-10-01T00:00:00Z",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "categories of other classes are rejected")
+
+	for i, grade := range []struct {
+		category string
+		score    float64
+		max      float64
+		status   models.AssignmentStatus
+	}{
+		{homework, 8, 10, models.AssignmentStatusCompleted},
+		{homework, 0, 10, models.AssignmentStatusMissing},
+		{exams, 45, 50, models.AssignmentStatusLate},
+	} {
+		assignment := &models.Assignment{Title: fmt.Sprintf("Work %d", i+1), Subject: "Math", ClassID: class.ID, CategoryID: grade.category, CreatedBy: teacher.ID, DueDate: time.Date(2026, 10, i+1, 0, 0, 0, 0, time.UTC)}
+		require.NoError(t, store.Grades.CreateAssignment(ctx, assignment))
+		require.NoError(t, store.Grades.CreateGrade(ctx, &models.Grade{StudentID: ann.ID, AssignmentID: assignment.ID, Score: grade.score, MaxScore: grade.max, Status: grade.status, GradedBy: teacher.ID}))
+	}
+
+	// Homework 8/20 = 40% weighs 40%, exams 90% weigh 60%
+	w = request(router, http.MethodGet, "/api/v1/reports/grades?format=csv&classId="+class.ID, teacherToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "Gradebook\nLetter scale,Standard\nMissing assignments,Counted as zero\n\n"+
+		"5A\nStudent,Grade,Exams,Homework,Average,Letter,Missing\nAnn,5,90.0,40.0,70.0,C,1\nBob,5,,,,,0\n", w.Body.String())
+
+	// Excluding missing work and switching to a pass/fail scale
+	w = request(router, http.MethodPost, "/api/v1/grade-scales", teacherToken, gin.H{"name": "Pass/Fail", "steps": []gin.H{{"letter": "P", "min_percent": 85}, {"letter": "F", "min_percent": 0}}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/grade-scales", adminToken, gin.H{"name": "Pass/Fail", "steps": []gin.H{{"letter": "P", "min_percent": 85}, {"letter": "F", "min_percent": 0}}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Scale models.GradeScale `json:"scale"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	w = request(router, http.MethodPut, "/api/v1/classes/"+class.ID+"/gradebook/settings", teacherToken, gin.H{"grade_scale_id": created.Scale.ID, "missing_policy": "exclude"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/reports/grades?classId="+class.ID, teacherToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report struct {
+		Report []struct {
+			StudentID string   `json:"student_id"`
+			Average   *float64 `json:"average"`
+			Letter    *string  `json:"letter"`
+			Missing   int      `json:"missing"`
+		} `json:"report"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Report, 2)
+	require.NotNil(t, report.Report[0].Average)
+	assert.InDelta(t, 0.4*80+0.6*90, *report.Report[0].Average, 1e-9)
+	assert.Equal(t, "P", *report.Report[0].Letter)
+	assert.Equal(t, 1, report.Report[0].Missing)
+	assert.Nil(t, report.Report[1].Average, "students without grades have no average")
+
+	// Deleting a category leaves its assignments uncategorized
+	w = request(router, http.MethodDelete, "/api/v1/grading-categories/"+homework, teacherToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	page, err := store.Grades.ListAssignments(ctx, models.ListOptions{})
+	require.NoError(t, err)
+	for _, assignment := range page.Items {
+		assert.NotEqual(t, homework, assignment.CategoryID)
+	}
+
+	w = request(router, http.MethodGet, "/api/v1/reports/grades?classId="+other.ID, teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/reports/grades?classId=unknown", teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, "unknown classes are not told apart")
+}
+
+// Test academic years and terms: term dates are checked, listings and
+// reports can be limited to a term, and finalizing a term locks its grades
+// and stores report cards parents can download
+func TestTerms(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob := newStudent("Ann", "5"), newStudent("Bob", "5")
+	for _, student := range []*models.Student{ann, bob} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "admin", models.RoleStaff)
+	createUser(t, store, "parent", models.RoleParent, ann.ID)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID, bob.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+	adminToken, teacherToken, parentToken := login(t, router, "admin"), login(t, router, "teacher"), login(t, router, "parent")
+
+	w := request(router, http.MethodPost, "/api/v1/academic-years", adminToken, gin.H{"name": "2026-27", "start_date": "2026-08-01T00:00:00Z", "end_date": "2027-06-30T00:00:00Z"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Year models.AcademicYear `json:"academic_year"`
+		Term models.Term         `json:"term"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	yearID := created.Year.ID
+
+	w = request(router, http.MethodPost, "/api/v1/terms", teacherToken, gin.H{"academic_year_id": yearID, "name": "Fall", "start_date": "2026-08-01T00:00:00Z", "end_date": "2026-12-20T00:00:00Z"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/terms", adminToken, gin.H{"academic_year_id": yearID, "name": "Fall", "start_date": "2026-08-01T00:00:00Z", "end_date": "2026-12-20T00:00:00Z"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	fall := created.Term
+	w = request(router, http.MethodPost, "/api/v1/terms", adminToken, gin.H{"academic_year_id": yearID, "name": "Winter", "start_date": "2026-12-01T00:00:00Z", "end_date": "2027-02-28T00:00:00Z"})
+	assert.Equal(t, http.StatusConflict, w.Code, "terms do not overlap")
+	w = request(router, http.MethodPost, "/api/v1/terms", adminToken, gin.H{"academic_year_id": yearID, "name": "Summer", "start_date": "2027-06-01T00:00:00Z", "end_date": "2027-07-31T00:00:00Z"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "terms fall within their year")
+
+	w = request(router, http.MethodGet, "/api/v1/terms/current?date=2026-10-15", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, fall.ID, created.Term.ID)
+	w = request(router, http.MethodGet, "/api/v1/terms/current?date=2027-01-15", parentToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// One assignment and attendance record in the term and one after it
+	var grades []models.Grade
+	for _, due := range []time.Time{time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)} {
+		assignment := &models.Assignment{Title: "Quiz " + due.Format("Jan"), Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: due}
+		require.NoError(t, store.Grades.CreateAssignment(ctx, assignment))
+		grade := models.Grade{StudentID: ann.ID, AssignmentID: assignment.ID, Score: 8, MaxScore: 10, Status: models.AssignmentStatusCompleted, GradedBy: teacher.ID}
+		require.NoError(t, store.Grades.CreateGrade(ctx, &grade))
+		grades = append(grades, grade)
+		require.NoError(t, store.Attendance.Create(ctx, &models.Attendance{StudentID: ann.ID, Date: due, Status: models.AttendanceStatusPresent, RecordedBy: teacher.ID}))
+	}
+
+	var listed struct {
+		Count int `json:"count"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/grades/student/"+ann.ID+"?termId="+fall.ID, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, 1, listed.Count)
+	w = request(router, http.MethodGet, "/api/v1/attendance/student/"+ann.ID+"?termId="+fall.ID, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, 1, listed.Count)
+	w = request(router, http.MethodGet, "/api/v1/grades/student/"+ann.ID+"?termId=unknown", adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/reports/attendance?termId="+fall.ID+"&startDate=2026-09-01", adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/reports/grades?format=csv&termId="+fall.ID, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "Ann,5,1,80.0,8,8,0\n")
+
+	w = request(router, http.MethodPost, "/api/v1/terms/"+fall.ID+"/finalize", teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/terms/"+fall.ID+"/finalize", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodPost, "/api/v1/terms/"+fall.ID+"/finalize", adminToken, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Grades of the finalized term are locked, later ones are not
+	for i, want := range []int{http.StatusConflict, http.StatusOK} {
+		grade := grades[i]
+		w = request(router, http.MethodPut, "/api/v1/grades/"+grade.ID, teacherToken, gin.H{
+			"student_id": grade.StudentID, "assignment_id": grade.AssignmentID, "score": 10, "max_score": 10, "status": "completed", "graded_by": teacher.ID,
+		})
+		assert.Equal(t, want, w.Code, w.Body.String())
+	}
+	// Its assignments cannot be deleted or moved out, nor others moved in
+	for i, due := range []string{"2027-01-20T00:00:00Z", "2026-10-20T00:00:00Z"} {
+		w = request(router, http.MethodPut, "/api/v1/assignments/"+grades[i].AssignmentID, teacherToken, gin.H{
+			"title": "Moved", "subject": "Math", "due_date": due, "class_id": class.ID, "max_score": 10, "created_by": teacher.ID,
+		})
+		assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	}
+	w = request(router, http.MethodPost, "/api/v1/assignments", teacherToken, gin.H{
+		"title": "Late quiz", "subject": "Math", "due_date": "2026-11-02T00:00:00Z", "class_id": class.ID, "max_score": 10, "created_by": teacher.ID,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code, "no assignments are added to it")
+	w = request(router, http.MethodDelete, "/api/v1/assignments/"+grades[0].AssignmentID, teacherToken, nil)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	_, err := store.Grades.GetGradeByID(ctx, grades[0].ID)
+	require.NoError(t, err)
+	w = request(router, http.MethodPut, "/api/v1/terms/"+fall.ID, adminToken, gin.H{"name": "Autumn", "start_date": "2026-08-01T00:00:00Z", "end_date": "2026-12-20T00:00:00Z"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = request(router, http.MethodDelete, "/api/v1/academic-years/"+yearID, adminToken, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var cards struct {
+		ReportCards []models.ReportCard `json:"report_cards"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/students/"+ann.ID+"/report-cards", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cards))
+	require.Len(t, cards.ReportCards, 1)
+	card := cards.ReportCards[0]
+	assert.Equal(t, fall.ID, card.TermID)
+	w = request(router, http.MethodGet, "/api/v1/students/"+bob.ID+"/report-cards", parentToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodGet, "/api/v1/students/"+bob.ID+"/report-cards/"+card.ID, adminToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, "cards are looked up under their student")
+
+	// The card keeps the score given before the term was finalized
+	w = request(router, http.MethodGet, "/api/v1/students/"+ann.ID+"/report-cards/"+card.ID, parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report struct {
+		GradeStats struct {
+			Total   int     `json:"total_assignments"`
+			Average float64 `json:"average_grade"`
+		} `json:"grade_stats"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.GradeStats.Total)
+	assert.Equal(t, 80.0, report.GradeStats.Average)
+
+	w = request(router, http.MethodGet, "/api/v1/students/"+ann.ID+"/report-cards/"+card.ID+"?format=csv", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "attachment; filename=\"report-card-"+ann.ID+"-"+fall.ID+".csv\"", w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Body.String(), "Term,Fall (2026-08-01 to 2026-12-20)\n")
+	assert.Contains(t, w.Body.String(), "Quiz Oct,Math,2026-10-01,8,10,80.0,completed,")
+	assert.NotContains(t, w.Body.String(), "Quiz Jan")
+}
+
+// Test the year-end rollover and that reports on a past period show the
+// grade level students had then
+func TestPromotions(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob, cid, dee, eve := newStudent("Ann", "5"), newStudent("Bob", "5"), newStudent("Cid", "12"), newStudent("Dee", "Pre-K"), newStudent("Eve", "7")
+	for _, student := range []*models.Student{ann, bob, cid, dee, eve} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	gone := newStudent("Gus", "3")
+	gone.IsActive = false
+	require.NoError(t, store.Students.Create(ctx, gone))
+	createUser(t, store, "admin", models.RoleStaff)
+	createUser(t, store, "teacher", models.RoleFaculty)
+	adminToken, teacherToken := login(t, router, "admin"), login(t, router, "teacher")
+
+	plan := gin.H{"effective_date": "2026-08-01T00:00:00Z", "hold_student_ids": []string{bob.ID}, "graduate_student_ids": []string{eve.ID}}
+	w := request(router, http.MethodPost, "/api/v1/promotions/preview", teacherToken, plan)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/promotions/preview", adminToken, gin.H{"hold_student_ids": []string{bob.ID}, "graduate_student_ids": []string{bob.ID}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/promotions/preview", adminToken, gin.H{"hold_student_ids": []string{gone.ID}})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "inactive students cannot be held back")
+
+	var preview struct {
+		Promotions []models.GradeChange           `json:"promotions"`
+		Counts     map[models.PromotionAction]int `json:"counts"`
+		Count      int                            `json:"count"`
+	}
+	w = request(router, http.MethodPost, "/api/v1/promotions/preview", adminToken, plan)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &preview))
+	require.Equal(t, 5, preview.Count)
+	actions := make(map[string]models.PromotionAction)
+	for _, change := range preview.Promotions {
+		actions[change.StudentName+" "+change.FromGrade+">"+change.ToGrade] = change.Action
+	}
+	assert.Equal(t, map[string]models.PromotionAction{
+		"Ann 5>6":         models.PromotionPromote,
+		"Bob 5>5":         models.PromotionHold,
+		"Cid 12>12":       models.PromotionGraduate,
+		"Dee Pre-K>Pre-K": models.PromotionSkip,
+		"Eve 7>7":         models.PromotionGraduate,
+	}, actions)
+	assert.Equal(t, 2, preview.Counts[models.PromotionGraduate])
+
+	// Previewing changes nothing
+	student, err := store.Students.GetByID(ctx, ann.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "5", student.Grade)
+
+	w = request(router, http.MethodPost, "/api/v1/promotions", adminToken, plan)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for id, want := range map[string]struct {
+		grade  string
+		active bool
+	}{ann.ID: {"6", true}, bob.ID: {"5", true}, cid.ID: {"12", false}, dee.ID: {"Pre-K", true}, eve.ID: {"7", false}} {
+		student, err := store.Students.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want.grade, student.Grade, student.Name)
+		assert.Equal(t, want.active, student.IsActive, student.Name)
+	}
+	w = request(router, http.MethodPost, "/api/v1/promotions", adminToken, gin.H{"effective_date": "2026-08-01T00:00:00Z"})
+	assert.Equal(t, http.StatusConflict, w.Code, "students are promoted once per date")
+
+	var history struct {
+		Grade        string               `json:"grade"`
+		GradeHistory []models.GradeChange `json:"grade_history"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/students/"+ann.ID+"/grade-history", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Equal(t, "6", history.Grade)
+	require.Len(t, history.GradeHistory, 1)
+	assert.Equal(t, "5", history.GradeHistory[0].FromGrade)
+	assert.Equal(t, models.PromotionPromote, history.GradeHistory[0].Action)
+
+	// Reports before the rollover show the old grade level and filter by it
+	w = request(router, http.MethodGet, "/api/v1/reports/attendance?format=csv&grade=5&startDate=2026-06-01&endDate=2026-06-30", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "\nAnn,5,")
+	w = request(router, http.MethodGet, "/api/v1/reports/attendance?format=csv&startDate=2026-09-01&endDate=2026-09-30", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "\nAnn,6,")
+}
+
+// Test handing in work with files and that the time it was received sets
+// the status of the grade
+func TestSubmissions(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob := newStudent("Ann", "5"), newStudent("Bob", "5")
+	for _, student := range []*models.Student{ann, bob} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "parent", models.RoleParent, ann.ID)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID, bob.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+	teacherToken, parentToken := login(t, router, "teacher"), login(t, router, "parent")
+
+	open := &models.Assignment{Title: "Essay", Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Now().Add(48 * time.Hour)}
+	overdue := &models.Assignment{Title: "Quiz", Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Now().Add(-48 * time.Hour)}
+	for _, assignment := range []*models.Assignment{open, overdue} {
+		require.NoError(t, store.Grades.CreateAssignment(ctx, assignment))
+	}
+	grade := models.Grade{StudentID: ann.ID, AssignmentID: overdue.ID, Score: 7, MaxScore: 10, Status: models.AssignmentStatusCompleted, GradedBy: teacher.ID}
+	require.NoError(t, store.Grades.CreateGrade(ctx, &grade))
+
+	type upload struct{ name, content string }
+	submit := func(assignmentID, token string, fields map[string]string, files ...upload) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		for name, value := range fields {
+			require.NoError(t, form.WriteField(name, value))
+		}
+		for _, f := range files {
+			file, err := form.CreateFormFile("files", f.name)
+			require.NoError(t, err)
+			_, _ = file.Write([]byte(f.content))
+		}
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/assignments/"+assignmentID+"/submissions", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	pdf := upload{"essay.pdf", "%PDF-1.4\n1 0 obj\n<<>>\nendobj\n"}
+
+	w := submit(open.ID, teacherToken, map[string]string{"student_id": ann.ID, "text": "Done"})
+	assert.Equal(t, http.StatusForbidden, w.Code, "faculty cannot hand in work")
+	w = submit(open.ID, parentToken, map[string]string{"student_id": bob.ID, "text": "Done"})
+	assert.Equal(t, http.StatusForbidden, w.Code, "parents hand in work for their children only")
+	w = submit(open.ID, parentToken, map[string]string{"student_id": ann.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "a submission is not empty")
+	w = submit(open.ID, parentToken, map[string]string{"student_id": ann.ID}, upload{"essay.pdf", "<html><script></script></html>"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, "the type is sniffed from the content")
+	w = submit(open.ID, parentToken, map[string]string{"student_id": ann.ID}, upload{"essay.txt", strings.Repeat("a", models.MaxSubmissionFileSize+1)})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	var created struct {
+		Submission models.Submission `json:"submission"`
+	}
+	w = submit(open.ID, parentToken, map[string]string{"student_id": ann.ID, "text": "My essay"}, pdf)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	onTime := created.Submission
+	assert.Equal(t, models.AssignmentStatusCompleted, onTime.Status)
+	require.Len(t, onTime.Files, 1)
+	assert.Equal(t, "application/pdf", onTime.Files[0].ContentType)
+
+	// A grade given later takes the status of the submission
+	w = request(router, http.MethodPost, "/api/v1/grades", teacherToken, gin.H{
+		"student_id": ann.ID, "assignment_id": open.ID, "score": 9, "max_score": 10, "status": "missing", "graded_by": teacher.ID,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"status":"completed"`)
+
+	// Work handed in after the due date makes the existing grade late
+	w = submit(overdue.ID, parentToken, map[string]string{"student_id": ann.ID, "text": "Sorry"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, models.AssignmentStatusLate, created.Submission.Status)
+	updated, err := store.Grades.GetGradeByID(ctx, grade.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.AssignmentStatusLate, updated.Status)
+	entries, err := store.Audit.List(ctx, models.ListOptions{Conditions: []models.Condition{{Field: "entity_id", Op: models.OpEq, Value: grade.ID}}})
+	require.NoError(t, err)
+	assert.Len(t, entries.Items, 2, "the status change is audited")
+
+	w = request(router, http.MethodGet, "/api/v1/submissions/"+onTime.ID+"/files/"+onTime.Files[0].ID, parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, pdf.content, w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=essay.pdf`, w.Header().Get("Content-Disposition"))
+	w = request(router, http.MethodGet, "/api/v1/submissions/"+onTime.ID+"/files/unknown", parentToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Listings of an assignment only hold the students in the caller's scope
+	require.NoError(t, store.Submissions.Create(ctx, &models.Submission{AssignmentID: open.ID, StudentID: bob.ID, Text: "Mine", SubmittedBy: teacher.ID}))
+	var listed struct {
+		Count int `json:"count"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/assignments/"+open.ID+"/submissions", teacherToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, 2, listed.Count)
+	w = request(router, http.MethodGet, "/api/v1/assignments/"+open.ID+"/submissions", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, 1, listed.Count)
+	w = request(router, http.MethodGet, "/api/v1/students/"+ann.ID+"/submissions", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, 2, listed.Count)
+	w = request(router, http.MethodGet, "/api/v1/students/"+bob.ID+"/submissions", parentToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Deleting the assignment removes the stored files
+	w = request(router, http.MethodDelete, "/api/v1/assignments/"+open.ID, teacherToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodGet, "/api/v1/submissions/"+onTime.ID, parentToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// Test that the missing assignments job marks students who handed nothing
+// in, only once, and reports its last run
+func TestMarkMissingJob(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob, cara, dan := newStudent("Ann", "5"), newStudent("Bob", "5"), newStudent("Cara", "5"), newStudent("Dan", "5")
+	dan.IsActive = false
+	for _, student := range []*models.Student{ann, bob, cara, dan} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	createUser(t, store, "staff", models.RoleStaff)
+	createUser(t, store, "parent", models.RoleParent, cara.ID)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID, bob.ID, cara.ID, dan.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+	teacherToken, staffToken := login(t, router, "teacher"), login(t, router, "staff")
+
+	overdue := &models.Assignment{Title: "Quiz", Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Now().Add(-time.Hour), MaxScore: 20}
+	upcoming := &models.Assignment{Title: "Essay", Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Now().Add(time.Hour)}
+	old := &models.Assignment{Title: "Old", Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Now().AddDate(0, -3, 0)}
+	for _, assignment := range []*models.Assignment{overdue, upcoming, old} {
+		require.NoError(t, store.Grades.CreateAssignment(ctx, assignment))
+	}
+	assert.Equal(t, float64(models.DefaultMaxScore), upcoming.MaxScore)
+	require.NoError(t, store.Grades.CreateGrade(ctx, &models.Grade{StudentID: ann.ID, AssignmentID: overdue.ID, Score: 15, MaxScore: 20, Status: models.AssignmentStatusCompleted, GradedBy: teacher.ID}))
+	require.NoError(t, store.Submissions.Create(ctx, &models.Submission{AssignmentID: overdue.ID, StudentID: bob.ID, Text: "Late", SubmittedBy: teacher.ID}))
+
+	w := request(router, http.MethodGet, "/api/v1/admin/jobs", teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/admin/jobs/unknown/run", staffToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Only Cara is marked: Ann is graded, Bob handed work in and Dan is inactive
+	for _, want := range []string{"1 grades marked missing", "0 grades marked missing"} {
+		w = request(router, http.MethodPost, "/api/v1/admin/jobs/"+scheduler.MarkMissingJob+"/run", staffToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), want)
+	}
+	grades, err := store.Grades.ListGradesByStudentID(ctx, cara.ID)
+	require.NoError(t, err)
+	require.Len(t, grades, 1)
+	assert.Equal(t, overdue.ID, grades[0].AssignmentID)
+	assert.Equal(t, models.AssignmentStatusMissing, grades[0].Status)
+	assert.Equal(t, 0.0, grades[0].Score)
+	assert.Equal(t, 20.0, grades[0].MaxScore)
+	assert.Equal(t, teacher.ID, grades[0].GradedBy)
+
+	// Cara's parent is told once, however often the job runs
+	var inbox struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/notifications", login(t, router, "parent"), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inbox))
+	require.Len(t, inbox.Notifications, 1)
+	assert.Equal(t, models.NotificationMissingAssignment, inbox.Notifications[0].Kind)
+	assert.Equal(t, grades[0].ID, inbox.Notifications[0].EntityID)
+
+	var status struct {
+		Jobs []scheduler.JobStatus `json:"jobs"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/admin/jobs", staffToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Len(t, status.Jobs, 1)
+	require.NotNil(t, status.Jobs[0].LastRun)
+	assert.Equal(t, models.JobSucceeded, status.Jobs[0].LastRun.Status)
+	assert.Equal(t, "0 grades marked missing", status.Jobs[0].LastRun.Result)
+}
+
+// Test that parents are notified once per absence, low grade and comment by
+// someone else, and manage their inbox and delivery preferences
+func TestNotifications(t *testing.T) {
+	router, store := newTestServer(t)
+	ctx := context.Background()
+	ann, bob := newStudent("Ann", "5"), newStudent("Bob", "5")
+	for _, student := range []*models.Student{ann, bob} {
+		require.NoError(t, store.Students.Create(ctx, student))
+	}
+	teacher := createUser(t, store, "teacher", models.RoleFaculty)
+	parent := createUser(t, store, "parent", models.RoleParent, ann.ID)
+	createUser(t, store, "other", models.RoleParent, bob.ID)
+	class := &models.Class{Name: "5A", Subject: "Math", Term: "Fall", TeacherIDs: []string{teacher.ID}, StudentIDs: []string{ann.ID, bob.ID}}
+	require.NoError(t, store.Classes.Create(ctx, class))
+	teacherToken, parentToken, otherToken := login(t, router, "teacher"), login(t, router, "parent"), login(t, router, "other")
+
+	w := request(router, http.MethodGet, "/api/v1/notifications", teacherToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Saving an absence twice notifies once; being present notifies nobody
+	absence := gin.H{"student_id": ann.ID, "date": time.Now().Format(time.RFC3339), "status": models.AttendanceStatusAbsent, "recorded_by": teacher.ID}
+	w = request(router, http.MethodPost, "/api/v1/attendance", teacherToken, absence)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Attendance models.Attendance `json:"attendance"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	w = request(router, http.MethodPut, "/api/v1/attendance/"+created.Attendance.ID, teacherToken, absence)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodPost, "/api/v1/attendance", teacherToken, gin.H{"student_id": ann.ID, "date": time.Now().AddDate(0, 0, -1).Format(time.RFC3339), "status": models.AttendanceStatusPresent, "recorded_by": teacher.ID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Statuses are checked rather than saved as free text nobody is told about
+	w = request(router, http.MethodPost, "/api/v1/attendance", teacherToken, gin.H{"student_id": ann.ID, "date": time.Now().AddDate(0, 0, -2).Format(time.RFC3339), "status": "Absent", "recorded_by": teacher.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = request(router, http.MethodPut, "/api/v1/attendance/"+created.Attendance.ID, teacherToken, gin.H{"student_id": ann.ID, "date": time.Now().Format(time.RFC3339), "status": "sick", "recorded_by": teacher.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// Low grades are reported, good ones are not
+	assignment := &models.Assignment{Title: "Quiz", Subject: "Math", ClassID: class.ID, CreatedBy: teacher.ID, DueDate: time.Now().Add(time.Hour)}
+	require.NoError(t, store.Grades.CreateAssignment(ctx, assignment))
+	for student, score := range map[string]float64{ann.ID: 40, bob.ID: 90} {
+		w = request(router, http.MethodPost, "/api/v1/grades", teacherToken, gin.H{"student_id": student, "assignment_id": assignment.ID, "score": score, "max_score": 100, "status": models.AssignmentStatusCompleted, "graded_by": teacher.ID})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// The author of a comment is not told about it
+	post := &models.ForumPost{Title: "Field trip", Content: "Permission slips", AuthorID: teacher.ID, StudentID: ann.ID}
+	require.NoError(t, store.Forum.CreatePost(ctx, post))
+	w = request(router, http.MethodPost, "/api/v1/forum/comments", parentToken, gin.H{"post_id": post.ID, "content": "Signed", "author_id": parent.ID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = request(router, http.MethodPost, "/api/v1/forum/comments", teacherToken, gin.H{"post_id": post.ID, "content": "Thanks!", "author_id": teacher.ID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var inbox struct {
+		Notifications []models.Notification `json:"notifications"`
+		Total         int                   `json:"total"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/notifications", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inbox))
+	require.Len(t, inbox.Notifications, 3)
+	kinds := map[models.NotificationKind]bool{}
+	for _, n := range inbox.Notifications {
+		kinds[n.Kind] = true
+		assert.Equal(t, ann.ID, n.StudentID)
+		assert.Nil(t, n.ReadAt)
+	}
+	assert.Equal(t, map[models.NotificationKind]bool{models.NotificationAbsence: true, models.NotificationLowGrade: true, models.NotificationForumComment: true}, kinds)
+
+	// Parents only see and read their own notifications
+	w = request(router, http.MethodGet, "/api/v1/notifications", otherToken, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inbox))
+	assert.Empty(t, inbox.Notifications)
+	w = request(router, http.MethodGet, "/api/v1/notifications?kind=absence", parentToken, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inbox))
+	require.Len(t, inbox.Notifications, 1)
+	w = request(router, http.MethodPost, "/api/v1/notifications/"+inbox.Notifications[0].ID+"/read", otherToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = request(router, http.MethodPost, "/api/v1/notifications/"+inbox.Notifications[0].ID+"/read", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = request(router, http.MethodGet, "/api/v1/notifications?read=false", parentToken, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inbox))
+	assert.Len(t, inbox.Notifications, 2)
+	w = request(router, http.MethodPost, "/api/v1/notifications/read-all", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"count":2`)
+	w = request(router, http.MethodGet, "/api/v1/notifications?read=false", parentToken, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inbox))
+	assert.Empty(t, inbox.Notifications)
+
+	// Preferences default to every kind by email
+	var prefs struct {
+		Preferences models.NotificationPreferences `json:"preferences"`
+	}
+	w = request(router, http.MethodGet, "/api/v1/notifications/preferences", parentToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+	assert.ElementsMatch(t, models.NotificationKinds, prefs.Preferences.Email)
+
+	for _, invalid := range []gin.H{
+		{"email": []string{"gossip"}},
+		{"webhook": []string{"absence"}},
+		{"webhook": []string{"absence"}, "webhook_url": "http://example.com/hook"},
+	} {
+		w = request(router, http.MethodPut, "/api/v1/notifications/preferences", parentToken, invalid)
+		assert.Equal(t, http.StatusBadRequest, w.Code, invalid)
+	}
+	w = request(router, http.MethodPut, "/api/v1/notifications/preferences", parentToken, gin.H{"email": []string{"absence"}, "webhook": []string{"low_grade"}, "webhook_url": "https://example.com/hook"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = request(router, http.MethodGet, "/api/v1/notifications/preferences", parentToken, nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+	assert.Equal(t, []models.NotificationKind{models.NotificationAbsence}, prefs.Preferences.Email)
+	assert.Equal(t, "https://example.com/hook", prefs.Preferences.WebhookURL)
+	assert.Equal(t, parent.ID, prefs.Preferences.UserID)
+}
@@ -1,38 +1,65 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"log"
+	"time"
 
+	"example.com/sre-bootcamp-rest-api/middleware"
 	"example.com/sre-bootcamp-rest-api/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func getStudents(c *gin.Context) {
+func (h *Handler) getStudents(c *gin.Context) {
 	log.Println("Fetching all students...")
-	students, err := models.GetAllStudents()
+	scope, err := middleware.GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch students. Try again later."})
+		return
+	}
+
+	opts, ok := listOptions(c, models.StudentListSpec)
+	if !ok {
+		return
+	}
+
+	// Inactive students are hidden unless requested
+	if c.Query("is_active") == "" && c.Query("include_inactive") != "true" {
+		opts.Conditions = append(opts.Conditions, models.Condition{Field: "is_active", Op: models.OpEq, Value: true})
+	}
+
+	// Parents only see the students linked to them
+	if !scope.Unrestricted() {
+		opts.IDs = append([]string{}, scope.StudentIDs()...)
+	}
+
+	page, err := h.store.Students.List(c.Request.Context(), opts)
 	if err != nil {
 		log.Println("Error fetching students:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch students. Try again later."})
 		return
 	}
 
-	if students == nil {
-		students = []models.Student{} // Return empty array instead of null
+	if page.Items == nil {
+		page.Items = []models.Student{} // Return empty array instead of null
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"students": students,
-		"count":   len(students),
+		"students":    page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
 	})
 }
 
-func getStudent(c *gin.Context) {
+func (h *Handler) getStudent(c *gin.Context) {
 	log.Printf("Fetching student with ID: %s...", c.Param("id"))
 	id := c.Param("id")
 	
-	student, err := models.GetStudentByID(id)
+	student, err := h.store.Students.GetByID(c.Request.Context(), id)
 	if err != nil {
 		log.Println("Error fetching student:", err)
 		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
@@ -44,9 +71,10 @@ func getStudent(c *gin.Context) {
 	})
 }
 
-func createStudent(c *gin.Context) {
+func (h *Handler) createStudent(c *gin.Context) {
 	log.Println("Creating a new student...")
-	var student models.Student
+	// New students are active unless the request says otherwise
+	student := models.Student{IsActive: true}
 	err := c.ShouldBindJSON(&student)
 
 	if err != nil {
@@ -58,10 +86,18 @@ func createStudent(c *gin.Context) {
 		return
 	}
 
+	if err := student.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid student data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	student.ID = uuid.New().String()
 	log.Printf("Generated new student ID: %s", student.ID)
 
-	if err := student.Save(); err != nil {
+	if err := h.store.Students.Create(c.Request.Context(), &student); err != nil {
 		log.Println("Error saving student:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Could not create student. Try again later.",
@@ -76,17 +112,18 @@ func createStudent(c *gin.Context) {
 	})
 }
 
-func updateStudent(c *gin.Context) {
+func (h *Handler) updateStudent(c *gin.Context) {
 	log.Printf("Updating student with ID: %s...", c.Param("id"))
 	id := c.Param("id")
 	
-	existingStudent, err := models.GetStudentByID(id)
+	existingStudent, err := h.store.Students.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
 		return
 	}
 
-	var student models.Student
+	// Fields missing from the request keep their current values
+	student := *existingStudent
 	err = c.ShouldBindJSON(&student)
 	if err != nil {
 		log.Println("Error binding JSON:", err)
@@ -97,8 +134,16 @@ func updateStudent(c *gin.Context) {
 		return
 	}
 
+	if err := student.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid student data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	student.ID = existingStudent.ID
-	err = student.Update()
+	err = h.store.Students.Update(c.Request.Context(), &student)
 	if err != nil {
 		log.Println("Error updating student:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -114,27 +159,141 @@ func updateStudent(c *gin.Context) {
 	})
 }
 
-func deleteStudent(c *gin.Context) {
-	log.Printf("Deleting student with ID: %s...", c.Param("id"))
+// deleteStudent archives a student. The student's records are kept until it
+// is restored or purged.
+func (h *Handler) deleteStudent(c *gin.Context) {
+	log.Printf("Archiving student with ID: %s...", c.Param("id"))
 	id := c.Param("id")
 
-	student, err := models.GetStudentByID(id)
+	student, err := h.store.Students.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
 		return
 	}
 
-	err = student.Delete()
+	user := middleware.GetUserFromContext(c)
+	err = h.store.Students.Archive(c.Request.Context(), student.ID, user.ID)
 	if err != nil {
-		log.Println("Error deleting student:", err)
+		log.Println("Error archiving student:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Could not delete student. Try again later.",
+			"message": "Could not archive student. Try again later.",
 			"error":   err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Student deleted successfully!",
+		"message": "Student archived successfully!",
+	})
+}
+
+// getArchivedStudents lists archived students
+func (h *Handler) getArchivedStudents(c *gin.Context) {
+	opts, ok := listOptions(c, models.StudentListSpec)
+	if !ok {
+		return
+	}
+
+	page, err := h.store.Students.ListArchived(c.Request.Context(), opts)
+	if err != nil {
+		log.Println("Error fetching archived students:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch archived students. Try again later."})
+		return
+	}
+
+	if page.Items == nil {
+		page.Items = []models.Student{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"students":    page.Items,
+		"count":       len(page.Items),
+		"next_cursor": page.NextCursor,
+		"total":       page.Total,
+	})
+}
+
+// restoreStudent brings an archived student back
+func (h *Handler) restoreStudent(c *gin.Context) {
+	log.Printf("Restoring student with ID: %s...", c.Param("id"))
+	id := c.Param("id")
+
+	err := h.store.Students.Restore(c.Request.Context(), id)
+	if errors.Is(err, models.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Archived student not found."})
+		return
+	}
+	if err != nil {
+		log.Println("Error restoring student:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not restore student. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	student, err := h.store.Students.GetByID(c.Request.Context(), id)
+	if err != nil {
+		log.Println("Error fetching restored student:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Student restored, but could not be fetched."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Student restored successfully!",
+		"student": student,
+	})
+}
+
+// purgeStudent permanently deletes an archived student and all of its
+// records. The request body must repeat the student ID to confirm.
+func (h *Handler) purgeStudent(c *gin.Context) {
+	log.Printf("Purging student with ID: %s...", c.Param("id"))
+	id := c.Param("id")
+
+	var request struct {
+		Confirm string `json:"confirm" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Confirm != id {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Confirm the purge by sending the student ID as \"confirm\"."})
+		return
+	}
+
+	// Submissions go with the student; their files are removed after it
+	submissions, err := h.store.Submissions.ListByStudentID(c.Request.Context(), id)
+	if err != nil {
+		log.Println("Error fetching submissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not purge student. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	err = h.store.Students.Purge(c.Request.Context(), id, time.Now().Add(-h.retention))
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
+		return
+	case errors.Is(err, models.ErrPurgeNotAllowed):
+		c.JSON(http.StatusConflict, gin.H{
+			"message":   "Student cannot be purged yet.",
+			"error":     err.Error(),
+			"retention": h.retention.String(),
+		})
+		return
+	case err != nil:
+		log.Println("Error purging student:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not purge student. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	h.deleteSubmissionFiles(c.Request.Context(), submissions...)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Student purged permanently.",
 	})
 }
@@ -0,0 +1,333 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+
+	"example.com/sre-bootcamp-rest-api/authz"
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxSubmissionRequestSize bounds the body of a submission request: every
+// file at its largest plus room for the text and the multipart framing
+const maxSubmissionRequestSize = models.MaxSubmissionFiles*models.MaxSubmissionFileSize + 1<<20
+
+// createSubmission hands in work for an assignment on behalf of a student.
+// The multipart form carries the student_id, an optional text and up to
+// models.MaxSubmissionFiles files in the files field.
+func (h *Handler) createSubmission(c *gin.Context) {
+	ctx := c.Request.Context()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSubmissionRequestSize)
+
+	assignment, err := h.store.Grades.GetAssignmentByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found."})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "The submission is too large."})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	studentID := c.PostForm("student_id")
+	if studentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A student_id is required."})
+		return
+	}
+	if !middleware.CheckStudentAccess(c, studentID) {
+		return
+	}
+	if _, err := h.store.Students.GetByID(ctx, studentID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Student not found."})
+		return
+	}
+	if assignment.ClassID != "" {
+		enrolled, err := h.store.Classes.IsStudentEnrolled(ctx, assignment.ClassID, studentID)
+		if err != nil {
+			log.Println("Error checking enrollment:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not create submission. Try again later.",
+				"error":   err.Error(),
+			})
+			return
+		}
+		if !enrolled {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "The student is not on the roster of the assignment's class."})
+			return
+		}
+	}
+
+	user := middleware.GetUserFromContext(c)
+	submission := models.Submission{
+		AssignmentID: assignment.ID,
+		StudentID:    studentID,
+		Text:         c.PostForm("text"),
+		SubmittedBy:  user.ID,
+	}
+	uploads := form.File["files"]
+	for _, header := range uploads {
+		submission.Files = append(submission.Files, models.SubmissionFile{Name: filepath.Base(header.Filename), Size: header.Size})
+	}
+	if err := submission.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid submission.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Check every file before storing any
+	for i, header := range uploads {
+		file := &submission.Files[i]
+		head, err := readHead(header)
+		if err != nil {
+			log.Println("Error reading uploaded file:", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Could not read the uploaded files.",
+				"error":   err.Error(),
+			})
+			return
+		}
+		file.ContentType, err = models.SubmissionFileType(file.Name, header.Size, head)
+		switch {
+		case errors.Is(err, models.ErrSubmissionFileTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "The file " + file.Name + " is too large.", "error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "The file " + file.Name + " is not of an accepted type.", "error": err.Error()})
+			return
+		}
+	}
+
+	for i, header := range uploads {
+		file := &submission.Files[i]
+		file.ID = uuid.New().String()
+		file.Key = "submissions/" + file.ID
+		if err := h.storeUpload(ctx, file, header); err != nil {
+			log.Println("Error storing uploaded file:", err)
+			h.deleteSubmissionFiles(ctx, submission)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"message": "Could not store the uploaded files. Try again later.",
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	if err := h.store.Submissions.Create(ctx, &submission); err != nil {
+		h.deleteSubmissionFiles(ctx, submission)
+		if errors.Is(err, models.ErrTermFinalized) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The assignment is due in a finalized term; work can no longer be submitted."})
+			return
+		}
+		log.Println("Error saving submission:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not create submission. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Submission created successfully!",
+		"submission": submission,
+	})
+}
+
+// readHead returns the first bytes of an uploaded file, enough to sniff
+// its content type
+func readHead(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+// storeUpload copies an uploaded file to blob storage. The size the client
+// declared is checked against what was actually written.
+func (h *Handler) storeUpload(ctx context.Context, file *models.SubmissionFile, header *multipart.FileHeader) error {
+	content, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = content.Close() }()
+
+	size, err := h.blobs.Put(ctx, file.Key, io.LimitReader(content, models.MaxSubmissionFileSize+1))
+	if err != nil {
+		return err
+	}
+	if size > models.MaxSubmissionFileSize {
+		return models.ErrSubmissionFileTooLarge
+	}
+	file.Size = size
+	return nil
+}
+
+// deleteSubmissionFiles removes the stored files of submissions. Failures
+// are only logged: the records are already gone or were never written.
+func (h *Handler) deleteSubmissionFiles(ctx context.Context, submissions ...models.Submission) {
+	for _, submission := range submissions {
+		for _, file := range submission.Files {
+			if file.Key == "" {
+				continue
+			}
+			if err := h.blobs.Delete(ctx, file.Key); err != nil {
+				log.Println("Error deleting submission file:", err)
+			}
+		}
+	}
+}
+
+// getSubmissionsByAssignmentID lists the submissions for an assignment the
+// caller may see, most recent first
+func (h *Handler) getSubmissionsByAssignmentID(c *gin.Context) {
+	ctx := c.Request.Context()
+	assignment, err := h.store.Grades.GetAssignmentByID(ctx, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Assignment not found."})
+		return
+	}
+
+	scope, err := middleware.GetScopeFromContext(c)
+	if err != nil {
+		log.Println("Error loading access scope:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch submissions. Try again later."})
+		return
+	}
+
+	// Only the students in scope are read; the filter is a backstop
+	submissions, err := h.store.Submissions.ListByAssignmentID(ctx, assignment.ID, scope.StudentIDs())
+	if err != nil {
+		log.Println("Error fetching submissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch submissions. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	submissions = authz.Filter(scope, submissions, func(s models.Submission) string { return s.StudentID })
+
+	if submissions == nil {
+		submissions = []models.Submission{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submissions": submissions,
+		"count":       len(submissions),
+	})
+}
+
+// getSubmissionsByStudentID lists the submissions of a student, most recent
+// first
+func (h *Handler) getSubmissionsByStudentID(c *gin.Context) {
+	submissions, err := h.store.Submissions.ListByStudentID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Println("Error fetching submissions:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch submissions. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if submissions == nil {
+		submissions = []models.Submission{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submissions": submissions,
+		"count":       len(submissions),
+	})
+}
+
+// getSubmissionByID retrieves a submission with the list of its files
+func (h *Handler) getSubmissionByID(c *gin.Context) {
+	submission, err := h.store.Submissions.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Println("Error fetching submission:", err)
+		c.JSON(http.StatusNotFound, gin.H{"message": "Submission not found."})
+		return
+	}
+
+	if !middleware.CheckStudentAccess(c, submission.StudentID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submission": submission,
+	})
+}
+
+// downloadSubmissionFile sends a file of a submission as an attachment
+func (h *Handler) downloadSubmissionFile(c *gin.Context) {
+	ctx := c.Request.Context()
+	submission, err := h.store.Submissions.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		log.Println("Error fetching submission:", err)
+		c.JSON(http.StatusNotFound, gin.H{"message": "Submission not found."})
+		return
+	}
+
+	if !middleware.CheckStudentAccess(c, submission.StudentID) {
+		return
+	}
+
+	var file *models.SubmissionFile
+	for i := range submission.Files {
+		if submission.Files[i].ID == c.Param("fileId") {
+			file = &submission.Files[i]
+			break
+		}
+	}
+	if file == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "File not found."})
+		return
+	}
+
+	content, err := h.blobs.Open(ctx, file.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "File not found."})
+			return
+		}
+		log.Println("Error opening submission file:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch file. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,414 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/export"
+	"example.com/sre-bootcamp-rest-api/middleware"
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/reporting"
+	"github.com/gin-gonic/gin"
+)
+
+// getAcademicYears lists the academic years with their terms
+func (h *Handler) getAcademicYears(c *gin.Context) {
+	years, err := h.store.Terms.ListYears(c.Request.Context())
+	if err != nil {
+		log.Println("Error fetching academic years:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch academic years. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if years == nil {
+		years = []models.AcademicYear{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"academic_years": years,
+		"count":          len(years),
+	})
+}
+
+// getAcademicYearByID retrieves an academic year with its terms
+func (h *Handler) getAcademicYearByID(c *gin.Context) {
+	year, err := h.store.Terms.GetYearByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondTermError(c, err, "Academic year not found.", "Could not fetch academic year. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"academic_year": year})
+}
+
+// createAcademicYear creates an academic year
+func (h *Handler) createAcademicYear(c *gin.Context) {
+	var year models.AcademicYear
+	if err := c.ShouldBindJSON(&year); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := year.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid academic year.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Terms.CreateYear(c.Request.Context(), &year); err != nil {
+		respondTermError(c, err, "Academic year not found.", "Could not create academic year. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Academic year created successfully!",
+		"academic_year": year,
+	})
+}
+
+// updateAcademicYear renames or moves an academic year
+func (h *Handler) updateAcademicYear(c *gin.Context) {
+	var year models.AcademicYear
+	if err := c.ShouldBindJSON(&year); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := year.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid academic year.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	year.ID = c.Param("id")
+	if err := h.store.Terms.UpdateYear(c.Request.Context(), &year); err != nil {
+		respondTermError(c, err, "Academic year not found.", "Could not update academic year. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Academic year updated successfully!",
+		"academic_year": year,
+	})
+}
+
+// deleteAcademicYear deletes an academic year and its terms. Years with a
+// finalized term are kept.
+func (h *Handler) deleteAcademicYear(c *gin.Context) {
+	if err := h.store.Terms.DeleteYear(c.Request.Context(), c.Param("id")); err != nil {
+		respondTermError(c, err, "Academic year not found.", "Could not delete academic year. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Academic year deleted successfully!"})
+}
+
+// getCurrentTerm retrieves the term the date query parameter falls in,
+// today by default
+func (h *Handler) getCurrentTerm(c *gin.Context) {
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid date format. Use YYYY-MM-DD."})
+			return
+		}
+	}
+
+	term, err := h.store.Terms.GetTermByDate(c.Request.Context(), date)
+	if err != nil {
+		respondTermError(c, err, "No term covers this date.", "Could not fetch term. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"term": term})
+}
+
+// getTermByID retrieves a term
+func (h *Handler) getTermByID(c *gin.Context) {
+	term, err := h.store.Terms.GetTermByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondTermError(c, err, "Term not found.", "Could not fetch term. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"term": term})
+}
+
+// createTerm creates a term within an academic year
+func (h *Handler) createTerm(c *gin.Context) {
+	var term models.Term
+	if err := c.ShouldBindJSON(&term); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := term.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid term.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Terms.CreateTerm(c.Request.Context(), &term); err != nil {
+		respondTermError(c, err, "Academic year not found.", "Could not create term. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Term created successfully!",
+		"term":    term,
+	})
+}
+
+// updateTerm renames or moves a term that is not finalized
+func (h *Handler) updateTerm(c *gin.Context) {
+	existing, err := h.store.Terms.GetTermByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondTermError(c, err, "Term not found.", "Could not update term. Try again later.")
+		return
+	}
+
+	var term models.Term
+	if err := c.ShouldBindJSON(&term); err != nil {
+		log.Println("Error binding JSON:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Could not parse request data.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Terms stay in the academic year they were created in
+	term.ID = existing.ID
+	term.AcademicYearID = existing.AcademicYearID
+	if err := term.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid term.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.store.Terms.UpdateTerm(c.Request.Context(), &term); err != nil {
+		respondTermError(c, err, "Term not found.", "Could not update term. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Term updated successfully!",
+		"term":    term,
+	})
+}
+
+// deleteTerm deletes a term that is not finalized
+func (h *Handler) deleteTerm(c *gin.Context) {
+	if err := h.store.Terms.DeleteTerm(c.Request.Context(), c.Param("id")); err != nil {
+		respondTermError(c, err, "Term not found.", "Could not delete term. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Term deleted successfully!"})
+}
+
+// finalizeTerm finalizes a term: the grades of assignments due in it can no
+// longer change, and the report card of every student that is not archived
+// is stored as it stands. Finalizing cannot be undone.
+func (h *Handler) finalizeTerm(c *gin.Context) {
+	ctx := c.Request.Context()
+	user := middleware.GetUserFromContext(c)
+
+	term, err := h.store.Terms.Finalize(ctx, c.Param("id"), user.ID, func(term *models.Term) ([]models.ReportCard, error) {
+		return h.reportCards(ctx, term)
+	})
+	if err != nil {
+		respondTermError(c, err, "Term not found.", "Could not finalize term. Try again later.")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Term finalized successfully!",
+		"term":    term,
+	})
+}
+
+// reportCards builds the report card of every student that is not archived
+// for a term. It reads the student report of each student in turn, which is
+// acceptable once per term.
+func (h *Handler) reportCards(ctx context.Context, term *models.Term) ([]models.ReportCard, error) {
+	page, err := h.store.Students.List(ctx, models.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+
+	cards := make([]models.ReportCard, 0, len(page.Items))
+	for i := range page.Items {
+		student := &page.Items[i]
+		body, doc, err := h.studentReport(ctx, student, term)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report card of student %s: %w", student.ID, err)
+		}
+		document, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report card of student %s: %w", student.ID, err)
+		}
+		cards = append(cards, models.ReportCard{
+			StudentID:   student.ID,
+			StudentName: student.Name,
+			Data:        data,
+			Document:    document,
+		})
+	}
+	return cards, nil
+}
+
+// getReportCards lists the report cards of a student, most recent first
+func (h *Handler) getReportCards(c *gin.Context) {
+	cards, err := h.store.Terms.ListReportCards(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		log.Println("Error fetching report cards:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch report cards. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if cards == nil {
+		cards = []models.ReportCard{} // Return empty array instead of null
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report_cards": cards,
+		"count":        len(cards),
+	})
+}
+
+// getReportCard downloads a report card of a student as it was taken when
+// its term was finalized, in the same formats as the student report
+func (h *Handler) getReportCard(c *gin.Context) {
+	studentID := c.Param("id")
+
+	format, ok := reportFormat(c)
+	if !ok {
+		return
+	}
+
+	card, err := h.store.Terms.GetReportCardByID(c.Request.Context(), c.Param("cardId"))
+	if err == nil && card.StudentID != studentID {
+		err = fmt.Errorf("report card %w", models.ErrNotFound)
+	}
+	if err != nil {
+		respondTermError(c, err, "Report card not found.", "Could not fetch report card. Try again later.")
+		return
+	}
+
+	var body gin.H
+	var doc export.Document
+	if err := json.Unmarshal(card.Data, &body); err != nil {
+		log.Println("Error decoding report card:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch report card. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err := json.Unmarshal(card.Document, &doc); err != nil {
+		log.Println("Error decoding report card:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Could not fetch report card. Try again later.",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	renderReport(c, format, "report-card-"+studentID+"-"+card.TermID, body, doc)
+}
+
+// queryTerm loads the term named by the termId query parameter, or returns
+// nil without one. It responds with an error and returns false when the
+// term cannot be loaded.
+func (h *Handler) queryTerm(c *gin.Context) (*models.Term, bool) {
+	id := c.Query("termId")
+	if id == "" {
+		return nil, true
+	}
+
+	term, err := h.store.Terms.GetTermByID(c.Request.Context(), id)
+	if err != nil {
+		respondTermError(c, err, "Term not found.", "Could not fetch term. Try again later.")
+		return nil, false
+	}
+	return term, true
+}
+
+// termGradeFilter selects the grades of assignments due in a term, or every
+// grade without one
+func termGradeFilter(term *models.Term) reporting.GradeFilter {
+	if term == nil {
+		return reporting.GradeFilter{}
+	}
+	from, to := term.Period()
+	return reporting.GradeFilter{From: from, To: to}
+}
+
+// termConditions restricts attendance records to the days of a term
+func termConditions(term *models.Term) []models.Condition {
+	return []models.Condition{
+		{Field: "date", Op: models.OpGte, Value: term.StartDate},
+		{Field: "date", Op: models.OpLte, Value: term.EndDate},
+	}
+}
+
+// respondTermError responds to an error of the term repository. notFound is
+// the message for missing records and failure the one for other errors.
+func respondTermError(c *gin.Context, err error, notFound, failure string) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": notFound})
+	case errors.Is(err, models.ErrTermFinalized):
+		c.JSON(http.StatusConflict, gin.H{"message": "The term is finalized and can no longer change."})
+	case errors.Is(err, models.ErrTermOverlap):
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "The dates overlap another academic year or term.",
+			"error":   err.Error(),
+		})
+	case errors.Is(err, models.ErrTermOutsideYear):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Term dates must fall within the academic year.",
+			"error":   err.Error(),
+		})
+	default:
+		log.Println("Error in term request:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": failure,
+			"error":   err.Error(),
+		})
+	}
+}
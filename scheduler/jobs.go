@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/notify"
+)
+
+// MarkMissingJob is the name of the job marking missing assignments
+const MarkMissingJob = "mark-missing"
+
+// DefaultJobs returns the jobs the service runs on the given store,
+// telling parents about their results through notifier
+func DefaultJobs(store *models.Store, notifier *notify.Notifier) []Job {
+	return []Job{markMissing(store.Grades, notifier)}
+}
+
+// markMissing gives missing grades to students who handed nothing in for
+// assignments whose due date has passed, and notifies their parents. Only
+// assignments due within MARK_MISSING_LOOKBACK are considered, so that
+// turning the job on does not fill in years of old assignments.
+func markMissing(grades models.GradeRepository, notifier *notify.Notifier) Job {
+	lookback := envDuration("MARK_MISSING_LOOKBACK", 30*24*time.Hour)
+	return Job{
+		Name:     MarkMissingJob,
+		Interval: envDuration("MARK_MISSING_INTERVAL", time.Hour),
+		Run: func(ctx context.Context) (string, error) {
+			now := time.Now()
+			created, err := grades.MarkMissing(ctx, now.Add(-lookback), now)
+			if err != nil {
+				return "", err
+			}
+			for _, grade := range created {
+				notifier.GradeSaved(ctx, grade)
+			}
+			return fmt.Sprintf("%d grades marked missing", len(created)), nil
+		},
+	}
+}
+
+// envDuration reads a duration from the environment, falling back to the
+// default when it is unset or invalid
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Locker hands out named locks shared by every replica. TryLock returns a
+// function releasing the lock, or nil when another holder has it.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (unlock func(), err error)
+}
+
+// PostgresLocker takes Postgres session advisory locks. Each lock is held on
+// a connection of its own, so it is released by the server if the replica
+// holding it dies.
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+// NewPostgresLocker creates a locker on the given database
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+// TryLock takes the advisory lock keyed by the hash of name
+func (l *PostgresLocker) TryLock(ctx context.Context, name string) (func(), error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection for lock %s: %w", name, err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&locked); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to take lock %s: %w", name, err)
+	}
+	if !locked {
+		_ = conn.Close()
+		return nil, nil
+	}
+
+	return func() {
+		ctx := context.WithoutCancel(ctx)
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", name); err != nil {
+			// Close would return the connection to the pool with the lock
+			// still held; marking it bad makes the pool discard it, and the
+			// server releases the lock when the session ends
+			log.Printf("Error releasing lock %s, discarding its connection: %v", name, err)
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		_ = conn.Close()
+	}, nil
+}
+
+// LocalLocker hands out locks within the process, for a single replica and
+// for tests
+type LocalLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// NewLocalLocker creates a locker with no lock held
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{held: make(map[string]bool)}
+}
+
+// TryLock takes the named lock unless it is held
+func (l *LocalLocker) TryLock(ctx context.Context, name string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[name] {
+		return nil, nil
+	}
+	l.held[name] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, name)
+	}, nil
+}
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a connection whose lock could not be released is closed rather
+// than returned to the pool still holding the lock
+func TestPostgresLocker_UnlockFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(hashtext\(\$1\)\)`).
+		WithArgs("job:test").
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(hashtext\(\$1\)\)`).
+		WithArgs("job:test").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectClose()
+
+	unlock, err := NewPostgresLocker(db).TryLock(context.Background(), "job:test")
+	require.NoError(t, err)
+	require.NotNil(t, unlock)
+	unlock()
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
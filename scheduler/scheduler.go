@@ -0,0 +1,175 @@
+// Package scheduler runs background jobs inside the service. Every replica
+// runs a scheduler; a job only runs on the replica that takes its lock, and
+// only once its interval has passed since the last run recorded in the
+// store, so replicas neither run a job twice at once nor more often than
+// its interval.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+)
+
+var (
+	// ErrUnknownJob is returned when running a job that is not scheduled
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrJobRunning is returned when the job is already running on some replica
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// checkInterval is how often the scheduler looks for jobs that are due
+const checkInterval = time.Minute
+
+// Job is work run periodically. Run returns a summary of what it did.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) (string, error)
+}
+
+// JobStatus describes a job and its last run, if any
+type JobStatus struct {
+	Name      string         `json:"name"`
+	Interval  string         `json:"interval"`
+	LastRun   *models.JobRun `json:"last_run"`
+	NextRunAt time.Time      `json:"next_run_at"`
+}
+
+// Scheduler runs jobs at their interval
+type Scheduler struct {
+	jobs     []Job
+	locker   Locker
+	runs     models.JobRepository
+	instance string
+}
+
+// New creates a scheduler for the jobs, taking their locks from locker and
+// recording their runs in runs
+func New(locker Locker, runs models.JobRepository, jobs ...Job) *Scheduler {
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return &Scheduler{jobs: jobs, locker: locker, runs: runs, instance: instance}
+}
+
+// Start checks for due jobs right away and then every minute until ctx is
+// cancelled. The returned function cancels the checks and waits for a job
+// that is still running to return.
+func (s *Scheduler) Start(ctx context.Context) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			s.RunDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// RunDue runs every job whose interval has passed since its last run.
+// Failures are recorded with the run and logged.
+func (s *Scheduler) RunDue(ctx context.Context) {
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.run(ctx, job, false); err != nil && !errors.Is(err, ErrJobRunning) {
+			log.Printf("Error running job %s: %v", job.Name, err)
+		}
+	}
+}
+
+// Run runs a job now, whether it is due or not, and returns its run
+func (s *Scheduler) Run(ctx context.Context, name string) (*models.JobRun, error) {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return s.run(ctx, job, true)
+		}
+	}
+	return nil, ErrUnknownJob
+}
+
+// Status returns the jobs with their last run
+func (s *Scheduler) Status(ctx context.Context) ([]JobStatus, error) {
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		status := JobStatus{Name: job.Name, Interval: job.Interval.String(), NextRunAt: time.Now()}
+		last, err := s.runs.GetRun(ctx, job.Name)
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			status.LastRun = last
+			if next := last.StartedAt.Add(job.Interval); next.After(status.NextRunAt) {
+				status.NextRunAt = next
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// run runs a job under its lock. Unless forced, a job whose interval has
+// not passed since its last run is skipped and nil is returned. The last
+// run is read after taking the lock, so a replica that waited on another
+// one sees its run.
+func (s *Scheduler) run(ctx context.Context, job Job, force bool) (*models.JobRun, error) {
+	unlock, err := s.locker.TryLock(ctx, "job:"+job.Name)
+	if err != nil {
+		return nil, err
+	}
+	if unlock == nil {
+		return nil, ErrJobRunning
+	}
+	defer unlock()
+
+	if !force {
+		last, err := s.runs.GetRun(ctx, job.Name)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+		if last != nil && time.Since(last.StartedAt) < job.Interval {
+			return nil, nil
+		}
+	}
+
+	run := &models.JobRun{Name: job.Name, Instance: s.instance, StartedAt: time.Now()}
+	result, err := job.Run(ctx)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Status = models.JobFailed
+		run.Error = err.Error()
+		log.Printf("Job %s failed: %v", job.Name, err)
+	} else {
+		run.Status = models.JobSucceeded
+		run.Result = result
+		log.Printf("Job %s succeeded: %s", job.Name, result)
+	}
+
+	// Record the run even when ctx was cancelled during it
+	if err := s.runs.SaveRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, fmt.Errorf("failed to record the run of job %s: %w", job.Name, err)
+	}
+	return run, nil
+}
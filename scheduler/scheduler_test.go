@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/sre-bootcamp-rest-api/models"
+	"example.com/sre-bootcamp-rest-api/models/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that jobs only run once their interval has passed, that a forced run
+// ignores the interval and that failures are recorded
+func TestScheduler_RunDue(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	runs := 0
+	fail := false
+	s := New(NewLocalLocker(), store.Jobs, Job{
+		Name:     "count",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) (string, error) {
+			runs++
+			if fail {
+				return "", errors.New("boom")
+			}
+			return "counted", nil
+		},
+	})
+
+	s.RunDue(ctx)
+	s.RunDue(ctx)
+	assert.Equal(t, 1, runs, "the second check is within the interval")
+
+	fail = true
+	run, err := s.Run(ctx, "count")
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, models.JobFailed, run.Status)
+	assert.Equal(t, "boom", run.Error)
+
+	statuses, err := s.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, models.JobFailed, statuses[0].LastRun.Status)
+	assert.Equal(t, run.StartedAt.Add(time.Hour), statuses[0].NextRunAt)
+
+	_, err = s.Run(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrUnknownJob))
+}
+
+// Test that a job does not run while another holder has its lock
+func TestScheduler_Locked(t *testing.T) {
+	ctx := context.Background()
+	locker := NewLocalLocker()
+	ran := false
+	s := New(locker, memory.NewStore().Jobs, Job{
+		Name:     "locked",
+		Interval: time.Minute,
+		Run:      func(ctx context.Context) (string, error) { ran = true; return "", nil },
+	})
+
+	unlock, err := locker.TryLock(ctx, "job:locked")
+	require.NoError(t, err)
+	require.NotNil(t, unlock)
+
+	_, err = s.Run(ctx, "locked")
+	assert.True(t, errors.Is(err, ErrJobRunning))
+	s.RunDue(ctx)
+	assert.False(t, ran)
+
+	unlock()
+	_, err = s.Run(ctx, "locked")
+	require.NoError(t, err)
+	assert.True(t, ran)
+}
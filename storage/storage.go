@@ -0,0 +1,111 @@
+// Package storage keeps the content of uploaded files outside the database.
+// Records store the key a blob was written under; Blobs implementations
+// decide where the bytes live. Local writes them to a directory, which suits
+// a single instance or a shared volume.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned when no blob is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// Blobs stores file contents under keys chosen by the caller. Keys are
+// slash-separated relative paths such as "submissions/<id>".
+type Blobs interface {
+	// Put stores the content under the key, replacing any blob already
+	// there, and returns the number of bytes written
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	// Open returns the content stored under the key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under the key; deleting a missing
+	// blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Local stores blobs as files below a directory
+type Local struct {
+	dir string
+}
+
+// NewLocal returns a Local storing blobs below dir, creating it if needed
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// path returns the file a key is stored in. Keys may not leave the
+// storage directory.
+func (l *Local) path(key string) (string, error) {
+	name := filepath.FromSlash(key)
+	if key == "" || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(l.dir, name), nil
+}
+
+// Put writes the content to a temporary file and renames it into place, so
+// readers never see a partial blob
+func (l *Local) Put(ctx context.Context, key string, content io.Reader) (int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	size, err := io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return size, nil
+}
+
+// Open opens the file of a blob
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob %s: %w", key, err)
+	}
+	return file, nil
+}
+
+// Delete removes the file of a blob
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
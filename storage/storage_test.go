@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that blobs can be stored, replaced, read back and deleted
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = blobs.Put(ctx, "submissions/a", strings.NewReader("draft"))
+	require.NoError(t, err)
+	size, err := blobs.Put(ctx, "submissions/a", strings.NewReader("final"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+
+	file, err := blobs.Open(ctx, "submissions/a")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.Equal(t, "final", string(content))
+
+	require.NoError(t, blobs.Delete(ctx, "submissions/a"))
+	require.NoError(t, blobs.Delete(ctx, "submissions/a"), "deleting twice is fine")
+	_, err = blobs.Open(ctx, "submissions/a")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+// Test that keys cannot point outside the storage directory
+func TestLocal_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../b"} {
+		_, err := blobs.Put(ctx, key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+		_, err = blobs.Open(ctx, key)
+		assert.Error(t, err, key)
+	}
+}
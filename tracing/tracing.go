@@ -0,0 +1,160 @@
+// Package tracing configures OpenTelemetry tracing for the service. Incoming
+// requests are traced by the otelgin middleware, SQL statements by the
+// otelsql driver wrapper in db.InitDB.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+var logger *logrus.Entry
+
+// serviceName is the service name of the installed tracer provider
+var serviceName = "student-api"
+
+// SetLogger sets the logger instance for the tracing package
+func SetLogger(l *logrus.Logger) {
+	logger = l.WithField("component", "tracing")
+}
+
+// Config holds the tracing configuration
+type Config struct {
+	// ServiceName is reported as service.name on every span
+	ServiceName string
+	// Exporter selects where spans are sent: otlp, console, file or none.
+	// With none, spans are still created so that trace IDs appear in logs.
+	Exporter string
+	// FilePath is the file spans are appended to when Exporter is file
+	FilePath string
+}
+
+// GetConfig loads the tracing configuration from environment variables.
+//
+// OTEL_TRACES_EXPORTER selects the exporter. The OTLP exporter sends spans
+// over HTTP and is configured with the standard OTEL_EXPORTER_OTLP_*
+// variables, for example OTEL_EXPORTER_OTLP_ENDPOINT. Sampling follows
+// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG.
+func GetConfig() (Config, error) {
+	config := Config{
+		ServiceName: getEnv("OTEL_SERVICE_NAME", "student-api"),
+		Exporter:    strings.ToLower(getEnv("OTEL_TRACES_EXPORTER", "none")),
+		FilePath:    getEnv("OTEL_TRACES_FILE", "traces.json"),
+	}
+
+	switch config.Exporter {
+	case "otlp", "console", "file", "none":
+	default:
+		return config, fmt.Errorf("invalid OTEL_TRACES_EXPORTER: %s", config.Exporter)
+	}
+
+	return config, nil
+}
+
+// getEnv gets an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// InitTracing installs the global tracer provider and W3C trace context
+// propagator. The returned function flushes pending spans and must be called
+// on shutdown.
+func InitTracing(ctx context.Context) (func(context.Context) error, error) {
+	// Initialize logger if not set
+	if logger == nil {
+		logger = logrus.StandardLogger().WithField("component", "tracing")
+	}
+
+	config, err := GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	var closer io.Closer
+	switch config.Exporter {
+	case "otlp":
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case "console":
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create console exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithSyncer(exporter))
+	case "file":
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+		closer = file
+	}
+
+	serviceName = config.ServiceName
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	logger.WithFields(logrus.Fields{
+		"service_name": config.ServiceName,
+		"exporter":     config.Exporter,
+	}).Info("Initialized tracing")
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
+
+// Middleware returns a middleware that starts a server span for each request,
+// continuing the trace from an incoming W3C traceparent header. Spans are
+// named after the route template. Health checks and metric scrapes are not
+// traced.
+func Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+	}))
+}